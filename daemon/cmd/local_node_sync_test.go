@@ -76,6 +76,18 @@ func (fln *fakeLocalNode) Store(context.Context) (resource.Store[*slim_corev1.No
 	return nil, errors.New("unimplemented")
 }
 
+func (fln *fakeLocalNode) Get(ctx context.Context, key resource.Key) (item *slim_corev1.Node, exists bool, err error) {
+	return nil, false, errors.New("unimplemented")
+}
+
+func (fln *fakeLocalNode) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (fln *fakeLocalNode) HasSynced() bool {
+	return true
+}
+
 func TestLocalNodeSync(t *testing.T) {
 	var (
 		local = node.LocalNode{Node: types.Node{
@@ -230,3 +242,15 @@ func (mr *mockResource[T]) Events(ctx context.Context, opts ...resource.EventsOp
 func (mr *mockResource[T]) Store(context.Context) (resource.Store[T], error) {
 	panic("store not impl")
 }
+
+func (mr *mockResource[T]) Get(ctx context.Context, key resource.Key) (item T, exists bool, err error) {
+	panic("get not impl")
+}
+
+func (mr *mockResource[T]) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (mr *mockResource[T]) HasSynced() bool {
+	return true
+}
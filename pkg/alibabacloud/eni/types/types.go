@@ -4,6 +4,7 @@
 package types
 
 import (
+	ipamOption "github.com/cilium/cilium/pkg/ipam/option"
 	"github.com/cilium/cilium/pkg/ipam/types"
 )
 
@@ -135,6 +136,11 @@ func (e *ENI) InterfaceID() string {
 	return e.NetworkInterfaceID
 }
 
+// InterfaceType returns the type of the interface
+func (e *ENI) InterfaceType() string {
+	return ipamOption.IPAMAlibabaCloud
+}
+
 // ForeachAddress iterates over all addresses and calls fn
 func (e *ENI) ForeachAddress(id string, fn types.AddressIterator) error {
 	for _, address := range e.PrivateIPSets {
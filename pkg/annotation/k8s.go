@@ -140,6 +140,13 @@ const (
 	// Its just a prefix, because the ASN of the Router is part of the annotation itself
 	BGPVRouterAnnoPrefix = "cilium.io/bgp-virtual-router."
 
+	// BGPVRoutersAnnoKey is an alternative to BGPVRouterAnnoPrefix that carries
+	// the configuration of every local BGP virtual router on the node in a
+	// single annotation, as a JSON object mapping each ASN (as a string) to
+	// its router-id, e.g. {"64512":"172.0.0.3"}. This avoids one annotation
+	// per ASN when a node has many local ASNs configured.
+	BGPVRoutersAnnoKey = "cilium.io/bgp-virtual-routers"
+
 	// IPAMPoolKey is the annotation name used to store the IPAM pool name from
 	// which workloads should allocate their IP from
 	IPAMPoolKey = IPAMPrefix + "/ip-pool"
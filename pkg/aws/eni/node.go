@@ -653,8 +653,9 @@ func (n *Node) GetMaximumAllocatableIPv4() int {
 		return 0
 	}
 
-	// limits.IPv4 contains the primary IP which is not available for allocation
-	maxPerInterface := math.IntMax(limits.IPv4-1, 0)
+	// The primary IP on each ENI is not available for allocation.
+	limits.ReservedIPv4 = 1
+	maxPerInterface := limits.UsableIPv4PerAdapter()
 
 	if n.IsPrefixDelegated() {
 		maxPerInterface = maxPerInterface * option.ENIPDBlockSizeIPv4
@@ -718,8 +719,9 @@ func (n *Node) GetMinimumAllocatableIPv4() int {
 		return 0
 	}
 
-	// limits.IPv4 contains the primary IP which is not available for allocation
-	maxPerInterface := math.IntMax(limits.IPv4-1, 0)
+	// The primary IP on each ENI is not available for allocation.
+	limits.ReservedIPv4 = 1
+	maxPerInterface := limits.UsableIPv4PerAdapter()
 
 	return math.IntMin(minimum, (limits.Adapters-index)*maxPerInterface)
 }
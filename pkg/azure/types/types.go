@@ -6,6 +6,7 @@ package types
 import (
 	"strings"
 
+	ipamOption "github.com/cilium/cilium/pkg/ipam/option"
 	"github.com/cilium/cilium/pkg/ipam/types"
 )
 
@@ -142,6 +143,11 @@ func (a *AzureInterface) InterfaceID() string {
 	return a.ID
 }
 
+// InterfaceType returns the type of the interface
+func (a *AzureInterface) InterfaceType() string {
+	return ipamOption.IPAMAzure
+}
+
 func (a *AzureInterface) extractIDs() {
 	switch {
 	// Interface from a VMSS instance:
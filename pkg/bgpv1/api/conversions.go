@@ -272,6 +272,25 @@ func ToAgentRoutes(ms []*models.BgpRoute) ([]*types.Route, error) {
 	return ret, nil
 }
 
+// SummarizePaths counts the given paths per address family, inferring the
+// family from the path's NLRI when Path.Family is not explicitly set. This
+// provides a quick RIB size overview per family for `cilium bgp routes`
+// summaries.
+func SummarizePaths(paths []types.Path) map[types.Family]int {
+	counts := make(map[types.Family]int)
+	for _, p := range paths {
+		family := p.Family
+		if family == (types.Family{}) {
+			family = types.Family{
+				Afi:  types.Afi(p.NLRI.AFI()),
+				Safi: types.Safi(p.NLRI.SAFI()),
+			}
+		}
+		counts[family]++
+	}
+	return counts
+}
+
 func ToAPIRoutePolicies(policies []*types.RoutePolicy, routerASN int64) []*models.BgpRoutePolicy {
 	ret := make([]*models.BgpRoutePolicy, 0, len(policies))
 
@@ -50,3 +50,20 @@ func TestRoutePolicyConversions(t *testing.T) {
 		})
 	}
 }
+
+// Test that SummarizePaths counts a mix of IPv4 and IPv6 unicast paths per family
+func TestSummarizePaths(t *testing.T) {
+	var paths []types.Path
+	for _, tt := range types.CommonPaths {
+		paths = append(paths, tt.Path)
+	}
+	// Duplicate the IPv4 unicast path so families have different counts.
+	paths = append(paths, types.CommonPaths[0].Path)
+
+	summary := SummarizePaths(paths)
+
+	require.Equal(t, map[types.Family]int{
+		{Afi: types.AfiIPv4, Safi: types.SafiUnicast}: 2,
+		{Afi: types.AfiIPv6, Safi: types.SafiUnicast}: 1,
+	}, summary)
+}
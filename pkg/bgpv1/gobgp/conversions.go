@@ -7,16 +7,24 @@ import (
 	"errors"
 	"fmt"
 	"net/netip"
+	"strconv"
+	"strings"
 
 	gobgp "github.com/osrg/gobgp/v3/api"
 	"github.com/osrg/gobgp/v3/pkg/apiutil"
 	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/cilium/cilium/api/v1/models"
+	bgpapi "github.com/cilium/cilium/pkg/bgpv1/api"
 	"github.com/cilium/cilium/pkg/bgpv1/types"
+	"github.com/cilium/cilium/pkg/logging"
+	"github.com/cilium/cilium/pkg/logging/logfields"
 	"github.com/cilium/cilium/pkg/time"
 )
 
+var log = logging.DefaultLogger.WithField(logfields.LogSubsys, "bgp-control-plane-gobgp")
+
 // ToGoBGPPath converts the Agent Path type to the GoBGP Path type
 func ToGoBGPPath(p *types.Path) (*gobgp.Path, error) {
 	nlri, err := apiutil.MarshalNLRI(p.NLRI)
@@ -53,11 +61,16 @@ func ToGoBGPPath(p *types.Path) (*gobgp.Path, error) {
 	}, nil
 }
 
-// ToAgentPath converts the GoBGP Path type to the Agent Path type
-func ToAgentPath(p *gobgp.Path) (*types.Path, error) {
+// toAgentNLRI decodes a GoBGP path's NLRI into its native representation,
+// inferring the address family from the path's Family field.
+func toAgentNLRI(p *gobgp.Path) (bgp.AddrPrefixInterface, error) {
 	family := bgp.AfiSafiToRouteFamily(uint16(p.Family.Afi), uint8(p.Family.Safi))
+	return apiutil.UnmarshalNLRI(family, p.Nlri)
+}
 
-	nlri, err := apiutil.UnmarshalNLRI(family, p.Nlri)
+// ToAgentPath converts the GoBGP Path type to the Agent Path type
+func ToAgentPath(p *gobgp.Path) (*types.Path, error) {
+	nlri, err := toAgentNLRI(p)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert Nlri: %w", err)
 	}
@@ -102,6 +115,72 @@ func ToAgentPaths(paths []*gobgp.Path) ([]*types.Path, error) {
 	return ps, nil
 }
 
+// toModelsBgpRoutes converts a flat list of GoBGP paths into the
+// []*models.BgpRoute served by the agent API, grouping paths by their
+// destination prefix. It reuses toAgentNLRI to decode each path's NLRI,
+// so a path whose NLRI type is not understood is skipped with a logged
+// warning rather than failing the conversion for every other path.
+func toModelsBgpRoutes(paths []*gobgp.Path, tableType types.TableType) ([]*models.BgpRoute, error) {
+	routes := map[string]*models.BgpRoute{}
+	prefixes := []string{}
+
+	for _, p := range paths {
+		nlri, err := toAgentNLRI(p)
+		if err != nil {
+			log.WithError(err).Warning("skipping path with NLRI type that could not be decoded")
+			continue
+		}
+
+		prefix := nlri.String()
+		route, ok := routes[prefix]
+		if !ok {
+			route = &models.BgpRoute{Prefix: prefix}
+			if tableType == types.TableTypeAdjRIBIn || tableType == types.TableTypeAdjRIBOut {
+				route.Neighbor = p.NeighborIp
+			}
+			routes[prefix] = route
+			prefixes = append(prefixes, prefix)
+		}
+
+		path, err := toModelsBgpPath(p, nlri)
+		if err != nil {
+			log.WithError(err).Warning("skipping path that could not be converted")
+			continue
+		}
+		route.Paths = append(route.Paths, path)
+	}
+
+	ret := make([]*models.BgpRoute, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		ret = append(ret, routes[prefix])
+	}
+	return ret, nil
+}
+
+// toModelsBgpPath converts a single GoBGP path, whose NLRI has already been
+// decoded into nlri, into a models.BgpPath, filling in the best/stale/age and
+// family fields reported by the API alongside the serialized NLRI and path
+// attributes.
+func toModelsBgpPath(p *gobgp.Path, nlri bgp.AddrPrefixInterface) (*models.BgpPath, error) {
+	pattrs, err := apiutil.UnmarshalPathAttributes(p.Pattrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Pattrs: %w", err)
+	}
+
+	path, err := bgpapi.ToAPIPath(&types.Path{
+		NLRI:           nlri,
+		PathAttributes: pattrs,
+		AgeNanoseconds: int64(time.Since(p.Age.AsTime())),
+		Best:           p.Best,
+	})
+	if err != nil {
+		return nil, err
+	}
+	path.Stale = p.Stale
+
+	return path, nil
+}
+
 func toGoBGPFamily(family types.Family) *gobgp.Family {
 	return &gobgp.Family{
 		Afi:  toGoBGPAfi(family.Afi),
@@ -459,3 +538,36 @@ func toGoBGPTableType(t types.TableType) (gobgp.TableType, error) {
 		return gobgp.TableType_LOCAL, fmt.Errorf("unknown table type %d", t)
 	}
 }
+
+// CommunityToString renders a numeric BGP community for diagnostics,
+// preferring the well-known name (e.g. "no-export") when the value matches
+// one, and falling back to the standard ASN:value form otherwise.
+func CommunityToString(c uint32) string {
+	if name, ok := bgp.WellKnownCommunityNameMap[bgp.WellKnownCommunity(c)]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d:%d", c>>16, c&0xffff)
+}
+
+// ParseCommunity parses a community string in either well-known name form
+// (e.g. "no-export") or ASN:value form (e.g. "65001:100") into its numeric
+// representation. It is the inverse of CommunityToString.
+func ParseCommunity(s string) (uint32, error) {
+	if v, ok := bgp.WellKnownCommunityValueMap[s]; ok {
+		return uint32(v), nil
+	}
+
+	elems := strings.Split(s, ":")
+	if len(elems) != 2 {
+		return 0, fmt.Errorf("invalid community %q: must be of the form asn:value or a well-known name", s)
+	}
+	asn, err := strconv.ParseUint(elems[0], 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid community %q: %w", s, err)
+	}
+	value, err := strconv.ParseUint(elems[1], 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid community %q: %w", s, err)
+	}
+	return uint32(asn<<16 | value), nil
+}
@@ -5,9 +5,12 @@ package gobgp
 
 import (
 	"context"
+	"net"
+	"net/netip"
 	"testing"
 
 	gobgp "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/packet/bgp"
 	"github.com/osrg/gobgp/v3/pkg/server"
 	"github.com/stretchr/testify/require"
 
@@ -65,3 +68,643 @@ func TestPathConversions(t *testing.T) {
 		})
 	}
 }
+
+// TestToModelsBgpRoutes verifies that toModelsBgpRoutes groups paths by
+// their destination prefix and fills in best/stale/age/family on the
+// resulting models.BgpPath, for both IPv4 and IPv6 unicast routes.
+func TestToModelsBgpRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefix     bgp.AddrPrefixInterface
+		nextHop    string
+		wantPrefix string
+	}{
+		{
+			name:       "IPv4 unicast",
+			prefix:     bgp.NewIPAddrPrefix(24, "10.0.0.0"),
+			nextHop:    "0.0.0.0",
+			wantPrefix: "10.0.0.0/24",
+		},
+		{
+			name:       "IPv6 unicast",
+			prefix:     bgp.NewIPv6AddrPrefix(64, "fd00::"),
+			nextHop:    "::",
+			wantPrefix: "fd00::/64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewBgpServer()
+			go s.Serve()
+			require.NoError(t, s.StartBgp(context.TODO(), &gobgp.StartBgpRequest{
+				Global: &gobgp.Global{Asn: 65000, RouterId: "127.0.0.1", ListenPort: -1},
+			}))
+			t.Cleanup(s.Stop)
+
+			agentPath := &types.Path{
+				NLRI: tt.prefix,
+				PathAttributes: []bgp.PathAttributeInterface{
+					bgp.NewPathAttributeOrigin(0),
+					bgp.NewPathAttributeNextHop(tt.nextHop),
+				},
+			}
+
+			path, err := ToGoBGPPath(agentPath)
+			require.NoError(t, err)
+
+			res, err := s.AddPath(context.TODO(), &gobgp.AddPathRequest{Path: path})
+			require.NoError(t, err)
+			require.NotZero(t, res.Uuid)
+
+			var paths []*gobgp.Path
+			err = s.ListPath(context.TODO(), &gobgp.ListPathRequest{Family: path.Family}, func(destination *gobgp.Destination) {
+				paths = append(paths, destination.Paths...)
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, paths)
+
+			routes, err := toModelsBgpRoutes(paths, types.TableTypeLocRIB)
+			require.NoError(t, err)
+			require.Len(t, routes, 1)
+
+			route := routes[0]
+			require.Equal(t, tt.wantPrefix, route.Prefix)
+			require.Empty(t, route.Neighbor, "loc-rib routes should not carry a neighbor")
+			require.Len(t, route.Paths, 1)
+
+			gotPath := route.Paths[0]
+			require.True(t, gotPath.Best)
+			require.False(t, gotPath.Stale)
+			require.NotNil(t, gotPath.Family)
+		})
+	}
+}
+
+// findMultiExitDisc returns the MED attribute and whether it is present in attrs.
+func findMultiExitDisc(attrs []bgp.PathAttributeInterface) (*bgp.PathAttributeMultiExitDisc, bool) {
+	for _, attr := range attrs {
+		if med, ok := attr.(*bgp.PathAttributeMultiExitDisc); ok {
+			return med, true
+		}
+	}
+	return nil, false
+}
+
+// TestPathConversionsAbsentMED verifies that ToGoBGPPath/ToAgentPath round-trip
+// the presence or absence of the MED attribute faithfully. An absent MED must
+// not be synthesized as a MED of 0, since the two carry different meaning for
+// policy decisions and for re-advertising the path.
+func TestPathConversionsAbsentMED(t *testing.T) {
+	prefix := bgp.NewIPAddrPrefix(24, "10.0.0.0")
+	origin := bgp.NewPathAttributeOrigin(0)
+	nextHop := bgp.NewPathAttributeNextHop("0.0.0.0")
+
+	tests := []struct {
+		name       string
+		attributes []bgp.PathAttributeInterface
+		wantMED    bool
+	}{
+		{
+			name: "no MED attribute",
+			attributes: []bgp.PathAttributeInterface{
+				origin,
+				nextHop,
+			},
+			wantMED: false,
+		},
+		{
+			name: "explicit MED of 0",
+			attributes: []bgp.PathAttributeInterface{
+				origin,
+				nextHop,
+				bgp.NewPathAttributeMultiExitDisc(0),
+			},
+			wantMED: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewBgpServer()
+			go s.Serve()
+
+			err := s.StartBgp(context.TODO(), &gobgp.StartBgpRequest{
+				Global: &gobgp.Global{
+					Asn:        65000,
+					RouterId:   "127.0.0.1",
+					ListenPort: -1,
+				},
+			})
+			require.NoError(t, err)
+
+			t.Cleanup(func() {
+				s.Stop()
+			})
+
+			agentPath := &types.Path{
+				NLRI:           prefix,
+				PathAttributes: tt.attributes,
+			}
+
+			path, err := ToGoBGPPath(agentPath)
+			require.NoError(t, err)
+
+			_, wantMED := findMultiExitDisc(agentPath.PathAttributes)
+			require.Equal(t, tt.wantMED, wantMED)
+
+			res, err := s.AddPath(context.TODO(), &gobgp.AddPathRequest{
+				Path: path,
+			})
+			require.NoError(t, err)
+			require.NotZero(t, res.Uuid)
+
+			req := &gobgp.ListPathRequest{
+				Family: path.Family,
+			}
+			err = s.ListPath(context.TODO(), req, func(destination *gobgp.Destination) {
+				paths, err := ToAgentPaths(destination.Paths)
+				require.NoError(t, err)
+				require.NotZero(t, paths)
+
+				med, gotMED := findMultiExitDisc(paths[0].PathAttributes)
+				require.Equal(t, tt.wantMED, gotMED)
+				if gotMED {
+					require.EqualValues(t, 0, med.Value)
+				}
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+// findPrefixSID returns the Prefix-SID attribute and whether it is present in attrs.
+func findPrefixSID(attrs []bgp.PathAttributeInterface) (*bgp.PathAttributePrefixSID, bool) {
+	for _, attr := range attrs {
+		if psid, ok := attr.(*bgp.PathAttributePrefixSID); ok {
+			return psid, true
+		}
+	}
+	return nil, false
+}
+
+// TestPathConversionsPrefixSIDSRv6L3Service verifies that ToGoBGPPath/ToAgentPath
+// round-trip a Prefix-SID attribute carrying an SRv6 L3 Service TLV.
+func TestPathConversionsPrefixSIDSRv6L3Service(t *testing.T) {
+	prefix := bgp.NewIPAddrPrefix(24, "10.0.0.0")
+	sid := netip.MustParseAddr("fd00::1")
+	l3ServiceTLV := bgp.NewSRv6ServiceTLV(bgp.TLVTypeSRv6L3Service,
+		bgp.NewSRv6InformationSubTLV(sid, bgp.SRBehavior(5)))
+	wantTLVBytes, err := l3ServiceTLV.Serialize()
+	require.NoError(t, err)
+
+	agentPath := &types.Path{
+		NLRI: prefix,
+		PathAttributes: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			bgp.NewPathAttributePrefixSID(l3ServiceTLV),
+			// gobgp always serializes NEXT_HOP last regardless of input
+			// order, so it must come last here too.
+			bgp.NewPathAttributeNextHop("0.0.0.0"),
+		},
+	}
+
+	s := server.NewBgpServer()
+	go s.Serve()
+	require.NoError(t, s.StartBgp(context.TODO(), &gobgp.StartBgpRequest{
+		Global: &gobgp.Global{Asn: 65000, RouterId: "127.0.0.1", ListenPort: -1},
+	}))
+	t.Cleanup(s.Stop)
+
+	path, err := ToGoBGPPath(agentPath)
+	require.NoError(t, err)
+
+	res, err := s.AddPath(context.TODO(), &gobgp.AddPathRequest{Path: path})
+	require.NoError(t, err)
+	require.NotZero(t, res.Uuid)
+
+	err = s.ListPath(context.TODO(), &gobgp.ListPathRequest{Family: path.Family}, func(destination *gobgp.Destination) {
+		paths, err := ToAgentPaths(destination.Paths)
+		require.NoError(t, err)
+		require.NotZero(t, paths)
+
+		psid, ok := findPrefixSID(paths[0].PathAttributes)
+		require.True(t, ok, "expected a Prefix-SID attribute to round-trip")
+		require.Len(t, psid.TLVs, 1)
+
+		// The round-tripped TLV may come back as a different (but
+		// wire-compatible) concrete Go type than what was sent, since
+		// apiutil re-decodes it from its own protobuf representation -
+		// compare the encoded bytes rather than the concrete type.
+		gotTLVBytes, err := psid.TLVs[0].Serialize()
+		require.NoError(t, err)
+		require.Equal(t, wantTLVBytes, gotTLVBytes)
+	})
+	require.NoError(t, err)
+}
+
+// TestPathConversionsPrefixSIDLabelIndexUnsupported documents a limitation
+// inherited from our vendored gobgp rather than one introduced by
+// ToGoBGPPath/ToAgentPath: those functions don't switch on individual
+// Prefix-SID sub-TLVs themselves, they delegate entirely to
+// apiutil.MarshalPathAttributes/UnmarshalPathAttributes. That code's
+// PathAttributePrefixSID.DecodeFromBytes only recognizes the SRv6 L3/L2
+// Service TLVs (exercised by TestPathConversionsPrefixSIDSRv6L3Service)
+// and silently drops the Label-Index TLV, which has no
+// corresponding Go type in this gobgp version at all. Until gobgp is
+// upgraded to a version that represents and decodes it, a Label-Index TLV
+// cannot round-trip through this conversion layer.
+func TestPathConversionsPrefixSIDLabelIndexUnsupported(t *testing.T) {
+	// Hand-crafted wire bytes for a Prefix-SID attribute (type 40,
+	// transitive+optional) carrying a single Label-Index TLV (TLV type 1):
+	// a 3-byte TLV header followed by a 7-byte value (Reserved(1) +
+	// Flags(2) + Label Index(4)).
+	labelIndexTLV := []byte{
+		1, 0, 7,
+		0, 0, 0, 0, 1, 0x86, 0xa0, // label index 100000
+	}
+	raw := append([]byte{
+		byte(bgp.BGP_ATTR_FLAG_TRANSITIVE | bgp.BGP_ATTR_FLAG_OPTIONAL),
+		byte(bgp.BGP_ATTR_TYPE_PREFIX_SID),
+		byte(len(labelIndexTLV)),
+	}, labelIndexTLV...)
+
+	attr := &bgp.PathAttributePrefixSID{}
+	require.NoError(t, attr.DecodeFromBytes(raw))
+	require.Empty(t, attr.TLVs, "Label-Index TLV is expected to be dropped by the vendored gobgp decoder")
+}
+
+// findAggregator returns the Aggregator attribute and whether it is present
+// in attrs.
+func findAggregator(attrs []bgp.PathAttributeInterface) (*bgp.PathAttributeAggregator, bool) {
+	for _, attr := range attrs {
+		if agg, ok := attr.(*bgp.PathAttributeAggregator); ok {
+			return agg, true
+		}
+	}
+	return nil, false
+}
+
+// TestPathConversionsAggregator4ByteASN verifies that ToGoBGPPath/ToAgentPath
+// round-trip an Aggregator attribute whose ASN exceeds the 2-byte range,
+// i.e. one that must be carried as AS4_AGGREGATOR on the wire rather than
+// the legacy 2-byte AGGREGATOR, without truncating the ASN.
+func TestPathConversionsAggregator4ByteASN(t *testing.T) {
+	prefix := bgp.NewIPAddrPrefix(24, "10.0.0.0")
+	const largeASN = uint32(4200000000)
+
+	agentPath := &types.Path{
+		NLRI: prefix,
+		PathAttributes: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			bgp.NewPathAttributeAggregator(largeASN, "10.0.0.1"),
+			// gobgp always serializes NEXT_HOP last regardless of input
+			// order, so it must come last here too.
+			bgp.NewPathAttributeNextHop("0.0.0.0"),
+		},
+	}
+
+	path, err := ToGoBGPPath(agentPath)
+	require.NoError(t, err)
+
+	s := server.NewBgpServer()
+	go s.Serve()
+
+	err = s.StartBgp(context.TODO(), &gobgp.StartBgpRequest{
+		Global: &gobgp.Global{
+			Asn:        65000,
+			RouterId:   "127.0.0.1",
+			ListenPort: -1,
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		s.Stop()
+	})
+
+	res, err := s.AddPath(context.TODO(), &gobgp.AddPathRequest{
+		Path: path,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, res.Uuid)
+
+	req := &gobgp.ListPathRequest{
+		Family: path.Family,
+	}
+	err = s.ListPath(context.TODO(), req, func(destination *gobgp.Destination) {
+		paths, err := ToAgentPaths(destination.Paths)
+		require.NoError(t, err)
+		require.NotZero(t, paths)
+
+		agg, ok := findAggregator(paths[0].PathAttributes)
+		require.True(t, ok, "expected an Aggregator attribute to round-trip")
+		require.EqualValues(t, largeASN, agg.Value.AS)
+	})
+	require.NoError(t, err)
+}
+
+func findOriginatorId(attrs []bgp.PathAttributeInterface) (*bgp.PathAttributeOriginatorId, bool) {
+	for _, attr := range attrs {
+		if originator, ok := attr.(*bgp.PathAttributeOriginatorId); ok {
+			return originator, true
+		}
+	}
+	return nil, false
+}
+
+func findClusterList(attrs []bgp.PathAttributeInterface) (*bgp.PathAttributeClusterList, bool) {
+	for _, attr := range attrs {
+		if clusterList, ok := attr.(*bgp.PathAttributeClusterList); ok {
+			return clusterList, true
+		}
+	}
+	return nil, false
+}
+
+// TestPathConversionsRouteReflection verifies that ToGoBGPPath/ToAgentPath
+// round-trip the Originator-ID and Cluster-List attributes that a route
+// reflector adds to a reflected iBGP path, preserving the order of the
+// cluster IDs in the list. As with the other attribute-specific tests in
+// this file, neither function switches on these attribute types directly:
+// the round-trip goes through apiutil.MarshalPathAttributes/
+// UnmarshalPathAttributes.
+func TestPathConversionsRouteReflection(t *testing.T) {
+	prefix := bgp.NewIPAddrPrefix(24, "10.0.0.0")
+	clusterIDs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+
+	agentPath := &types.Path{
+		NLRI: prefix,
+		PathAttributes: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			bgp.NewPathAttributeOriginatorId("192.0.2.1"),
+			bgp.NewPathAttributeClusterList(clusterIDs),
+			// gobgp always serializes NEXT_HOP last regardless of input
+			// order, so it must come last here too.
+			bgp.NewPathAttributeNextHop("0.0.0.0"),
+		},
+	}
+
+	path, err := ToGoBGPPath(agentPath)
+	require.NoError(t, err)
+
+	s := server.NewBgpServer()
+	go s.Serve()
+
+	err = s.StartBgp(context.TODO(), &gobgp.StartBgpRequest{
+		Global: &gobgp.Global{
+			Asn:        65000,
+			RouterId:   "127.0.0.1",
+			ListenPort: -1,
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		s.Stop()
+	})
+
+	res, err := s.AddPath(context.TODO(), &gobgp.AddPathRequest{
+		Path: path,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, res.Uuid)
+
+	req := &gobgp.ListPathRequest{
+		Family: path.Family,
+	}
+	err = s.ListPath(context.TODO(), req, func(destination *gobgp.Destination) {
+		paths, err := ToAgentPaths(destination.Paths)
+		require.NoError(t, err)
+		require.NotZero(t, paths)
+
+		originator, ok := findOriginatorId(paths[0].PathAttributes)
+		require.True(t, ok, "expected an Originator-ID attribute to round-trip")
+		require.Equal(t, "192.0.2.1", originator.Value.String())
+
+		clusterList, ok := findClusterList(paths[0].PathAttributes)
+		require.True(t, ok, "expected a Cluster-List attribute to round-trip")
+		require.Len(t, clusterList.Value, len(clusterIDs))
+		for i, id := range clusterIDs {
+			require.Equal(t, id, clusterList.Value[i].String(), "cluster list ordering must be preserved")
+		}
+	})
+	require.NoError(t, err)
+}
+
+// TestPathConversionsMpReachNLRIIPv6Unicast verifies that ToGoBGPPath/
+// ToAgentPath round-trip an IPv6 unicast MP_REACH_NLRI attribute carrying
+// both a global and a link-local next hop, and more than one NLRI. As with
+// the other attribute-specific tests in this file, neither function
+// switches on MP_REACH_NLRI directly: the round-trip goes through
+// apiutil.MarshalPathAttributes/UnmarshalPathAttributes, which delegates to
+// bgp.PathAttributeMpReachNLRI's own (de)serialization.
+//
+// This round-trips directly through ToGoBGPPath/ToAgentPath rather than a
+// live BgpServer's AddPath/ListPath: the server's RIB models a path as a
+// single prefix with a single next hop, so it collapses a multi-NLRI,
+// dual-next-hop MP_REACH_NLRI attribute to the first of each on its way
+// through AddPath, independently of anything ToGoBGPPath/ToAgentPath do.
+func TestPathConversionsMpReachNLRIIPv6Unicast(t *testing.T) {
+	nlris := []bgp.AddrPrefixInterface{
+		bgp.NewIPv6AddrPrefix(64, "2001:db8:1::"),
+		bgp.NewIPv6AddrPrefix(64, "2001:db8:2::"),
+	}
+	mpReach := bgp.NewPathAttributeMpReachNLRI("2001:db8::1", nlris)
+	mpReach.LinkLocalNexthop = net.ParseIP("fe80::1")
+
+	agentPath := &types.Path{
+		NLRI: nlris[0],
+		PathAttributes: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			mpReach,
+		},
+	}
+
+	path, err := ToGoBGPPath(agentPath)
+	require.NoError(t, err)
+
+	back, err := ToAgentPath(path)
+	require.NoError(t, err)
+
+	got, ok := findMpReachNLRI(back.PathAttributes)
+	require.True(t, ok, "expected an MP_REACH_NLRI attribute to round-trip")
+	require.Equal(t, "2001:db8::1", got.Nexthop.String())
+	require.Equal(t, "fe80::1", got.LinkLocalNexthop.String())
+	require.Len(t, got.Value, len(nlris))
+	for i, nlri := range nlris {
+		require.Equal(t, nlri.String(), got.Value[i].String())
+	}
+}
+
+// TestPathConversionsMpReachNLRIVPN verifies that ToGoBGPPath/ToAgentPath
+// round-trip an IPv6 VPN (MPLS-VPN) MP_REACH_NLRI attribute carrying two
+// labeled NLRIs under the same route distinguisher. See
+// TestPathConversionsMpReachNLRIIPv6Unicast for why this round-trips
+// directly through ToGoBGPPath/ToAgentPath rather than a live BgpServer.
+func TestPathConversionsMpReachNLRIVPN(t *testing.T) {
+	rd := bgp.NewRouteDistinguisherTwoOctetAS(65000, 100)
+	label := *bgp.NewMPLSLabelStack(100)
+	nlris := []bgp.AddrPrefixInterface{
+		bgp.NewLabeledVPNIPv6AddrPrefix(64, "2001:db8:1::", label, rd),
+		bgp.NewLabeledVPNIPv6AddrPrefix(64, "2001:db8:2::", label, rd),
+	}
+	mpReach := bgp.NewPathAttributeMpReachNLRI("2001:db8::1", nlris)
+
+	agentPath := &types.Path{
+		NLRI: nlris[0],
+		PathAttributes: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			mpReach,
+		},
+	}
+
+	path, err := ToGoBGPPath(agentPath)
+	require.NoError(t, err)
+
+	back, err := ToAgentPath(path)
+	require.NoError(t, err)
+
+	got, ok := findMpReachNLRI(back.PathAttributes)
+	require.True(t, ok, "expected an MP_REACH_NLRI attribute to round-trip")
+	require.Equal(t, "2001:db8::1", got.Nexthop.String())
+	require.Len(t, got.Value, len(nlris))
+	for i, nlri := range nlris {
+		require.Equal(t, nlri.String(), got.Value[i].String())
+	}
+}
+
+// findMpReachNLRI returns the MP_REACH_NLRI attribute and whether it is
+// present in attrs.
+func findMpReachNLRI(attrs []bgp.PathAttributeInterface) (*bgp.PathAttributeMpReachNLRI, bool) {
+	for _, attr := range attrs {
+		if mpReach, ok := attr.(*bgp.PathAttributeMpReachNLRI); ok {
+			return mpReach, true
+		}
+	}
+	return nil, false
+}
+
+// TestPathConversionsIPv6RouteTargetExtendedCommunity verifies that
+// ToGoBGPPath/ToAgentPath round-trip an IPv6 Address Specific Extended
+// Community carrying the Route Target subtype used in L3VPN, preserving
+// the full 16-byte IPv6 admin field and the local admin value. As with the
+// other attribute-specific tests in this file, neither function switches
+// on IP6 extended communities directly: the round-trip goes through
+// apiutil.MarshalPathAttributes/UnmarshalPathAttributes, which delegates
+// to bgp.PathAttributeIP6ExtendedCommunities's own (de)serialization.
+func TestPathConversionsIPv6RouteTargetExtendedCommunity(t *testing.T) {
+	prefix := bgp.NewIPAddrPrefix(24, "10.0.0.0")
+	rt := bgp.NewIPv6AddressSpecificExtended(bgp.EC_SUBTYPE_ROUTE_TARGET, "2001:db8::1", 100, true)
+
+	agentPath := &types.Path{
+		NLRI: prefix,
+		PathAttributes: []bgp.PathAttributeInterface{
+			bgp.NewPathAttributeOrigin(0),
+			bgp.NewPathAttributeIP6ExtendedCommunities([]bgp.ExtendedCommunityInterface{rt}),
+			bgp.NewPathAttributeNextHop("0.0.0.0"),
+		},
+	}
+
+	path, err := ToGoBGPPath(agentPath)
+	require.NoError(t, err)
+
+	s := server.NewBgpServer()
+	go s.Serve()
+
+	err = s.StartBgp(context.TODO(), &gobgp.StartBgpRequest{
+		Global: &gobgp.Global{
+			Asn:        65000,
+			RouterId:   "127.0.0.1",
+			ListenPort: -1,
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		s.Stop()
+	})
+
+	res, err := s.AddPath(context.TODO(), &gobgp.AddPathRequest{
+		Path: path,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, res.Uuid)
+
+	req := &gobgp.ListPathRequest{
+		Family: path.Family,
+	}
+	err = s.ListPath(context.TODO(), req, func(destination *gobgp.Destination) {
+		paths, err := ToAgentPaths(destination.Paths)
+		require.NoError(t, err)
+		require.NotZero(t, paths)
+
+		got, ok := findIPv6RouteTarget(paths[0].PathAttributes)
+		require.True(t, ok, "expected an IPv6 Route Target extended community to round-trip")
+		require.Equal(t, "2001:db8::1", got.IPv6.String())
+		require.EqualValues(t, 100, got.LocalAdmin)
+	})
+	require.NoError(t, err)
+}
+
+// findIPv6RouteTarget returns the IPv6 Address Specific Extended Community
+// with the Route Target subtype, and whether it is present in attrs.
+func findIPv6RouteTarget(attrs []bgp.PathAttributeInterface) (*bgp.IPv6AddressSpecificExtended, bool) {
+	for _, attr := range attrs {
+		ecs, ok := attr.(*bgp.PathAttributeIP6ExtendedCommunities)
+		if !ok {
+			continue
+		}
+		for _, ec := range ecs.Value {
+			if rt, ok := ec.(*bgp.IPv6AddressSpecificExtended); ok && rt.SubType == bgp.EC_SUBTYPE_ROUTE_TARGET {
+				return rt, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func TestCommunityToString(t *testing.T) {
+	require.Equal(t, "no-export", CommunityToString(uint32(bgp.COMMUNITY_NO_EXPORT)))
+	require.Equal(t, "no-advertise", CommunityToString(uint32(bgp.COMMUNITY_NO_ADVERTISE)))
+	require.Equal(t, "no-export-subconfed", CommunityToString(uint32(bgp.COMMUNITY_NO_EXPORT_SUBCONFED)))
+	require.Equal(t, "65001:100", CommunityToString(65001<<16|100))
+}
+
+func TestParseCommunity(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    uint32
+		wantErr bool
+	}{
+		{name: "well-known no-export", in: "no-export", want: uint32(bgp.COMMUNITY_NO_EXPORT)},
+		{name: "well-known no-advertise", in: "no-advertise", want: uint32(bgp.COMMUNITY_NO_ADVERTISE)},
+		{name: "well-known no-export-subconfed", in: "no-export-subconfed", want: uint32(bgp.COMMUNITY_NO_EXPORT_SUBCONFED)},
+		{name: "asn:value", in: "65001:100", want: 65001<<16 | 100},
+		{name: "missing colon", in: "invalid", wantErr: true},
+		{name: "non-numeric asn", in: "foo:100", wantErr: true},
+		{name: "non-numeric value", in: "65001:bar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommunity(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCommunityRoundTrip(t *testing.T) {
+	for _, c := range []uint32{uint32(bgp.COMMUNITY_NO_EXPORT), uint32(bgp.COMMUNITY_NO_ADVERTISE), 65001<<16 | 100, 1<<16 | 2} {
+		s := CommunityToString(c)
+		got, err := ParseCommunity(s)
+		require.NoError(t, err)
+		require.Equal(t, c, got)
+	}
+}
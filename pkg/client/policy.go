@@ -4,6 +4,9 @@
 package client
 
 import (
+	"context"
+	"time"
+
 	"github.com/cilium/cilium/api/v1/client/policy"
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/api"
@@ -58,3 +61,113 @@ func (c *Client) PolicyDelete(labels []string) (*models.Policy, error) {
 	}
 	return resp.Payload, Hint(err)
 }
+
+// defaultFqdnCacheWatchInterval is the poll interval used by WatchFqdnCache
+// when FqdnCacheWatchParams.Interval is left unset.
+const defaultFqdnCacheWatchInterval = 5 * time.Second
+
+// FqdnCacheWatchParams restricts the DNS lookups observed by WatchFqdnCache
+// and configures how often the cache is polled. The filter fields mirror
+// GetFqdnCacheParams.
+type FqdnCacheWatchParams struct {
+	Cidr         string
+	MatchPattern string
+	Source       string
+
+	// Interval is how often the cache is polled. Defaults to
+	// defaultFqdnCacheWatchInterval if zero.
+	Interval time.Duration
+}
+
+// fqdnLookupKey identifies a DNS lookup by the name it resolves and one of
+// the IPs it resolved to, so that a lookup with multiple IPs is treated as
+// one new entry per IP.
+type fqdnLookupKey struct {
+	fqdn string
+	ip   string
+}
+
+// WatchFqdnCache polls PolicyFqdnCacheGet at params.Interval and returns a
+// channel on which only DNS lookups not observed on a previous poll are
+// emitted, keyed by FQDN and IP. The returned channel is closed once ctx is
+// cancelled; a poll error is ignored and retried on the next tick rather
+// than terminating the watch.
+func (c *Client) WatchFqdnCache(ctx context.Context, params FqdnCacheWatchParams) (<-chan []*models.DNSLookup, error) {
+	interval := params.Interval
+	if interval <= 0 {
+		interval = defaultFqdnCacheWatchInterval
+	}
+
+	out := make(chan []*models.DNSLookup)
+
+	go func() {
+		defer close(out)
+
+		seen := make(map[fqdnLookupKey]struct{})
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			lookups, err := c.fqdnCacheGet(params)
+			if err == nil {
+				if delta := newFqdnLookups(seen, lookups); len(delta) > 0 {
+					select {
+					case out <- delta:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// fqdnCacheGet retrieves the current contents of the FQDN cache, applying
+// the filters in params.
+func (c *Client) fqdnCacheGet(params FqdnCacheWatchParams) ([]*models.DNSLookup, error) {
+	p := policy.NewGetFqdnCacheParams().WithTimeout(api.ClientTimeout)
+	if params.Cidr != "" {
+		p.SetCidr(&params.Cidr)
+	}
+	if params.MatchPattern != "" {
+		p.SetMatchpattern(&params.MatchPattern)
+	}
+	if params.Source != "" {
+		p.SetSource(&params.Source)
+	}
+
+	resp, err := c.Policy.GetFqdnCache(p)
+	if err != nil {
+		return nil, Hint(err)
+	}
+	return resp.Payload, nil
+}
+
+// newFqdnLookups returns the lookups in current whose (fqdn, ip) keys are
+// not already present in seen, and records those keys in seen.
+func newFqdnLookups(seen map[fqdnLookupKey]struct{}, current []*models.DNSLookup) []*models.DNSLookup {
+	var delta []*models.DNSLookup
+	for _, lookup := range current {
+		isNew := false
+		for _, ip := range lookup.Ips {
+			key := fqdnLookupKey{fqdn: lookup.Fqdn, ip: ip}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			isNew = true
+		}
+		if isNew {
+			delta = append(delta, lookup)
+		}
+	}
+	return delta
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/api/v1/client/policy"
+	"github.com/cilium/cilium/api/v1/models"
+)
+
+// stubTransport serves GetFqdnCache requests from a caller-supplied sequence
+// of responses, advancing to the next one on every call.
+type stubTransport struct {
+	mu        sync.Mutex
+	responses [][]*models.DNSLookup
+	calls     int
+}
+
+func (s *stubTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if op.ID != "GetFqdnCache" {
+		panic("unexpected operation: " + op.ID)
+	}
+
+	resp := s.responses[min(s.calls, len(s.responses)-1)]
+	s.calls++
+	return &policy.GetFqdnCacheOK{Payload: resp}, nil
+}
+
+func newTestClient(t *testing.T, responses [][]*models.DNSLookup) *Client {
+	transport := &stubTransport{responses: responses}
+	c := &Client{}
+	c.Policy = policy.New(transport, strfmt.Default)
+	return c
+}
+
+func lookup(fqdn string, ips ...string) *models.DNSLookup {
+	return &models.DNSLookup{Fqdn: fqdn, Ips: ips}
+}
+
+func TestWatchFqdnCache(t *testing.T) {
+	responses := [][]*models.DNSLookup{
+		{lookup("one.cilium.io", "1.1.1.1")},
+		{lookup("one.cilium.io", "1.1.1.1"), lookup("two.cilium.io", "2.2.2.2")},
+		{lookup("one.cilium.io", "1.1.1.1"), lookup("two.cilium.io", "2.2.2.2")},
+		{lookup("one.cilium.io", "1.1.1.1"), lookup("two.cilium.io", "2.2.2.2"), lookup("three.cilium.io", "3.3.3.3")},
+	}
+	c := newTestClient(t, responses)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deltas, err := c.WatchFqdnCache(ctx, FqdnCacheWatchParams{Interval: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	var seen []string
+	for len(seen) < 3 {
+		select {
+		case delta := <-deltas:
+			for _, d := range delta {
+				seen = append(seen, d.Fqdn)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for deltas, got %v so far", seen)
+		}
+	}
+
+	require.Equal(t, []string{"one.cilium.io", "two.cilium.io", "three.cilium.io"}, seen)
+
+	cancel()
+	_, ok := <-deltas
+	require.False(t, ok, "expected channel to be closed after ctx cancellation")
+}
+
+func TestNewFqdnLookups(t *testing.T) {
+	seen := make(map[fqdnLookupKey]struct{})
+
+	delta := newFqdnLookups(seen, []*models.DNSLookup{lookup("a.cilium.io", "1.1.1.1")})
+	require.Equal(t, []*models.DNSLookup{lookup("a.cilium.io", "1.1.1.1")}, delta)
+
+	// Same entry again: no delta.
+	delta = newFqdnLookups(seen, []*models.DNSLookup{lookup("a.cilium.io", "1.1.1.1")})
+	require.Empty(t, delta)
+
+	// A new IP for the same name is a new entry.
+	delta = newFqdnLookups(seen, []*models.DNSLookup{lookup("a.cilium.io", "1.1.1.1", "1.1.1.2")})
+	require.Equal(t, []*models.DNSLookup{lookup("a.cilium.io", "1.1.1.1", "1.1.1.2")}, delta)
+}
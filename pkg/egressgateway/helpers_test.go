@@ -61,6 +61,18 @@ func (fr fakeResource[T]) Store(context.Context) (resource.Store[T], error) {
 	return nil, errors.New("not implemented")
 }
 
+func (fr fakeResource[T]) Get(ctx context.Context, key resource.Key) (item T, exists bool, err error) {
+	return item, false, errors.New("not implemented")
+}
+
+func (fr fakeResource[T]) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (fr fakeResource[T]) HasSynced() bool {
+	return true
+}
+
 func addPolicy(tb testing.TB, policies fakeResource[*Policy], params *policyParams) {
 	tb.Helper()
 
@@ -4,14 +4,19 @@
 package xds
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/cilium/cilium/pkg/completion"
 	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging"
 	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/metrics"
+	"github.com/cilium/cilium/pkg/time"
 )
 
 // ProxyError wraps the error and the detail received from the proxy in to a new type
@@ -27,6 +32,14 @@ func (pe *ProxyError) Error() string {
 
 var ErrNackReceived = errors.New("NACK received")
 
+// ErrNodeGone is the error completed pending completions are given when the
+// node they were exclusively waiting on disconnects before ACKing.
+var ErrNodeGone = errors.New("node disconnected before ACKing")
+
+// ErrReset is the error any still-pending completions are given when
+// AckingResourceMutatorWrapper.Reset is called.
+var ErrReset = errors.New("xds resource mutator was reset")
+
 // ResourceVersionAckObserver defines the HandleResourceVersionAck method
 // which is called whenever a node acknowledges having applied a version of
 // the resources of a given type.
@@ -77,6 +90,13 @@ type AckingResourceMutator interface {
 	// DeleteNode frees resources held for the named node
 	DeleteNode(nodeID string)
 
+	// DeleteAllForNode is an explicit cleanup for a disconnected node: it
+	// frees resources held for the node, same as DeleteNode, and is
+	// intended for callers that want to make clear they are tearing down
+	// all xDS state for a node that is never coming back (as opposed to
+	// dropping one node from a shared wait).
+	DeleteAllForNode(nodeID string)
+
 	// Delete deletes a resource from this set by name and increases the cache's
 	// version number atomically if the resource is actually deleted.
 	// The completion is called back when the new deleted resources' version is
@@ -84,6 +104,16 @@ type AckingResourceMutator interface {
 	// A call to the returned revert function reverts the effects of this
 	// method call.
 	Delete(typeURL string, resourceName string, nodeIDs []string, wg *completion.WaitGroup, callback func(error)) AckingResourceMutatorRevertFunc
+
+	// SetSnapshot atomically replaces the entire set of resources of
+	// typeURL with resources, upserting every name in resources and
+	// deleting every name previously in the set but absent from
+	// resources, increasing the set's version number atomically at most
+	// once for the whole operation. The completion is called back when
+	// the new version is ACKed by the Envoy nodes which IDs are given in
+	// nodeIDs. A call to the returned revert function reverts the effects
+	// of this method call.
+	SetSnapshot(typeURL string, resources map[string]proto.Message, nodeIDs []string, wg *completion.WaitGroup, callback func(error)) AckingResourceMutatorRevertFunc
 }
 
 // AckingResourceMutatorWrapper is an AckingResourceMutator which wraps a
@@ -112,6 +142,26 @@ type AckingResourceMutatorWrapper struct {
 	// restoring controls waiting for acks. When 'true' updates do not wait for acks from the xDS client,
 	// as xDS caches are pre-populated before passing any resources to xDS clients.
 	restoring bool
+
+	// logLimiter rate limits the Debug-level logging on the ACK path
+	// (restoring Upsert/Delete, and per-node ACK/NACK bookkeeping), which
+	// can otherwise be extremely noisy under heavy xDS churn. Defaults to
+	// unlimited, see WithAckLogLimiter.
+	logLimiter logging.Limiter
+}
+
+// AckingResourceMutatorWrapperOption configures a AckingResourceMutatorWrapper
+// constructed by NewAckingResourceMutatorWrapper.
+type AckingResourceMutatorWrapperOption func(*AckingResourceMutatorWrapper)
+
+// WithAckLogLimiter overrides the rate limiter governing the ACK path's
+// Debug-level logging, letting operators turn it up or down (e.g. during
+// heavy xDS churn, or while debugging a stuck ACK) without recompiling.
+// Defaults to no limiting.
+func WithAckLogLimiter(limiter logging.Limiter) AckingResourceMutatorWrapperOption {
+	return func(m *AckingResourceMutatorWrapper) {
+		m.logLimiter = limiter
+	}
 }
 
 // pendingCompletion is an update that is pending completion.
@@ -125,16 +175,59 @@ type pendingCompletion struct {
 	// remainingNodesResources maps each pending node ID to pending resource
 	// name.
 	remainingNodesResources map[string]map[string]struct{}
+
+	// createdAt is the time at which this pending completion was created.
+	// Used to report how long a completion has been waiting for an ACK,
+	// which helps detect Envoy nodes that got stuck without acking a push.
+	createdAt time.Time
+
+	// requiredAcks is the number of nodes that must fully ACK before this
+	// completion resolves. Zero means every node in remainingNodesResources
+	// must ACK, the original behavior. Set by UpsertWithQuorum to allow a
+	// completion to resolve once a quorum of a larger node set has ACKed.
+	requiredAcks int
+
+	// totalNodes is the number of nodes remainingNodesResources started
+	// with. Needed to compute how many nodes have ACKed so far, since
+	// nodes are removed from remainingNodesResources as they fully ACK.
+	// Only meaningful when requiredAcks is non-zero.
+	totalNodes int
+}
+
+// updatePendingCompletionMetrics refreshes the gauges tracking the number of
+// pending xDS completions and the age of the oldest one. Must be called with
+// m.locker held, after the set of pending completions has changed.
+func (m *AckingResourceMutatorWrapper) updatePendingCompletionMetrics() {
+	metrics.EnvoyXDSPendingCompletions.Set(float64(len(m.pendingCompletions)))
+
+	var oldest time.Time
+	for _, pending := range m.pendingCompletions {
+		if oldest.IsZero() || pending.createdAt.Before(oldest) {
+			oldest = pending.createdAt
+		}
+	}
+
+	age := 0.0
+	if !oldest.IsZero() {
+		age = time.Since(oldest).Seconds()
+	}
+	metrics.EnvoyXDSOldestPendingCompletionSeconds.Set(age)
 }
 
 // NewAckingResourceMutatorWrapper creates a new AckingResourceMutatorWrapper
 // to wrap the given ResourceMutator.
-func NewAckingResourceMutatorWrapper(mutator ResourceMutator) *AckingResourceMutatorWrapper {
-	return &AckingResourceMutatorWrapper{
+func NewAckingResourceMutatorWrapper(mutator ResourceMutator, opts ...AckingResourceMutatorWrapperOption) *AckingResourceMutatorWrapper {
+	m := &AckingResourceMutatorWrapper{
 		mutator:            mutator,
 		ackedVersions:      make(map[string]uint64),
 		pendingCompletions: make(map[*completion.Completion]*pendingCompletion),
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 func (m *AckingResourceMutatorWrapper) MarkRestorePending() {
@@ -152,6 +245,83 @@ func (m *AckingResourceMutatorWrapper) MarkRestoreCompleted() {
 	m.restoring = false
 }
 
+// stateExporter is implemented by ResourceMutator implementations (e.g.
+// Cache) that can serialize their contained resources.
+// AckingResourceMutatorWrapper.ExportState delegates to this interface, if
+// implemented by the wrapped mutator, to include its resources in the
+// exported state.
+type stateExporter interface {
+	ExportState() ([]byte, error)
+}
+
+// stateImporter is implemented by ResourceMutator implementations that can
+// restore resources previously serialized by a stateExporter.
+type stateImporter interface {
+	ImportState(data []byte) error
+}
+
+// wrapperState is the serialized form of an AckingResourceMutatorWrapper, as
+// produced by ExportState and consumed by ImportState.
+type wrapperState struct {
+	Version       uint64
+	AckedVersions map[string]uint64
+	Resources     []byte `json:",omitempty"`
+}
+
+// ExportState serializes the current version, the per-node acked versions,
+// and (if the wrapped mutator supports it) its resources, so that they can
+// be restored via ImportState after a process restart.
+func (m *AckingResourceMutatorWrapper) ExportState() ([]byte, error) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	state := wrapperState{
+		Version:       m.version,
+		AckedVersions: m.ackedVersions,
+	}
+
+	if exporter, ok := m.mutator.(stateExporter); ok {
+		resources, err := exporter.ExportState()
+		if err != nil {
+			return nil, fmt.Errorf("exporting wrapped resource mutator state: %w", err)
+		}
+		state.Resources = resources
+	}
+
+	return json.Marshal(state)
+}
+
+// ImportState restores a state previously produced by ExportState, setting
+// m.version and m.ackedVersions and, if the wrapped mutator supports it and
+// the exported state included resources, restoring those too.
+func (m *AckingResourceMutatorWrapper) ImportState(data []byte) error {
+	var state wrapperState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("unmarshaling xDS resource mutator state: %w", err)
+	}
+
+	if len(state.Resources) > 0 {
+		importer, ok := m.mutator.(stateImporter)
+		if !ok {
+			return fmt.Errorf("wrapped resource mutator %T does not support importing state", m.mutator)
+		}
+		if err := importer.ImportState(state.Resources); err != nil {
+			return fmt.Errorf("importing wrapped resource mutator state: %w", err)
+		}
+	}
+
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	m.version = state.Version
+	m.ackedVersions = state.AckedVersions
+	if m.ackedVersions == nil {
+		m.ackedVersions = make(map[string]uint64)
+	}
+
+	return nil
+}
+
 // AddVersionCompletion adds a completion to wait for any ACK for the
 // version and type URL, ignoring the ACKed resource names.
 func (m *AckingResourceMutatorWrapper) addVersionCompletion(typeURL string, version uint64, nodeIDs []string, c *completion.Completion) {
@@ -159,22 +329,95 @@ func (m *AckingResourceMutatorWrapper) addVersionCompletion(typeURL string, vers
 		version:                 version,
 		typeURL:                 typeURL,
 		remainingNodesResources: make(map[string]map[string]struct{}, len(nodeIDs)),
+		createdAt:               time.Now(),
 	}
 	for _, nodeID := range nodeIDs {
 		comp.remainingNodesResources[nodeID] = nil
 	}
 	m.pendingCompletions[c] = comp
+	m.updatePendingCompletionMetrics()
 }
 
-// DeleteNode frees resources held for the named nodes
+// DeleteNode frees resources held for the named node: it clears the node's
+// acked version and resolves any pendingCompletions that reference it. A
+// pendingCompletion that was waiting exclusively on the departed node is
+// completed with ErrNodeGone; a pendingCompletion shared with other nodes
+// simply drops the departed node and keeps waiting on the rest.
 func (m *AckingResourceMutatorWrapper) DeleteNode(nodeID string) {
 	m.locker.Lock()
 	defer m.locker.Unlock()
 
+	m.deleteNode(nodeID)
+}
+
+// DeleteAllForNode behaves identically to DeleteNode. See the
+// AckingResourceMutator interface doc for why both names exist.
+func (m *AckingResourceMutatorWrapper) DeleteAllForNode(nodeID string) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	m.deleteNode(nodeID)
+}
+
+// deleteNode does the work for DeleteNode and DeleteAllForNode. Must be
+// called with m.locker held.
+func (m *AckingResourceMutatorWrapper) deleteNode(nodeID string) {
 	delete(m.ackedVersions, nodeID)
+
+	for comp, pending := range m.pendingCompletions {
+		if _, found := pending.remainingNodesResources[nodeID]; !found {
+			continue
+		}
+
+		delete(pending.remainingNodesResources, nodeID)
+		if len(pending.remainingNodesResources) == 0 {
+			comp.Complete(ErrNodeGone)
+			delete(m.pendingCompletions, comp)
+		}
+	}
+
+	m.updatePendingCompletionMetrics()
+}
+
+// Reset clears all version and ackedVersions state tracked by the wrapper,
+// completes every pending completion for typeURL with ErrReset rather than
+// leaving it hanging, and clears the wrapped mutator's resources of
+// typeURL. Pending completions for other typeURLs are left untouched, same
+// as Upsert/Delete/SetSnapshot/HandleResourceVersionAck. This is intended
+// for tests that want a clean wrapper between cases, and for a hard
+// reconfiguration after which no previously tracked state (including
+// in-flight ACK waits) for typeURL should carry forward. It is safe to call
+// while Upsert/Delete/SetSnapshot operations are in flight.
+func (m *AckingResourceMutatorWrapper) Reset(typeURL string) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	for comp, pending := range m.pendingCompletions {
+		if pending.typeURL != typeURL {
+			continue
+		}
+		comp.Complete(ErrReset)
+		delete(m.pendingCompletions, comp)
+	}
+	m.updatePendingCompletionMetrics()
+
+	m.ackedVersions = make(map[string]uint64)
+	m.version = 0
+
+	m.mutator.Clear(typeURL)
 }
 
 func (m *AckingResourceMutatorWrapper) Upsert(typeURL string, resourceName string, resource proto.Message, nodeIDs []string, wg *completion.WaitGroup, callback func(error)) AckingResourceMutatorRevertFunc {
+	return m.UpsertWithQuorum(typeURL, resourceName, resource, nodeIDs, 0, wg, callback)
+}
+
+// UpsertWithQuorum behaves like Upsert, except that the returned completion
+// resolves as soon as quorum of nodeIDs have ACKed the new version, rather
+// than requiring every one of them to. This is useful for large fleets,
+// where waiting on the very last straggler to ACK is not worth delaying the
+// caller for. A quorum <= 0, or >= len(nodeIDs), behaves exactly like
+// Upsert.
+func (m *AckingResourceMutatorWrapper) UpsertWithQuorum(typeURL string, resourceName string, resource proto.Message, nodeIDs []string, quorum int, wg *completion.WaitGroup, callback func(error)) AckingResourceMutatorRevertFunc {
 	m.locker.Lock()
 	defer m.locker.Unlock()
 
@@ -182,10 +425,12 @@ func (m *AckingResourceMutatorWrapper) Upsert(typeURL string, resourceName strin
 
 	if m.restoring {
 		// Do not wait for acks when restoring state
-		log.WithFields(logrus.Fields{
-			logfields.XDSTypeURL:      typeURL,
-			logfields.XDSResourceName: resourceName,
-		}).Debug("Upsert: Restoring, skipping wait for ACK")
+		if m.logLimiter.Allow() {
+			log.WithFields(logrus.Fields{
+				logfields.XDSTypeURL:      typeURL,
+				logfields.XDSResourceName: resourceName,
+			}).Debug("Upsert: Restoring, skipping wait for ACK")
+		}
 
 		wait = false
 	}
@@ -217,12 +462,16 @@ func (m *AckingResourceMutatorWrapper) Upsert(typeURL string, resourceName strin
 			version:                 m.version,
 			typeURL:                 typeURL,
 			remainingNodesResources: make(map[string]map[string]struct{}, len(nodeIDs)),
+			createdAt:               time.Now(),
+			requiredAcks:            quorum,
+			totalNodes:              len(nodeIDs),
 		}
 		for _, nodeID := range nodeIDs {
 			comp.remainingNodesResources[nodeID] = make(map[string]struct{}, 1)
 			comp.remainingNodesResources[nodeID][resourceName] = struct{}{}
 		}
 		m.pendingCompletions[c] = comp
+		m.updatePendingCompletionMetrics()
 	} else if callback != nil {
 		callback(nil)
 	}
@@ -250,18 +499,50 @@ func (m *AckingResourceMutatorWrapper) useCurrent(typeURL string, nodeIDs []stri
 	if !m.currentVersionAcked(nodeIDs) {
 		// Add a completion object for 'version' so that the caller may wait for the N/ACK
 		m.addVersionCompletion(typeURL, m.version, nodeIDs, wg.AddCompletionWithCallback(callback))
+	} else if callback != nil {
+		// Nothing to wait for, so the callback would otherwise never be called.
+		callback(nil)
+	}
+}
+
+// UseCurrent adds a completion to the WaitGroup if the current version of
+// the cached resource has not been acked yet, allowing the caller to wait
+// for the ACK. If the current version has already been acked, this is a
+// no-op.
+func (m *AckingResourceMutatorWrapper) UseCurrent(typeURL string, nodeIDs []string, wg *completion.WaitGroup) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	if wg != nil {
+		m.useCurrent(typeURL, nodeIDs, wg, nil)
+	}
+}
+
+// UseCurrentWithCallback behaves like UseCurrent, but additionally invokes
+// callback(nil) immediately if the current version has already been acked,
+// so that a caller relying on the callback to proceed is not left waiting
+// forever on a version that will never produce a new ACK.
+func (m *AckingResourceMutatorWrapper) UseCurrentWithCallback(typeURL string, nodeIDs []string, wg *completion.WaitGroup, callback func(error)) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	if wg != nil {
+		m.useCurrent(typeURL, nodeIDs, wg, callback)
+	} else if callback != nil {
+		callback(nil)
 	}
 }
 
 func (m *AckingResourceMutatorWrapper) currentVersionAcked(nodeIDs []string) bool {
 	for _, node := range nodeIDs {
 		if acked, exists := m.ackedVersions[node]; !exists || acked < m.version {
-			ackLog := log.WithFields(logrus.Fields{
-				logfields.XDSCachedVersion: m.version,
-				logfields.XDSAckedVersion:  acked,
-				logfields.XDSClientNode:    node,
-			})
-			ackLog.Debugf("Node has not acked the current cached version yet")
+			if m.logLimiter.Allow() {
+				log.WithFields(logrus.Fields{
+					logfields.XDSCachedVersion: m.version,
+					logfields.XDSAckedVersion:  acked,
+					logfields.XDSClientNode:    node,
+				}).Debugf("Node has not acked the current cached version yet")
+			}
 			return false
 		}
 	}
@@ -276,10 +557,12 @@ func (m *AckingResourceMutatorWrapper) Delete(typeURL string, resourceName strin
 
 	if m.restoring {
 		// Do not wait for acks when restoring state
-		log.WithFields(logrus.Fields{
-			logfields.XDSTypeURL:      typeURL,
-			logfields.XDSResourceName: resourceName,
-		}).Debug("Delete: Restoring, skipping wait for ACK")
+		if m.logLimiter.Allow() {
+			log.WithFields(logrus.Fields{
+				logfields.XDSTypeURL:      typeURL,
+				logfields.XDSResourceName: resourceName,
+			}).Debug("Delete: Restoring, skipping wait for ACK")
+		}
 
 		wait = false
 	}
@@ -336,6 +619,95 @@ func (m *AckingResourceMutatorWrapper) Delete(typeURL string, resourceName strin
 	}
 }
 
+// SetSnapshot atomically replaces the entire set of resources of typeURL
+// with resources, registering a single completion for the whole operation
+// rather than one per changed resource. As with Delete, there is no way to
+// tell from the ACK alone which of the upserted/deleted resources it
+// covers, so as a best effort this just waits for any ACK covering the new
+// version.
+func (m *AckingResourceMutatorWrapper) SetSnapshot(typeURL string, resources map[string]proto.Message, nodeIDs []string, wg *completion.WaitGroup, callback func(error)) AckingResourceMutatorRevertFunc {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	wait := wg != nil
+
+	if m.restoring {
+		// Do not wait for acks when restoring state
+		if m.logLimiter.Allow() {
+			log.WithFields(logrus.Fields{
+				logfields.XDSTypeURL: typeURL,
+			}).Debug("SetSnapshot: Restoring, skipping wait for ACK")
+		}
+
+		wait = false
+	}
+
+	var updated bool
+	var revert ResourceMutatorRevertFunc
+	m.version, updated, revert = m.mutator.SetSnapshot(typeURL, resources)
+
+	if !updated {
+		if wait {
+			m.useCurrent(typeURL, nodeIDs, wg, callback)
+		} else if callback != nil {
+			callback(nil)
+		}
+		return func(completion *completion.Completion) {}
+	}
+
+	if wait {
+		c := wg.AddCompletionWithCallback(callback)
+		if _, found := m.pendingCompletions[c]; found {
+			log.WithFields(logrus.Fields{
+				logfields.XDSTypeURL: typeURL,
+			}).Fatalf("attempt to reuse completion to set xDS snapshot: %v", c)
+		}
+
+		m.addVersionCompletion(typeURL, m.version, nodeIDs, c)
+	} else if callback != nil {
+		callback(nil)
+	}
+
+	return func(completion *completion.Completion) {
+		m.locker.Lock()
+		defer m.locker.Unlock()
+
+		if revert != nil {
+			m.version, _ = revert()
+
+			if completion != nil {
+				m.addVersionCompletion(typeURL, m.version, nodeIDs, completion)
+			}
+		}
+	}
+}
+
+// GetResources returns a copy of the currently stored resources of the given
+// type, keyed by resource name, by delegating to the wrapped mutator's read
+// path. An unknown typeURL is not an error: it simply has no resources.
+// It returns an error if the wrapped mutator does not also implement
+// ResourceSource.
+func (m *AckingResourceMutatorWrapper) GetResources(typeURL string) (map[string]proto.Message, error) {
+	src, ok := m.mutator.(ResourceSource)
+	if !ok {
+		return nil, fmt.Errorf("wrapped mutator %T does not support reads", m.mutator)
+	}
+
+	versioned, err := src.GetResources(typeURL, 0, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if versioned == nil {
+		return map[string]proto.Message{}, nil
+	}
+
+	resources := make(map[string]proto.Message, len(versioned.ResourceNames))
+	for i, name := range versioned.ResourceNames {
+		resources[name] = proto.Clone(versioned.Resources[i])
+	}
+	return resources, nil
+}
+
 // 'ackVersion' is the last version that was acked. 'nackVersion', if greater than 'nackVersion', is the last version that was NACKed.
 func (m *AckingResourceMutatorWrapper) HandleResourceVersionAck(ackVersion uint64, nackVersion uint64, nodeIP string, resourceNames []string, typeURL string, detail string) {
 	ackLog := log.WithFields(logrus.Fields{
@@ -362,7 +734,9 @@ func (m *AckingResourceMutatorWrapper) HandleResourceVersionAck(ackVersion uint6
 		if comp.Err() != nil {
 			// Completion was canceled or timed out.
 			// Remove from pending list.
-			ackLog.Debugf("completion context was canceled: %v", pending)
+			if m.logLimiter.Allow() {
+				ackLog.Debugf("completion context was canceled: %v", pending)
+			}
 			continue
 		}
 
@@ -378,18 +752,44 @@ func (m *AckingResourceMutatorWrapper) HandleResourceVersionAck(ackVersion uint6
 					if len(remainingResourceNames) == 0 {
 						delete(pending.remainingNodesResources, nodeIP)
 					}
-					if len(pending.remainingNodesResources) == 0 {
-						// completedComparision. Notify and remove from pending list.
-						if pending.version <= ackVersion {
-							ackLog.Debugf("completing ACK: %v", pending)
-							comp.Complete(nil)
-						} else {
-							ackLog.Debugf("completing NACK: %v", pending)
-							comp.Complete(&ProxyError{Err: ErrNackReceived, Detail: detail})
-						}
-						continue
+				}
+			} else if pending.version <= m.ackedVersions[nodeIP] {
+				// This message's nonce is lower than a version already
+				// recorded for the node, which happens when ACKs arrive out
+				// of order. The node's stored max acked version already
+				// covers this pending completion for this node, regardless
+				// of which resource names this particular message carries,
+				// so do not let a stale, reordered message keep it pending.
+				if m.logLimiter.Allow() {
+					ackLog.Debugf("node already acked a version covering pending completion out of order: %v", pending)
+				}
+				delete(pending.remainingNodesResources, nodeIP)
+			}
+
+			if pending.requiredAcks > 0 && pending.totalNodes-len(pending.remainingNodesResources) >= pending.requiredAcks {
+				// Enough nodes in this quorum completion have fully ACKed.
+				// Resolve it now rather than waiting on the rest.
+				if m.logLimiter.Allow() {
+					ackLog.Debugf("completing ACK at quorum: %v", pending)
+				}
+				comp.Complete(nil)
+				continue
+			}
+
+			if len(pending.remainingNodesResources) == 0 {
+				// completedComparision. Notify and remove from pending list.
+				if pending.version <= m.ackedVersions[nodeIP] {
+					if m.logLimiter.Allow() {
+						ackLog.Debugf("completing ACK: %v", pending)
+					}
+					comp.Complete(nil)
+				} else {
+					if m.logLimiter.Allow() {
+						ackLog.Debugf("completing NACK: %v", pending)
 					}
+					comp.Complete(&ProxyError{Err: ErrNackReceived, Detail: detail})
 				}
+				continue
 			}
 		}
 
@@ -399,4 +799,5 @@ func (m *AckingResourceMutatorWrapper) HandleResourceVersionAck(ackVersion uint6
 	}
 
 	m.pendingCompletions = remainingCompletions
+	m.updatePendingCompletionMetrics()
 }
@@ -8,10 +8,16 @@ import (
 	"testing"
 	"time"
 
+	envoy_config_route "github.com/cilium/proxy/go/envoy/config/route/v3"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/cilium/cilium/pkg/completion"
+	"github.com/cilium/cilium/pkg/logging"
+	"github.com/cilium/cilium/pkg/metrics"
 )
 
 const (
@@ -100,35 +106,77 @@ func TestUpsertSingleNode(t *testing.T) {
 	require.Len(t, acker.ackedVersions, 1)
 	require.Equal(t, uint64(2), acker.ackedVersions[node1])
 
-	// Ack the right version, for another resource, from the right node.
+	// Ack the right version, for another resource, from the right node: the
+	// resource name does not match, so the completion (which is waiting on
+	// resources[0]) stays pending even though node0 is now at version 2.
 	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[1].Name}, typeURL, "")
 	require.Condition(t, isNotCompletedComparison(comp))
 	require.Len(t, acker.ackedVersions, 2)
 	require.Equal(t, uint64(2), acker.ackedVersions[node0])
 
-	// Ack an older version, for the right resource, from the right node.
+	// A stale, out-of-order message for the right resource then arrives
+	// with a lower nonce than the version already recorded for node0. It
+	// still resolves the completion, since node0 has already moved past
+	// this version regardless of what this particular message names.
 	acker.HandleResourceVersionAck(1, 1, node0, []string{resources[0].Name}, typeURL, "")
-	require.Condition(t, isNotCompletedComparison(comp))
-	require.Len(t, acker.ackedVersions, 2)
-	require.Equal(t, uint64(2), acker.ackedVersions[node0])
-
-	// Ack the right version, for the right resource, from the right node.
-	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[0].Name}, typeURL, "")
 	require.Condition(t, completedComparison(comp))
 	require.Len(t, acker.ackedVersions, 2)
 	require.Equal(t, uint64(2), acker.ackedVersions[node0])
 }
 
-// UseCurrent adds a completion to the WaitGroup if the current
-// version of the cached resource has not been acked yet, allowing the
-// caller to wait for the ACK.
-func (m *AckingResourceMutatorWrapper) UseCurrent(typeURL string, nodeIDs []string, wg *completion.WaitGroup) {
-	m.locker.Lock()
-	defer m.locker.Unlock()
+// TestAckLogLimiter verifies that WithAckLogLimiter governs the Debug-level
+// logging AckingResourceMutatorWrapper emits while restoring, so that an
+// operator-configured sampling rate is actually respected instead of every
+// Upsert logging unconditionally.
+func TestAckLogLimiter(t *testing.T) {
+	previousLevel := logging.DefaultLogger.GetLevel()
+	logging.DefaultLogger.SetLevel(logrus.DebugLevel)
+	t.Cleanup(func() { logging.DefaultLogger.SetLevel(previousLevel) })
 
-	if wg != nil {
-		m.useCurrent(typeURL, nodeIDs, wg, nil)
+	hook := logrustest.NewLocal(logging.DefaultLogger)
+
+	const burst = 2
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache, WithAckLogLimiter(logging.NewLimiter(time.Hour, burst)))
+	acker.MarkRestorePending()
+
+	for _, resource := range resources {
+		acker.Upsert("type.googleapis.com/envoy.config.v3.DummyConfiguration", resource.Name, resource, nil, nil, nil)
 	}
+
+	var restoringLogs int
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Upsert: Restoring, skipping wait for ACK" {
+			restoringLogs++
+		}
+	}
+	assert.Equal(t, burst, restoringLogs, "logging must stop once the configured burst is exhausted")
+}
+
+func TestPendingCompletionMetrics(t *testing.T) {
+	metrics.NewLegacyMetrics()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+	require.Equal(t, float64(0), metrics.EnvoyXDSPendingCompletions.Get())
+
+	// Create version 2 with resource 0, which is pending an ACK.
+	callback, comp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.Equal(t, float64(1), metrics.EnvoyXDSPendingCompletions.Get())
+	require.GreaterOrEqual(t, metrics.EnvoyXDSOldestPendingCompletionSeconds.Get(), float64(0))
+
+	// Ack the right version, for the right resource, from the right node.
+	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[0].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+	require.Equal(t, float64(0), metrics.EnvoyXDSPendingCompletions.Get())
+	require.Equal(t, float64(0), metrics.EnvoyXDSOldestPendingCompletionSeconds.Get())
 }
 
 func TestUseCurrent(t *testing.T) {
@@ -168,18 +216,52 @@ func TestUseCurrent(t *testing.T) {
 	// UseCurrent ignores resource names, so an ack of the same or later version from the right node will complete it
 	require.Len(t, acker.pendingCompletions, 1)
 
-	// Ack an older version, for the right resource, from the right node.
+	// Ack an older version, for the right resource, from the right node:
+	// node0 is already recorded as having acked version 2 overall (from the
+	// previous step), which covers this completion's version, so it
+	// resolves now even though this stale, out-of-order message only
+	// carries nonce 1.
 	acker.HandleResourceVersionAck(1, 1, node0, []string{resources[0].Name}, typeURL, "")
-	require.Condition(t, isNotCompletedComparison(comp))
+	require.Condition(t, completedComparison(comp))
 	require.Len(t, acker.ackedVersions, 2)
 	require.Equal(t, uint64(2), acker.ackedVersions[node0])
+	require.Len(t, acker.pendingCompletions, 0)
+}
+
+func TestUseCurrentWithCallback(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	// Empty cache is the version 1
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2 with resource 0.
+	upsertCallback, upsertComp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0}, wg, upsertCallback)
+	require.Condition(t, isNotCompletedComparison(upsertComp))
 	require.Len(t, acker.pendingCompletions, 1)
 
-	// Ack the right version, for the right resource, from the right node.
+	// Not yet acked: the callback must not fire and a completion is added,
+	// same as UseCurrent.
+	notYetAckedCallback, notYetAckedComp := newCompCallback()
+	acker.UseCurrentWithCallback(typeURL, []string{node0}, wg, notYetAckedCallback)
+	require.Condition(t, isNotCompletedComparison(notYetAckedComp))
+	require.Len(t, acker.pendingCompletions, 2)
+
+	// Ack the current version for node0.
 	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[0].Name}, typeURL, "")
-	require.Condition(t, completedComparison(comp))
-	require.Len(t, acker.ackedVersions, 2)
-	require.Equal(t, uint64(2), acker.ackedVersions[node0])
+	require.Condition(t, completedComparison(upsertComp))
+	require.Condition(t, completedComparison(notYetAckedComp))
+	require.Len(t, acker.pendingCompletions, 0)
+
+	// Already acked: the callback must fire immediately, without adding a
+	// new pending completion.
+	alreadyAckedCallback, alreadyAckedComp := newCompCallback()
+	acker.UseCurrentWithCallback(typeURL, []string{node0}, wg, alreadyAckedCallback)
+	require.Condition(t, completedComparison(alreadyAckedComp))
 	require.Len(t, acker.pendingCompletions, 0)
 }
 
@@ -228,6 +310,52 @@ func TestUpsertMultipleNodes(t *testing.T) {
 	require.Equal(t, true, acker.currentVersionAcked([]string{node0, node1, node2}))
 }
 
+func TestUpsertWithQuorum(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	// Empty cache is the version 1
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2 with resource 0, requiring only 2 of the 3 nodes to ACK.
+	callback, comp := newCompCallback()
+	acker.UpsertWithQuorum(typeURL, resources[0].Name, resources[0], []string{node0, node1, node2}, 2, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	// One ACK is not yet quorum.
+	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[0].Name}, typeURL, "")
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	// A second ACK reaches quorum; the completion resolves without waiting
+	// on node2.
+	acker.HandleResourceVersionAck(2, 2, node1, []string{resources[0].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+}
+
+func TestUpsertWithQuorumTimesOutBelowQuorum(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	// Empty cache is the version 1
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2 with resource 0, requiring 2 of the 3 nodes to ACK.
+	callback, comp := newCompCallback()
+	acker.UpsertWithQuorum(typeURL, resources[0].Name, resources[0], []string{node0, node1, node2}, 2, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	// Only a single node ever ACKs, so quorum is never reached and the
+	// completion does not resolve within MaxCompletionDuration.
+	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[0].Name}, typeURL, "")
+	require.Condition(t, isNotCompletedComparison(comp))
+}
+
 func TestUpsertMoreRecentVersion(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -279,6 +407,41 @@ func TestUpsertMoreRecentVersionNack(t *testing.T) {
 	require.EqualValues(t, &ProxyError{Err: ErrNackReceived, Detail: "Detail"}, comp.Err())
 }
 
+// TestHandleResourceVersionAckOutOfOrder verifies that a pending completion
+// is resolved once the node's stored max acked version covers it, even if a
+// later message carries a lower, stale nonce (ACKs observed out of order
+// under load), rather than being left pending forever waiting for a message
+// whose nonce happens to match it exactly.
+func TestHandleResourceVersionAckOutOfOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	// Empty cache is the version 1
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2 with resource 0.
+	callback, comp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	// A later message for an unrelated resource carries a higher nonce,
+	// advancing the node's stored max acked version past the pending
+	// completion's version without satisfying it directly.
+	acker.HandleResourceVersionAck(3, 3, node0, []string{"unrelated"}, typeURL, "")
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.Equal(t, uint64(3), acker.ackedVersions[node0])
+
+	// A stale message for the pending completion's own resource then
+	// arrives out of order, carrying a nonce lower than the version already
+	// recorded for the node. It must still resolve the completion, since
+	// the node has already moved past this version.
+	acker.HandleResourceVersionAck(1, 1, node0, []string{resources[0].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+}
+
 func TestDeleteSingleNode(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -347,6 +510,75 @@ func TestDeleteMultipleNodes(t *testing.T) {
 	require.Condition(t, completedComparison(comp))
 }
 
+func TestDeleteNodeResolvesExclusivePendingCompletion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2, waited on only by node0.
+	callback, comp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.Len(t, acker.pendingCompletions, 1)
+
+	// node0 disconnects before ACKing. The completion is the only one
+	// waiting on it, so it must be resolved right away with ErrNodeGone,
+	// rather than left to time out.
+	acker.DeleteNode(node0)
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.ErrorIs(t, comp.Err(), ErrNodeGone)
+	require.Empty(t, acker.pendingCompletions)
+}
+
+func TestDeleteNodeDropsNodeFromSharedPendingCompletion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2, waited on by both node0 and node1.
+	callback, comp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0, node1}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.Len(t, acker.pendingCompletions, 1)
+
+	// node0 disconnects before ACKing. The completion is still shared with
+	// node1, so it must stay pending rather than being resolved.
+	acker.DeleteNode(node0)
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.Len(t, acker.pendingCompletions, 1)
+
+	// node1 ACKs, which now completes the waitgroup since node0 was dropped.
+	acker.HandleResourceVersionAck(2, 2, node1, []string{resources[0].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+}
+
+func TestDeleteAllForNodeResolvesExclusivePendingCompletion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	callback, comp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	acker.DeleteAllForNode(node0)
+	require.Condition(t, isNotCompletedComparison(comp))
+	require.ErrorIs(t, comp.Err(), ErrNodeGone)
+	require.Empty(t, acker.pendingCompletions)
+}
+
 func TestRevertInsert(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -475,3 +707,148 @@ func TestRevertDelete(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, resources[2], res)
 }
+
+func TestSetSnapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	// Empty cache is the version 1
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// Create version 2 with resources 0 and 1.
+	callback, comp := newCompCallback()
+	acker.SetSnapshot(typeURL, map[string]proto.Message{
+		resources[0].Name: resources[0],
+		resources[1].Name: resources[1],
+	}, []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	// Ack the right version, for another resource, from the right node: as
+	// with Delete, the resource name is ignored and only the version
+	// matters.
+	acker.HandleResourceVersionAck(2, 2, node0, []string{resources[2].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+
+	// Replace the snapshot with just resource 1, dropping resource 0.
+	callback, comp = newCompCallback()
+	revert := acker.SetSnapshot(typeURL, map[string]proto.Message{
+		resources[1].Name: resources[1],
+	}, []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+
+	res, err := cache.Lookup(typeURL, resources[0].Name)
+	require.NoError(t, err)
+	require.Nil(t, res)
+	res, err = cache.Lookup(typeURL, resources[1].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[1], res)
+
+	acker.HandleResourceVersionAck(3, 3, node0, []string{resources[1].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+
+	// Revert restores resource 0.
+	revertComp := wg.AddCompletion()
+	defer revertComp.Complete(nil)
+	revert(revertComp)
+
+	res, err = cache.Lookup(typeURL, resources[0].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[0], res)
+	res, err = cache.Lookup(typeURL, resources[1].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[1], res)
+}
+
+func TestAckingResourceMutatorWrapperExportImportState(t *testing.T) {
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	acker.locker.Lock()
+	acker.version = 7
+	acker.ackedVersions[node0] = 5
+	acker.ackedVersions[node1] = 7
+	acker.locker.Unlock()
+
+	data, err := acker.ExportState()
+	require.NoError(t, err)
+
+	restored := NewAckingResourceMutatorWrapper(NewCache())
+	require.NoError(t, restored.ImportState(data))
+
+	require.Equal(t, acker.version, restored.version)
+	require.Equal(t, acker.ackedVersions, restored.ackedVersions)
+}
+
+func TestAckingResourceMutatorWrapperImportStateInvalidJSON(t *testing.T) {
+	acker := NewAckingResourceMutatorWrapper(NewCache())
+	require.Error(t, acker.ImportState([]byte("not json")))
+}
+
+func TestGetResources(t *testing.T) {
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	// An unknown typeURL has no resources, but is not an error.
+	got, err := acker.GetResources(typeURL)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	acker.Upsert(typeURL, resources[0].Name, resources[0], nil, nil, nil)
+	acker.Upsert(typeURL, resources[1].Name, resources[1], nil, nil, nil)
+
+	got, err = acker.GetResources(typeURL)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.True(t, proto.Equal(resources[0], got[resources[0].Name]))
+	require.True(t, proto.Equal(resources[1], got[resources[1].Name]))
+
+	// The returned map must be a copy: mutating a returned resource must not
+	// affect the cache's own copy.
+	got[resources[0].Name].(*envoy_config_route.RouteConfiguration).Name = "mutated"
+	stillCached, err := cache.GetResources(typeURL, 0, "", []string{resources[0].Name})
+	require.NoError(t, err)
+	require.Equal(t, resources[0].Name, stillCached.Resources[0].(*envoy_config_route.RouteConfiguration).Name)
+}
+
+// TestReset verifies that Reset resolves any pending completion with
+// ErrReset, clears the tracked acked versions and version, and clears the
+// wrapped mutator's resources, while remaining safe to call with an
+// operation in flight.
+func TestReset(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+	wg := completion.NewWaitGroup(ctx)
+
+	cache := NewCache()
+	acker := NewAckingResourceMutatorWrapper(cache)
+
+	callback, comp := newCompCallback()
+	acker.Upsert(typeURL, resources[0].Name, resources[0], []string{node0}, wg, callback)
+	acker.HandleResourceVersionAck(2, 2, node1, []string{resources[0].Name}, typeURL, "")
+	require.Len(t, acker.ackedVersions, 1)
+	require.Condition(t, isNotCompletedComparison(comp), "completion must still be pending before Reset")
+
+	acker.Reset(typeURL)
+
+	require.Condition(t, isNotCompletedComparison(comp), "pending completion must resolve on Reset")
+	require.ErrorIs(t, comp.Err(), ErrReset)
+	require.Empty(t, acker.ackedVersions)
+	require.Empty(t, acker.pendingCompletions)
+	require.Equal(t, uint64(0), acker.version)
+
+	res, err := cache.Lookup(typeURL, resources[0].Name)
+	require.NoError(t, err)
+	require.Nil(t, res, "wrapped mutator's resources must be cleared by Reset")
+
+	// The wrapper must remain usable after Reset.
+	callback, comp = newCompCallback()
+	acker.Upsert(typeURL, resources[1].Name, resources[1], []string{node0}, wg, callback)
+	require.Condition(t, isNotCompletedComparison(comp))
+	acker.HandleResourceVersionAck(acker.version, acker.version, node0, []string{resources[1].Name}, typeURL, "")
+	require.Condition(t, completedComparison(comp))
+}
@@ -67,6 +67,11 @@ func (c *Cache) tx(typeURL string, upsertedResources map[string]proto.Message, d
 	c.locker.Lock()
 	defer c.locker.Unlock()
 
+	return c.txLocked(typeURL, upsertedResources, deletedNames)
+}
+
+// txLocked does the work of tx, assuming c.locker is already held.
+func (c *Cache) txLocked(typeURL string, upsertedResources map[string]proto.Message, deletedNames []string) (version uint64, updated bool, revert ResourceMutatorRevertFunc) {
 	cacheIsUpdated := false
 	newVersion := c.version + 1
 
@@ -159,6 +164,30 @@ func (c *Cache) Delete(typeURL string, resourceName string) (version uint64, upd
 	return c.tx(typeURL, nil, []string{resourceName})
 }
 
+// SetSnapshot atomically replaces the entire set of resources of typeURL
+// with resources: every name in resources is upserted, and every name
+// currently cached for typeURL but absent from resources is deleted. As
+// with tx, at most one version increment occurs for the whole operation,
+// and the returned revert function restores the prior set. This is used
+// for State-of-the-World xDS, where each push carries a node's complete
+// desired resource set for a typeURL rather than an incremental diff.
+func (c *Cache) SetSnapshot(typeURL string, resources map[string]proto.Message) (version uint64, updated bool, revert ResourceMutatorRevertFunc) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	var deletedNames []string
+	for k := range c.resources {
+		if k.typeURL != typeURL {
+			continue
+		}
+		if _, keep := resources[k.resourceName]; !keep {
+			deletedNames = append(deletedNames, k.resourceName)
+		}
+	}
+
+	return c.txLocked(typeURL, resources, deletedNames)
+}
+
 func (c *Cache) Clear(typeURL string) (version uint64, updated bool) {
 	c.locker.Lock()
 	defer c.locker.Unlock()
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package xds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCacheSetSnapshot(t *testing.T) {
+	typeURL := "type.googleapis.com/envoy.config.v3.DummyConfiguration"
+
+	// Empty cache is the version 1
+	cache := NewCache()
+
+	// Seed the cache with resources 0 and 1.
+	version, updated, _ := cache.SetSnapshot(typeURL, map[string]proto.Message{
+		resources[0].Name: resources[0],
+		resources[1].Name: resources[1],
+	})
+	require.True(t, updated)
+	require.Equal(t, uint64(2), version)
+
+	res, err := cache.Lookup(typeURL, resources[0].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[0], res)
+	res, err = cache.Lookup(typeURL, resources[1].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[1], res)
+
+	// Replace the snapshot: drop resource 0, keep resource 1 unchanged, add
+	// resource 2. This should delete resource 0, leave resource 1's
+	// lastModifiedVersion untouched, insert resource 2, and bump the
+	// version exactly once.
+	version, updated, revert := cache.SetSnapshot(typeURL, map[string]proto.Message{
+		resources[1].Name: resources[1],
+		resources[2].Name: resources[2],
+	})
+	require.True(t, updated)
+	require.Equal(t, uint64(3), version)
+
+	res, err = cache.Lookup(typeURL, resources[0].Name)
+	require.NoError(t, err)
+	require.Nil(t, res)
+	res, err = cache.Lookup(typeURL, resources[1].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[1], res)
+	res, err = cache.Lookup(typeURL, resources[2].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[2], res)
+
+	// Setting the exact same snapshot again is a no-op: nothing changed, so
+	// no version bump.
+	version, updated, _ = cache.SetSnapshot(typeURL, map[string]proto.Message{
+		resources[1].Name: resources[1],
+		resources[2].Name: resources[2],
+	})
+	require.False(t, updated)
+	require.Equal(t, uint64(3), version)
+
+	// Revert restores the prior full resource set (resources 0 and 1, no
+	// resource 2), as a single further version bump.
+	version, updated = revert()
+	require.True(t, updated)
+	require.Equal(t, uint64(4), version)
+
+	res, err = cache.Lookup(typeURL, resources[0].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[0], res)
+	res, err = cache.Lookup(typeURL, resources[1].Name)
+	require.NoError(t, err)
+	require.Equal(t, resources[1], res)
+	res, err = cache.Lookup(typeURL, resources[2].Name)
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
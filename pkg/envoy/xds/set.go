@@ -86,6 +86,16 @@ type ResourceMutator interface {
 	// The returned version value is the set's version after update.
 	// This method call cannot be reverted.
 	Clear(typeURL string) (version uint64, updated bool)
+
+	// SetSnapshot atomically replaces the entire set of resources of
+	// typeURL with resources: every name in resources is upserted, and
+	// every name currently in the set for typeURL but absent from
+	// resources is deleted. At most one version increment occurs for the
+	// whole operation, regardless of how many resources are upserted or
+	// deleted. The returned version value is the set's version after
+	// update. A call to the returned revert function reverts the effects
+	// of this method call.
+	SetSnapshot(typeURL string, resources map[string]proto.Message) (version uint64, updated bool, revert ResourceMutatorRevertFunc)
 }
 
 // ResourceSet provides read-write access to a versioned set of resources.
@@ -41,6 +41,18 @@ func (rm *resourceMock) Store(context.Context) (resource.Store[*ciliumv2.CiliumN
 	return nil, errors.New("unimplemented")
 }
 
+func (rm *resourceMock) Get(ctx context.Context, key resource.Key) (item *ciliumv2.CiliumNode, exists bool, err error) {
+	return nil, false, errors.New("unimplemented")
+}
+
+func (rm *resourceMock) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (rm *resourceMock) HasSynced() bool {
+	return true
+}
+
 var mtuMock = mtu.NewConfiguration(0, false, false, false, false, 1500, nil, false)
 
 func TestAllocatedIPDump(t *testing.T) {
@@ -502,6 +502,18 @@ func (f *fakeK8sCiliumNodeAPIResource) Store(context.Context) (resource.Store[*c
 	return nil, errors.New("unimplemented")
 }
 
+func (f *fakeK8sCiliumNodeAPIResource) Get(ctx context.Context, key resource.Key) (item *ciliumv2.CiliumNode, exists bool, err error) {
+	return nil, false, errors.New("unimplemented")
+}
+
+func (f *fakeK8sCiliumNodeAPIResource) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeK8sCiliumNodeAPIResource) HasSynced() bool {
+	return true
+}
+
 // currentNode returns a the current snapshot of the node
 func (f *fakeK8sCiliumNodeAPIResource) currentNode() *ciliumv2.CiliumNode {
 	f.mutex.Lock()
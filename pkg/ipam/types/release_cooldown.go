@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package types
+
+import (
+	"time"
+
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// DefaultReleaseCooldown is the default duration ReleaseCooldown withholds
+// a released IP from being offered again, used when NewReleaseCooldown is
+// called with a zero duration.
+const DefaultReleaseCooldown = 30 * time.Second
+
+// ReleaseCooldown is a small time-bounded set of recently released IPs.
+//
+// The IP release handshake tracked by IPAMStatus.ReleaseIPs/ReleaseIPv6s can
+// race: the operator may mark an IP as "released" and the agent may offer
+// that same IP for a new allocation before the operator's removal of the IP
+// from the pool has propagated back to the agent. Recording a released IP
+// here for a short cooldown window lets allocation code consult
+// ShouldCooldown and skip such an IP until the race has had time to settle.
+//
+// +k8s:deepcopy-gen=false
+// +deepequal-gen=false
+type ReleaseCooldown struct {
+	mutex    lock.Mutex
+	cooldown time.Duration
+	released map[string]time.Time
+}
+
+// NewReleaseCooldown returns a new ReleaseCooldown that withholds a
+// released IP for the given duration. A zero duration uses
+// DefaultReleaseCooldown.
+func NewReleaseCooldown(cooldown time.Duration) *ReleaseCooldown {
+	if cooldown <= 0 {
+		cooldown = DefaultReleaseCooldown
+	}
+	return &ReleaseCooldown{
+		cooldown: cooldown,
+		released: map[string]time.Time{},
+	}
+}
+
+// MarkReleased records ip as released as of now, so that ShouldCooldown
+// returns true for it until the cooldown window has elapsed.
+func (r *ReleaseCooldown) MarkReleased(ip string, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.released[ip] = now
+	r.evictExpiredLocked(now)
+}
+
+// ShouldCooldown returns true if ip was marked released within the
+// cooldown window as of now, i.e. it should not yet be considered for
+// (re)allocation.
+func (r *ReleaseCooldown) ShouldCooldown(ip string, now time.Time) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	releasedAt, ok := r.released[ip]
+	if !ok {
+		return false
+	}
+	if now.Sub(releasedAt) >= r.cooldown {
+		delete(r.released, ip)
+		return false
+	}
+	return true
+}
+
+// evictExpiredLocked removes every entry whose cooldown window has already
+// elapsed, bounding the set to IPs released within the last cooldown
+// window. Must be called with r.mutex held.
+func (r *ReleaseCooldown) evictExpiredLocked(now time.Time) {
+	for ip, releasedAt := range r.released {
+		if now.Sub(releasedAt) >= r.cooldown {
+			delete(r.released, ip)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseCooldown(t *testing.T) {
+	now := time.Now()
+	rc := NewReleaseCooldown(time.Minute)
+
+	require.False(t, rc.ShouldCooldown("10.0.0.1", now), "an IP that was never released should not be in cooldown")
+
+	// Simulate the release-then-quick-realloc race: the operator marks the
+	// IP released, and an allocation attempt for it shortly after should be
+	// blocked by the cooldown.
+	rc.MarkReleased("10.0.0.1", now)
+	require.True(t, rc.ShouldCooldown("10.0.0.1", now.Add(5*time.Second)))
+	require.True(t, rc.ShouldCooldown("10.0.0.1", now.Add(59*time.Second)))
+
+	// Once the cooldown window has elapsed, the IP is eligible again.
+	require.False(t, rc.ShouldCooldown("10.0.0.1", now.Add(time.Minute)))
+
+	// And having expired, it must not still show up on a subsequent check.
+	require.False(t, rc.ShouldCooldown("10.0.0.1", now.Add(2*time.Minute)))
+}
+
+func TestReleaseCooldownZeroDurationUsesDefault(t *testing.T) {
+	rc := NewReleaseCooldown(0)
+	require.Equal(t, DefaultReleaseCooldown, rc.cooldown)
+}
+
+func TestReleaseCooldownIndependentIPs(t *testing.T) {
+	now := time.Now()
+	rc := NewReleaseCooldown(time.Minute)
+
+	rc.MarkReleased("10.0.0.1", now)
+	require.True(t, rc.ShouldCooldown("10.0.0.1", now))
+	require.False(t, rc.ShouldCooldown("10.0.0.2", now), "marking one IP released must not affect another")
+}
+
+func TestReleaseCooldownEvictsExpiredEntries(t *testing.T) {
+	now := time.Now()
+	rc := NewReleaseCooldown(time.Minute)
+
+	rc.MarkReleased("10.0.0.1", now)
+
+	// By the time 10.0.0.2 is released, 10.0.0.1's cooldown has already
+	// elapsed, so it is evicted rather than kept around indefinitely.
+	rc.MarkReleased("10.0.0.2", now.Add(2*time.Minute))
+	require.Len(t, rc.released, 1)
+	require.NotContains(t, rc.released, "10.0.0.1")
+
+	// A release that is still within its cooldown window is kept.
+	rc.MarkReleased("10.0.0.3", now.Add(2*time.Minute).Add(time.Second))
+	require.Len(t, rc.released, 2)
+	require.Contains(t, rc.released, "10.0.0.2")
+	require.Contains(t, rc.released, "10.0.0.3")
+}
@@ -4,10 +4,16 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
+	"time"
+
+	"k8s.io/utils/clock"
 
 	"github.com/cilium/cilium/pkg/cidr"
+	ipamOption "github.com/cilium/cilium/pkg/ipam/option"
 	"github.com/cilium/cilium/pkg/lock"
 )
 
@@ -26,6 +32,32 @@ type Limits struct {
 	// HypervisorType tracks the instance's hypervisor type if available. Used to determine if features like prefix
 	// delegation are supported on an instance. Bare metal instances would have empty string.
 	HypervisorType string
+
+	// ReservedIPv4 is the number of IPv4 addresses per adapter/interface that
+	// are not available for allocation, e.g. the primary IP or an IP
+	// reserved by the cloud provider.
+	ReservedIPv4 int
+
+	// ReservedIPv6 is the number of IPv6 addresses per adapter/interface that
+	// are not available for allocation, e.g. the primary IP or an IP
+	// reserved by the cloud provider.
+	ReservedIPv6 int
+}
+
+// UsableIPv4PerAdapter returns the number of IPv4 addresses per
+// adapter/interface that are actually available for allocation, i.e. IPv4
+// minus ReservedIPv4, floored at 0 so that a reservation exceeding the
+// adapter's capacity never yields a negative count.
+func (l Limits) UsableIPv4PerAdapter() int {
+	return max(l.IPv4-l.ReservedIPv4, 0)
+}
+
+// UsableIPv6PerAdapter returns the number of IPv6 addresses per
+// adapter/interface that are actually available for allocation, i.e. IPv6
+// minus ReservedIPv6, floored at 0 so that a reservation exceeding the
+// adapter's capacity never yields a negative count.
+func (l Limits) UsableIPv6PerAdapter() int {
+	return max(l.IPv6-l.ReservedIPv6, 0)
 }
 
 // AllocationIP is an IP which is available for allocation, or already
@@ -52,6 +84,27 @@ type AllocationIP struct {
 // AllocationMap is a map of allocated IPs indexed by IP
 type AllocationMap map[string]AllocationIP
 
+// ValidateUsed asserts that m, interpreted as a Status.IPAM.Used (or
+// Status.IPAM.IPv6Used) map, is internally consistent: every entry must
+// record both the Owner that holds the IP and the Resource it was allocated
+// from, since an entry with either left blank means the IP was allocated
+// without properly being recorded as held.
+//
+// It returns a joined error naming every offending IP, or nil if m is
+// consistent.
+func (m AllocationMap) ValidateUsed() error {
+	var err error
+	for ip, alloc := range m {
+		if alloc.Owner == "" {
+			err = errors.Join(err, fmt.Errorf("used IP %s has no owner", ip))
+		}
+		if alloc.Resource == "" {
+			err = errors.Join(err, fmt.Errorf("used IP %s has no resource", ip))
+		}
+	}
+	return err
+}
+
 // IPAMPodCIDR is a pod CIDR
 //
 // +kubebuilder:validation:Format=cidr
@@ -115,6 +168,90 @@ type IPAMPoolSpec struct {
 	Allocated []IPAMPoolAllocation `json:"allocated,omitempty"`
 }
 
+// Diff computes the delta between this IPAMPoolSpec and other, indexed by pool
+// name. It returns the CIDRs that are present in other but not in this spec
+// (added), the CIDRs that are present in this spec but not in other
+// (removed), and the IPAMPoolRequest entries whose Needed demand differs
+// between the two (changedRequests, carrying the values from other).
+//
+// Pools are compared independently of ordering: only the set of CIDRs within
+// a pool matters, not the order in which they appear in Allocated.
+func (s IPAMPoolSpec) Diff(other IPAMPoolSpec) (added, removed []IPAMPoolAllocation, changedRequests []IPAMPoolRequest) {
+	thisCIDRs := poolCIDRSets(s.Allocated)
+	otherCIDRs := poolCIDRSets(other.Allocated)
+
+	for pool, otherSet := range otherCIDRs {
+		thisSet := thisCIDRs[pool]
+		if a := cidrSetDiff(otherSet, thisSet); len(a) > 0 {
+			added = append(added, IPAMPoolAllocation{Pool: pool, CIDRs: a})
+		}
+	}
+	for pool, thisSet := range thisCIDRs {
+		otherSet := otherCIDRs[pool]
+		if r := cidrSetDiff(thisSet, otherSet); len(r) > 0 {
+			removed = append(removed, IPAMPoolAllocation{Pool: pool, CIDRs: r})
+		}
+	}
+
+	thisNeeded := poolDemands(s.Requested)
+	otherNeeded := poolDemands(other.Requested)
+	seenPools := make(map[string]struct{}, len(thisNeeded)+len(otherNeeded))
+	for pool, otherDemand := range otherNeeded {
+		seenPools[pool] = struct{}{}
+		if thisNeeded[pool] != otherDemand {
+			changedRequests = append(changedRequests, IPAMPoolRequest{Pool: pool, Needed: otherDemand})
+		}
+	}
+	for pool, thisDemand := range thisNeeded {
+		if _, ok := seenPools[pool]; ok {
+			continue
+		}
+		if otherDemand, ok := otherNeeded[pool]; ok && otherDemand == thisDemand {
+			continue
+		}
+		changedRequests = append(changedRequests, IPAMPoolRequest{Pool: pool, Needed: otherNeeded[pool]})
+	}
+
+	return added, removed, changedRequests
+}
+
+// poolCIDRSets indexes a list of IPAMPoolAllocation by pool name, with the
+// CIDRs of each pool represented as a set for order-insensitive comparison.
+func poolCIDRSets(allocations []IPAMPoolAllocation) map[string]map[IPAMPodCIDR]struct{} {
+	sets := make(map[string]map[IPAMPodCIDR]struct{}, len(allocations))
+	for _, alloc := range allocations {
+		set, ok := sets[alloc.Pool]
+		if !ok {
+			set = make(map[IPAMPodCIDR]struct{}, len(alloc.CIDRs))
+			sets[alloc.Pool] = set
+		}
+		for _, c := range alloc.CIDRs {
+			set[c] = struct{}{}
+		}
+	}
+	return sets
+}
+
+// cidrSetDiff returns the CIDRs present in 'a' but not in 'b'.
+func cidrSetDiff(a, b map[IPAMPodCIDR]struct{}) []IPAMPodCIDR {
+	var diff []IPAMPodCIDR
+	for c := range a {
+		if _, ok := b[c]; !ok {
+			diff = append(diff, c)
+		}
+	}
+	return diff
+}
+
+// poolDemands indexes a list of IPAMPoolRequest by pool name.
+func poolDemands(requests []IPAMPoolRequest) map[string]IPAMPoolDemand {
+	demands := make(map[string]IPAMPoolDemand, len(requests))
+	for _, req := range requests {
+		demands[req.Pool] = req.Needed
+	}
+	return demands
+}
+
 // IPAMSpec is the IPAM specification of the node
 //
 // This structure is embedded into v2.CiliumNode
@@ -180,6 +317,36 @@ type IPAMSpec struct {
 	MaxAboveWatermark int `json:"max-above-watermark,omitempty"`
 }
 
+// Validate checks that the watermark fields of the IPAMSpec are internally
+// consistent, e.g. that MinAllocate does not exceed MaxAllocate. It is
+// intended to be called by the operator before acting on a spec, since the
+// kubebuilder minimums on these fields only enforce non-negativity.
+func (s *IPAMSpec) Validate() error {
+	if s.MinAllocate < 0 {
+		return fmt.Errorf("min-allocate must not be negative: %d", s.MinAllocate)
+	}
+	if s.MaxAllocate < 0 {
+		return fmt.Errorf("max-allocate must not be negative: %d", s.MaxAllocate)
+	}
+	if s.PreAllocate < 0 {
+		return fmt.Errorf("pre-allocate must not be negative: %d", s.PreAllocate)
+	}
+	if s.MaxAboveWatermark < 0 {
+		return fmt.Errorf("max-above-watermark must not be negative: %d", s.MaxAboveWatermark)
+	}
+
+	if s.MaxAllocate > 0 {
+		if s.MinAllocate > s.MaxAllocate {
+			return fmt.Errorf("min-allocate (%d) must not exceed max-allocate (%d)", s.MinAllocate, s.MaxAllocate)
+		}
+		if s.PreAllocate > s.MaxAllocate {
+			return fmt.Errorf("pre-allocate (%d) must not exceed max-allocate (%d)", s.PreAllocate, s.MaxAllocate)
+		}
+	}
+
+	return nil
+}
+
 // IPReleaseStatus defines the valid states in IP release handshake
 //
 // +kubebuilder:validation:Enum=marked-for-release;ready-for-release;do-not-release;released
@@ -232,6 +399,60 @@ type IPAMStatus struct {
 	ReleaseIPv6s map[string]IPReleaseStatus `json:"release-ipv6s,omitempty"`
 }
 
+// IPFamily identifies the IP address family a batch of IPs to release
+// belongs to, selecting whether IPAMStatus.MarkForRelease and
+// IPAMStatus.CollectReadyForRelease operate on ReleaseIPs or ReleaseIPv6s.
+type IPFamily string
+
+const (
+	IPv4Family IPFamily = "ipv4"
+	IPv6Family IPFamily = "ipv6"
+)
+
+// releaseMap returns a pointer to the release-tracking map for family,
+// lazily allocating it if necessary.
+func (s *IPAMStatus) releaseMap(family IPFamily) *map[string]IPReleaseStatus {
+	if family == IPv6Family {
+		return &s.ReleaseIPv6s
+	}
+	return &s.ReleaseIPs
+}
+
+// MarkForRelease marks ips as candidates for release in the release map for
+// the given family, batching what would otherwise be a sequence of
+// individual state transitions. IPs already in the ready-for-release,
+// do-not-release, or released state are left untouched: the first two
+// because the agent has already responded to the handshake, the last
+// because the operator has already completed it for that IP.
+func (s *IPAMStatus) MarkForRelease(ips []string, family IPFamily) {
+	m := s.releaseMap(family)
+	if *m == nil {
+		*m = make(map[string]IPReleaseStatus, len(ips))
+	}
+	for _, ip := range ips {
+		switch (*m)[ip] {
+		case ipamOption.IPAMReadyForRelease, ipamOption.IPAMDoNotRelease, ipamOption.IPAMReleased:
+			continue
+		}
+		(*m)[ip] = ipamOption.IPAMMarkForRelease
+	}
+}
+
+// CollectReadyForRelease returns the IPs of the given family that the agent
+// has acknowledged as safe to release, i.e. those in the ready-for-release
+// state. IPs in any other state, including do-not-release, are skipped.
+func (s *IPAMStatus) CollectReadyForRelease(family IPFamily) []string {
+	m := s.releaseMap(family)
+
+	var ips []string
+	for ip, status := range *m {
+		if status == ipamOption.IPAMReadyForRelease {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
 // IPAMPoolRequest is a request from the agent to the operator, indicating how
 // may IPs it requires from a given pool
 type IPAMPoolDemand struct {
@@ -321,6 +542,32 @@ type Subnet struct {
 	Tags Tags
 }
 
+// HasCapacityForPrefix reports whether the subnet has enough free addresses,
+// of the requested family, to hand out one contiguous prefix of length
+// prefixLen. This is used for prefix delegation (e.g. AWS ENI /28 IPv4
+// prefixes), where a single allocation hands out 2^(32-prefixLen) (or
+// 2^(128-prefixLen) for IPv6) addresses at once rather than one at a time.
+// It returns false for a prefix length that is negative, wider than the
+// address family, or otherwise too large to fit in the available address
+// count.
+func (s *Subnet) HasCapacityForPrefix(prefixLen int, ipv6 bool) bool {
+	totalBits := 32
+	available := s.AvailableAddresses
+	if ipv6 {
+		totalBits = 128
+		available = s.AvailableIPv6Addresses
+	}
+
+	hostBits := totalBits - prefixLen
+	if hostBits < 0 || hostBits > 62 {
+		// hostBits > 62 can never be satisfied by an int-sized address
+		// count, and shifting by that much would overflow anyway.
+		return false
+	}
+
+	return available >= 1<<hostBits
+}
+
 // SubnetMap indexes subnets by subnet ID
 type SubnetMap map[string]*Subnet
 
@@ -345,6 +592,52 @@ func (m SubnetMap) FirstSubnetWithAvailableAddresses(preferredPoolIDs []PoolID)
 	return PoolNotExists, 0
 }
 
+// FirstSubnetWithAvailableAddressesInZone is like
+// FirstSubnetWithAvailableAddresses, but restricts selection to subnets
+// whose AvailabilityZone matches zone. If zone is empty, it falls back to
+// the same behavior as FirstSubnetWithAvailableAddresses, considering
+// subnets in any availability zone.
+func (m SubnetMap) FirstSubnetWithAvailableAddressesInZone(zone string, preferredPoolIDs []PoolID) (PoolID, int) {
+	if zone == "" {
+		return m.FirstSubnetWithAvailableAddresses(preferredPoolIDs)
+	}
+
+	for _, p := range preferredPoolIDs {
+		if s := m[string(p)]; s != nil {
+			if s.AvailabilityZone == zone && s.AvailableAddresses > 0 {
+				return p, s.AvailableAddresses
+			}
+		}
+	}
+
+	for poolID, s := range m {
+		if s.AvailabilityZone == zone && s.AvailableAddresses > 0 {
+			return PoolID(poolID), s.AvailableAddresses
+		}
+	}
+
+	return PoolNotExists, 0
+}
+
+// MatchingSubnets returns all subnets in the map whose Tags satisfy
+// required, as determined by Tags.Match, sorted by descending available
+// IPv4 address count so that allocators are handed the most promising
+// candidate first. A nil required matches every subnet in the map.
+func (m SubnetMap) MatchingSubnets(required Tags) []*Subnet {
+	matching := make([]*Subnet, 0, len(m))
+	for _, s := range m {
+		if s.Tags.Match(required) {
+			matching = append(matching, s)
+		}
+	}
+
+	slices.SortFunc(matching, func(a, b *Subnet) int {
+		return b.AvailableAddresses - a.AvailableAddresses
+	})
+
+	return matching
+}
+
 // VirtualNetwork is the representation of a virtual network
 type VirtualNetwork struct {
 	// ID is the ID of the virtual network
@@ -363,6 +656,47 @@ type VirtualNetwork struct {
 // VirtualNetworkMap indexes virtual networks by their ID
 type VirtualNetworkMap map[string]*VirtualNetwork
 
+// RouteTable is the representation of a cloud provider route table,
+// tracking which subnets are associated with it.
+type RouteTable struct {
+	// ID is the ID of the route table
+	ID string
+
+	// Subnets is the set of subnet IDs associated with this route table
+	Subnets map[string]struct{}
+}
+
+// RouteTableMap indexes route tables by their ID
+type RouteTableMap map[string]*RouteTable
+
+// SameRouteTable returns whether subnetA and subnetB are associated with
+// the same route table. It returns an error if either subnet is not found
+// in any of the route tables in the map.
+func (m RouteTableMap) SameRouteTable(subnetA, subnetB string) (bool, error) {
+	tableA, err := m.routeTableForSubnet(subnetA)
+	if err != nil {
+		return false, err
+	}
+
+	tableB, err := m.routeTableForSubnet(subnetB)
+	if err != nil {
+		return false, err
+	}
+
+	return tableA.ID == tableB.ID, nil
+}
+
+// routeTableForSubnet scans the map for the route table containing
+// subnetID, returning an error if none is found.
+func (m RouteTableMap) routeTableForSubnet(subnetID string) (*RouteTable, error) {
+	for _, table := range m {
+		if _, ok := table.Subnets[subnetID]; ok {
+			return table, nil
+		}
+	}
+	return nil, fmt.Errorf("subnet %s not found in any route table", subnetID)
+}
+
 // PoolNotExists indicate that no such pool ID exists
 const PoolNotExists = PoolID("")
 
@@ -387,6 +721,25 @@ type PoolQuota struct {
 // PoolQuotaMap is a map of pool quotas indexes by pool identifier
 type PoolQuotaMap map[PoolID]PoolQuota
 
+// Summary aggregates the available IP capacity across all pools in the map,
+// both as a cluster-wide total and broken down by AvailabilityZone.
+func (m PoolQuotaMap) Summary() (totalAvailableIPv4, totalAvailableIPv6 int, perZone map[string]PoolQuota) {
+	perZone = map[string]PoolQuota{}
+
+	for _, q := range m {
+		totalAvailableIPv4 += q.AvailableIPs
+		totalAvailableIPv6 += q.AvailableIPv6s
+
+		zone := perZone[q.AvailabilityZone]
+		zone.AvailabilityZone = q.AvailabilityZone
+		zone.AvailableIPs += q.AvailableIPs
+		zone.AvailableIPv6s += q.AvailableIPv6s
+		perZone[q.AvailabilityZone] = zone
+	}
+
+	return totalAvailableIPv4, totalAvailableIPv6, perZone
+}
+
 // Interface is the implementation of a IPAM relevant network interface
 // +k8s:deepcopy-gen=false
 // +deepequal-gen=false
@@ -394,6 +747,13 @@ type Interface interface {
 	// InterfaceID must return the identifier of the interface
 	InterfaceID() string
 
+	// InterfaceType must return a short, stable identifier for the
+	// concrete backend implementing Interface, e.g. option.IPAMENI or
+	// option.IPAMAzure. This lets callers iterating over interfaces from
+	// multiple IPAM backends (e.g. via ForeachAddressWithType) tell them
+	// apart without type-asserting the concrete type.
+	InterfaceType() string
+
 	// ForeachAddress must iterate over all addresses of the interface and
 	// call fn for each address
 	ForeachAddress(instanceID string, fn AddressIterator) error
@@ -417,6 +777,12 @@ type InterfaceRevision struct {
 	// function calculated off the resource. This field is optional, not
 	// all IPAM backends make use of fingerprints.
 	Fingerprint string
+
+	// LastSeen is the time at which this revision of the interface was last
+	// reported via InstanceMap.Update. It lets consumers of ForeachInterface
+	// (e.g. ForeachStaleInterface) identify interfaces that have dropped out
+	// of the cloud provider's inventory without being explicitly removed.
+	LastSeen time.Time
 }
 
 // Instance is the representation of an instance, typically a VM, subject to
@@ -437,11 +803,20 @@ type Instance struct {
 type InstanceMap struct {
 	mutex lock.RWMutex
 	data  map[string]*Instance
+	clock clock.PassiveClock
 }
 
 // NewInstanceMap returns a new InstanceMap
 func NewInstanceMap() *InstanceMap {
-	return &InstanceMap{data: map[string]*Instance{}}
+	return &InstanceMap{data: map[string]*Instance{}, clock: clock.RealClock{}}
+}
+
+// NewInstanceMapWithClock returns a new InstanceMap that uses c to stamp the
+// LastSeen time of interfaces recorded via Update. Tests can use this with a
+// fake clock (e.g. k8s.io/utils/clock/testing) to deterministically control
+// what ForeachStaleInterface considers stale.
+func NewInstanceMapWithClock(c clock.PassiveClock) *InstanceMap {
+	return &InstanceMap{data: map[string]*Instance{}, clock: c}
 }
 
 // UpdateInstance updates the interfaces map for a particular instance.
@@ -460,6 +835,29 @@ func (m *InstanceMap) Update(instanceID string, iface InterfaceRevision) {
 	m.mutex.Unlock()
 }
 
+// UpdateMany updates multiple instances at once, taking the write lock only
+// once instead of once per instance. It is equivalent to calling
+// UpdateInstance for every entry in instances, but avoids the lock/unlock
+// churn of doing so when syncing a large inventory.
+func (m *InstanceMap) UpdateMany(instances map[string]*Instance) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for instanceID, instance := range instances {
+		m.data[instanceID] = instance
+	}
+}
+
+// ReplaceAll replaces the entire set of instances known to the InstanceMap
+// with instances. The replacement is atomic: a concurrent reader observes
+// either the full previous state or the full new state, never a mix of the
+// two.
+func (m *InstanceMap) ReplaceAll(instances map[string]*Instance) {
+	m.mutex.Lock()
+	m.data = instances
+	m.mutex.Unlock()
+}
+
 func (m *InstanceMap) updateLocked(instanceID string, iface InterfaceRevision) {
 	if iface.Resource == nil {
 		return
@@ -475,6 +873,7 @@ func (m *InstanceMap) updateLocked(instanceID string, iface InterfaceRevision) {
 		i.Interfaces = map[string]InterfaceRevision{}
 	}
 
+	iface.LastSeen = m.clock.Now()
 	i.Interfaces[iface.Resource.InterfaceID()] = iface
 }
 
@@ -483,6 +882,12 @@ type Address interface{}
 // AddressIterator is the function called by the ForeachAddress iterator
 type AddressIterator func(instanceID, interfaceID, ip, poolID string, address Address) error
 
+// AddressIteratorWithType is the function called by the
+// ForeachAddressWithType iterator. It is like AddressIterator but also
+// receives the interfaceType reported by the owning Interface's
+// InterfaceType() method.
+type AddressIteratorWithType func(instanceID, interfaceID, ip, poolID, interfaceType string, address Address) error
+
 func foreachAddress(instanceID string, instance *Instance, fn AddressIterator) error {
 	for _, rev := range instance.Interfaces {
 		if err := rev.Resource.ForeachAddress(instanceID, fn); err != nil {
@@ -493,6 +898,20 @@ func foreachAddress(instanceID string, instance *Instance, fn AddressIterator) e
 	return nil
 }
 
+func foreachAddressWithType(instanceID string, instance *Instance, fn AddressIteratorWithType) error {
+	for _, rev := range instance.Interfaces {
+		interfaceType := rev.Resource.InterfaceType()
+		err := rev.Resource.ForeachAddress(instanceID, func(instanceID, interfaceID, ip, poolID string, address Address) error {
+			return fn(instanceID, interfaceID, ip, poolID, interfaceType, address)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ForeachAddress calls fn for each address on each interface attached to each
 // instance. If an instanceID is specified, the only the interfaces and
 // addresses of the specified instance are considered.
@@ -521,6 +940,29 @@ func (m *InstanceMap) ForeachAddress(instanceID string, fn AddressIterator) erro
 	return nil
 }
 
+// ForeachAddressWithType is identical to ForeachAddress, except that fn also
+// receives the interface type (as reported by Interface.InterfaceType()) of
+// the interface the address belongs to.
+func (m *InstanceMap) ForeachAddressWithType(instanceID string, fn AddressIteratorWithType) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if instanceID != "" {
+		if instance := m.data[instanceID]; instance != nil {
+			return foreachAddressWithType(instanceID, instance, fn)
+		}
+		return fmt.Errorf("instance does not exist: %q", instanceID)
+	}
+
+	for instanceID, instance := range m.data {
+		if err := foreachAddressWithType(instanceID, instance, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // InterfaceIterator is the function called by the ForeachInterface iterator
 type InterfaceIterator func(instanceID, interfaceID string, iface InterfaceRevision) error
 
@@ -561,6 +1003,28 @@ func (m *InstanceMap) ForeachInterface(instanceID string, fn InterfaceIterator)
 	return nil
 }
 
+// ForeachStaleInterface calls fn for each interface, across all instances,
+// whose LastSeen is older than olderThan. This identifies interfaces that
+// have not been reported via Update recently enough to still be considered
+// attached, e.g. because the underlying cloud provider interface was
+// detached or deleted without the IPAM backend observing it directly. An
+// interface whose LastSeen is the zero time (never stamped by Update, e.g.
+// only ever set via UpdateInstance/UpdateMany/ReplaceAll) is never
+// considered stale.
+//
+// The InstanceMap is read-locked throughout the iteration process, i.e., no
+// updates will occur. However, the interface object given to fn will point
+// to live data and must be deep copied if used outside of the context of
+// the iterator function.
+func (m *InstanceMap) ForeachStaleInterface(olderThan time.Time, fn InterfaceIterator) error {
+	return m.ForeachInterface("", func(instanceID, interfaceID string, rev InterfaceRevision) error {
+		if rev.LastSeen.IsZero() || !rev.LastSeen.Before(olderThan) {
+			return nil
+		}
+		return fn(instanceID, interfaceID, rev)
+	})
+}
+
 // GetInterface returns returns a particular interface of an instance. The
 // boolean indicates whether the interface was found or not.
 func (m *InstanceMap) GetInterface(instanceID, interfaceID string) (InterfaceRevision, bool) {
@@ -596,6 +1060,22 @@ func (m *InstanceMap) NumInstances() (size int) {
 	return
 }
 
+// NumAddresses returns the number of addresses across all interfaces of all
+// instances in the instance map
+func (m *InstanceMap) NumAddresses() (size int) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for instanceID, instance := range m.data {
+		foreachAddress(instanceID, instance, func(instanceID, interfaceID, ip, poolID string, address Address) error {
+			size++
+			return nil
+		})
+	}
+
+	return
+}
+
 // Exists returns whether the instance ID is in the instanceMap
 func (m *InstanceMap) Exists(instanceID string) (exists bool) {
 	m.mutex.RLock()
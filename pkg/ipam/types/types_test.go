@@ -4,10 +4,16 @@
 package types
 
 import (
+	"fmt"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	ipamOption "github.com/cilium/cilium/pkg/ipam/option"
 )
 
 func TestTagsMatch(t *testing.T) {
@@ -18,12 +24,14 @@ func TestTagsMatch(t *testing.T) {
 
 type mockInterface struct {
 	id    string
+	typ   string
 	pools map[string][]net.IP
 }
 
 func (m *mockInterface) DeepCopyInterface() Interface {
 	mc := &mockInterface{
 		id:    m.id,
+		typ:   m.typ,
 		pools: map[string][]net.IP{},
 	}
 	for id, pool := range m.pools {
@@ -42,6 +50,10 @@ func (m *mockInterface) InterfaceID() string {
 	return m.id
 }
 
+func (m *mockInterface) InterfaceType() string {
+	return m.typ
+}
+
 func (m *mockInterface) ForeachAddress(instanceID string, fn AddressIterator) error {
 	for poolID, ips := range m.pools {
 		for _, ip := range ips {
@@ -100,6 +112,51 @@ func TestForeachAddresses(t *testing.T) {
 	require.Equal(t, 2, interfaces)
 }
 
+func TestForeachAddressWithType(t *testing.T) {
+	m := NewInstanceMap()
+	m.Update("i-1", InterfaceRevision{
+		Resource: &mockInterface{
+			id:  "intf0",
+			typ: "typeA",
+			pools: map[string][]net.IP{
+				"s1": {net.ParseIP("1.1.1.1"), net.ParseIP("2.2.2.2")},
+			},
+		},
+	})
+	m.Update("i-2", InterfaceRevision{
+		Resource: &mockInterface{
+			id:  "intf1",
+			typ: "typeB",
+			pools: map[string][]net.IP{
+				"s1": {net.ParseIP("3.3.3.3")},
+			},
+		},
+	})
+
+	// Iterate over all instances and record the reported interface type per
+	// address.
+	types := map[string]string{}
+	err := m.ForeachAddressWithType("", func(instanceID, interfaceID, ip, poolID, interfaceType string, address Address) error {
+		types[ip] = interfaceType
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"1.1.1.1": "typeA",
+		"2.2.2.2": "typeA",
+		"3.3.3.3": "typeB",
+	}, types)
+
+	// Iterate over a single instance
+	types = map[string]string{}
+	err = m.ForeachAddressWithType("i-2", func(instanceID, interfaceID, ip, poolID, interfaceType string, address Address) error {
+		types[ip] = interfaceType
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"3.3.3.3": "typeB"}, types)
+}
+
 func TestGetInterface(t *testing.T) {
 	m := NewInstanceMap()
 	rev := InterfaceRevision{
@@ -121,6 +178,7 @@ func TestGetInterface(t *testing.T) {
 	intf, ok := m.GetInterface("i-1", "intf0")
 	require.Equal(t, true, ok)
 
+	rev.LastSeen = intf.LastSeen // stamped by Update, not set by the caller
 	require.EqualValues(t, rev, intf)
 }
 
@@ -154,6 +212,111 @@ func TestInstanceMapNumInstances(t *testing.T) {
 	require.Equal(t, 2, m.NumInstances())
 }
 
+func TestInstanceMapUpdateMany(t *testing.T) {
+	viaUpdateMany := NewInstanceMap()
+	viaUpdateMany.UpdateMany(map[string]*Instance{
+		"i-1": {Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "a"}}},
+		"i-2": {Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "b"}}},
+	})
+
+	viaUpdateInstance := NewInstanceMap()
+	viaUpdateInstance.UpdateInstance("i-1", &Instance{Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "a"}}})
+	viaUpdateInstance.UpdateInstance("i-2", &Instance{Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "b"}}})
+
+	require.Equal(t, viaUpdateInstance.data, viaUpdateMany.data)
+}
+
+func TestInstanceMapUpdateManyMergesIntoExisting(t *testing.T) {
+	m := NewInstanceMap()
+	m.UpdateInstance("i-1", &Instance{Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "a"}}})
+
+	m.UpdateMany(map[string]*Instance{
+		"i-2": {Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "b"}}},
+	})
+
+	require.Equal(t, 2, m.NumInstances())
+	require.True(t, m.Exists("i-1"))
+	require.True(t, m.Exists("i-2"))
+}
+
+func TestInstanceMapReplaceAllAtomic(t *testing.T) {
+	m := NewInstanceMap()
+	oldInstances := map[string]*Instance{
+		"i-1": {Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "old"}}},
+	}
+	m.ReplaceAll(oldInstances)
+
+	newInstances := map[string]*Instance{
+		"i-2": {Interfaces: map[string]InterfaceRevision{"intf0": {Fingerprint: "new"}}},
+	}
+
+	// A concurrent reader must see either the old or the new set of
+	// instances in its entirety, never a mix of the two (e.g. both "i-1"
+	// and "i-2" present, or neither).
+	var wg sync.WaitGroup
+	errs := make(chan error, 1000)
+	wg.Add(1000)
+	for i := 0; i < 1000; i++ {
+		go func() {
+			defer wg.Done()
+			// Read both keys under a single lock acquisition: two separate
+			// Exists calls could straddle the swap and see both as present.
+			m.mutex.RLock()
+			hasOld := m.data["i-1"] != nil
+			hasNew := m.data["i-2"] != nil
+			m.mutex.RUnlock()
+			if hasOld == hasNew {
+				errs <- fmt.Errorf("observed mixed state: hasOld=%v hasNew=%v", hasOld, hasNew)
+			}
+		}()
+	}
+
+	m.ReplaceAll(newInstances)
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.False(t, m.Exists("i-1"))
+	require.True(t, m.Exists("i-2"))
+}
+
+func TestInstanceMapForeachStaleInterface(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	m := NewInstanceMapWithClock(fakeClock)
+
+	m.Update("i-1", InterfaceRevision{Resource: &mockInterface{id: "intf0"}})
+
+	fakeClock.Step(time.Minute)
+	m.Update("i-2", InterfaceRevision{Resource: &mockInterface{id: "intf0"}})
+
+	fakeClock.Step(time.Minute)
+
+	// Both interfaces were last seen before "now", but only "i-1" is older
+	// than a window of 90 seconds.
+	var stale []string
+	err := m.ForeachStaleInterface(fakeClock.Now().Add(-90*time.Second), func(instanceID, interfaceID string, iface InterfaceRevision) error {
+		stale = append(stale, instanceID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"i-1"}, stale)
+
+	// Refreshing "i-1" resets its LastSeen, so neither interface is stale
+	// against the same window anymore.
+	m.Update("i-1", InterfaceRevision{Resource: &mockInterface{id: "intf0"}})
+
+	stale = nil
+	err = m.ForeachStaleInterface(fakeClock.Now().Add(-90*time.Second), func(instanceID, interfaceID string, iface InterfaceRevision) error {
+		stale = append(stale, instanceID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, stale)
+}
+
 func TestFirstSubnetWithAvailableAddresses(t *testing.T) {
 	sm := SubnetMap{
 		"s0": &Subnet{AvailableAddresses: 0},
@@ -183,3 +346,491 @@ func TestFirstSubnetWithAvailableAddresses(t *testing.T) {
 	require.Equal(t, PoolID("s1"), subnetID)
 	require.Equal(t, 10, addresses)
 }
+
+func TestFirstSubnetWithAvailableAddressesInZone(t *testing.T) {
+	// Empty zone falls back to the zone-agnostic behavior.
+	fallbackMap := SubnetMap{
+		"s0": &Subnet{AvailabilityZone: "eu-west-1a", AvailableAddresses: 0},
+		"s1": &Subnet{AvailabilityZone: "eu-west-1b", AvailableAddresses: 20},
+	}
+	subnetID, addresses := fallbackMap.FirstSubnetWithAvailableAddressesInZone("", []PoolID{})
+	require.Equal(t, PoolID("s1"), subnetID)
+	require.Equal(t, 20, addresses)
+
+	sm := SubnetMap{
+		"s0": &Subnet{AvailabilityZone: "eu-west-1a", AvailableAddresses: 10},
+		"s1": &Subnet{AvailabilityZone: "eu-west-1b", AvailableAddresses: 20},
+		"s2": &Subnet{AvailabilityZone: "eu-west-1b", AvailableAddresses: 0},
+	}
+
+	// Selection is pinned to the requested zone, even if a preferred pool
+	// in a different zone has available addresses.
+	subnetID, addresses = sm.FirstSubnetWithAvailableAddressesInZone("eu-west-1b", []PoolID{"s0"})
+	require.Equal(t, PoolID("s1"), subnetID)
+	require.Equal(t, 20, addresses)
+
+	// Preferred pool s2 has no addresses, but the zone still has another
+	// subnet (s1) with availability, which is found by the non-preferred
+	// fallback search.
+	subnetID, addresses = sm.FirstSubnetWithAvailableAddressesInZone("eu-west-1b", []PoolID{"s2"})
+	require.Equal(t, PoolID("s1"), subnetID)
+	require.Equal(t, 20, addresses)
+
+	// Unknown zone matches nothing.
+	subnetID, addresses = sm.FirstSubnetWithAvailableAddressesInZone("eu-west-1c", []PoolID{})
+	require.Equal(t, PoolNotExists, subnetID)
+	require.Equal(t, 0, addresses)
+
+	// A zone whose only subnet has no available addresses matches nothing,
+	// even with a preferred pool from elsewhere.
+	sm = SubnetMap{
+		"s0": &Subnet{AvailabilityZone: "eu-west-1a", AvailableAddresses: 10},
+		"s1": &Subnet{AvailabilityZone: "eu-west-1b", AvailableAddresses: 0},
+	}
+	subnetID, addresses = sm.FirstSubnetWithAvailableAddressesInZone("eu-west-1b", []PoolID{"s0"})
+	require.Equal(t, PoolNotExists, subnetID)
+	require.Equal(t, 0, addresses)
+}
+
+func TestSubnetMapMatchingSubnets(t *testing.T) {
+	sm := SubnetMap{
+		"s0": &Subnet{AvailableAddresses: 5, Tags: Tags{"tier": "public"}},
+		"s1": &Subnet{AvailableAddresses: 20, Tags: Tags{"tier": "private"}},
+		"s2": &Subnet{AvailableAddresses: 10, Tags: Tags{"tier": "public", "zone": "a"}},
+	}
+
+	// A nil required tag set matches every subnet, sorted by descending
+	// available addresses.
+	require.Equal(t, []*Subnet{sm["s1"], sm["s2"], sm["s0"]}, sm.MatchingSubnets(nil))
+
+	// Only subnets carrying all the required tags are returned, still
+	// sorted by descending available addresses.
+	require.Equal(t, []*Subnet{sm["s2"], sm["s0"]}, sm.MatchingSubnets(Tags{"tier": "public"}))
+
+	// A required tag/value pair matched by no subnet yields no results.
+	require.Empty(t, sm.MatchingSubnets(Tags{"tier": "does-not-exist"}))
+}
+
+func TestRouteTableMapSameRouteTable(t *testing.T) {
+	rtm := RouteTableMap{
+		"rt-0": &RouteTable{ID: "rt-0", Subnets: map[string]struct{}{"s0": {}, "s1": {}}},
+		"rt-1": &RouteTable{ID: "rt-1", Subnets: map[string]struct{}{"s2": {}}},
+	}
+
+	// Both subnets are in rt-0.
+	same, err := rtm.SameRouteTable("s0", "s1")
+	require.NoError(t, err)
+	require.True(t, same)
+
+	// s0 is in rt-0, s2 is in rt-1.
+	same, err = rtm.SameRouteTable("s0", "s2")
+	require.NoError(t, err)
+	require.False(t, same)
+
+	// A subnet not present in any route table is an error.
+	_, err = rtm.SameRouteTable("s0", "does-not-exist")
+	require.Error(t, err)
+}
+
+func TestSubnetHasCapacityForPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		subnet     Subnet
+		prefixLen  int
+		ipv6       bool
+		wantResult bool
+	}{
+		{
+			name:       "ipv4 /28 with exactly enough addresses",
+			subnet:     Subnet{AvailableAddresses: 16},
+			prefixLen:  28,
+			wantResult: true,
+		},
+		{
+			name:       "ipv4 /28 with one address short",
+			subnet:     Subnet{AvailableAddresses: 15},
+			prefixLen:  28,
+			wantResult: false,
+		},
+		{
+			name:       "ipv4 /28 checked against the ipv6 count",
+			subnet:     Subnet{AvailableAddresses: 16, AvailableIPv6Addresses: 0},
+			prefixLen:  28,
+			ipv6:       true,
+			wantResult: false,
+		},
+		{
+			name:       "ipv6 /80 with exactly enough addresses",
+			subnet:     Subnet{AvailableIPv6Addresses: 1 << 48},
+			prefixLen:  80,
+			ipv6:       true,
+			wantResult: true,
+		},
+		{
+			name:       "ipv6 /80 with one address short",
+			subnet:     Subnet{AvailableIPv6Addresses: 1<<48 - 1},
+			prefixLen:  80,
+			ipv6:       true,
+			wantResult: false,
+		},
+		{
+			name:       "negative prefix length is impossible",
+			subnet:     Subnet{AvailableAddresses: 1 << 30},
+			prefixLen:  -1,
+			wantResult: false,
+		},
+		{
+			name:       "prefix length wider than the address family is impossible",
+			subnet:     Subnet{AvailableAddresses: 1 << 30},
+			prefixLen:  40,
+			wantResult: false,
+		},
+		{
+			name:       "ipv6 prefix length far too wide is impossible, not an overflow",
+			subnet:     Subnet{AvailableIPv6Addresses: 1 << 30},
+			prefixLen:  0,
+			ipv6:       true,
+			wantResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantResult, tt.subnet.HasCapacityForPrefix(tt.prefixLen, tt.ipv6))
+		})
+	}
+}
+
+func TestIPAMPoolSpecDiff(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            IPAMPoolSpec
+		other           IPAMPoolSpec
+		wantAdded       []IPAMPoolAllocation
+		wantRemoved     []IPAMPoolAllocation
+		wantChangedReqs []IPAMPoolRequest
+	}{
+		{
+			name: "added pool",
+			spec: IPAMPoolSpec{},
+			other: IPAMPoolSpec{
+				Allocated: []IPAMPoolAllocation{
+					{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.0.0/24"}},
+				},
+			},
+			wantAdded: []IPAMPoolAllocation{
+				{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.0.0/24"}},
+			},
+		},
+		{
+			name: "removed cidr",
+			spec: IPAMPoolSpec{
+				Allocated: []IPAMPoolAllocation{
+					{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.0.0/24", "10.0.1.0/24"}},
+				},
+			},
+			other: IPAMPoolSpec{
+				Allocated: []IPAMPoolAllocation{
+					{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.0.0/24"}},
+				},
+			},
+			wantRemoved: []IPAMPoolAllocation{
+				{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.1.0/24"}},
+			},
+		},
+		{
+			name: "order insensitive, no diff",
+			spec: IPAMPoolSpec{
+				Allocated: []IPAMPoolAllocation{
+					{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.0.0/24", "10.0.1.0/24"}},
+				},
+			},
+			other: IPAMPoolSpec{
+				Allocated: []IPAMPoolAllocation{
+					{Pool: "default", CIDRs: []IPAMPodCIDR{"10.0.1.0/24", "10.0.0.0/24"}},
+				},
+			},
+		},
+		{
+			name: "changed needed demand",
+			spec: IPAMPoolSpec{
+				Requested: []IPAMPoolRequest{
+					{Pool: "default", Needed: IPAMPoolDemand{IPv4Addrs: 1}},
+				},
+			},
+			other: IPAMPoolSpec{
+				Requested: []IPAMPoolRequest{
+					{Pool: "default", Needed: IPAMPoolDemand{IPv4Addrs: 4}},
+				},
+			},
+			wantChangedReqs: []IPAMPoolRequest{
+				{Pool: "default", Needed: IPAMPoolDemand{IPv4Addrs: 4}},
+			},
+		},
+		{
+			name: "unchanged demand, no diff",
+			spec: IPAMPoolSpec{
+				Requested: []IPAMPoolRequest{
+					{Pool: "default", Needed: IPAMPoolDemand{IPv4Addrs: 2}},
+				},
+			},
+			other: IPAMPoolSpec{
+				Requested: []IPAMPoolRequest{
+					{Pool: "default", Needed: IPAMPoolDemand{IPv4Addrs: 2}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, changedRequests := tt.spec.Diff(tt.other)
+			require.ElementsMatch(t, tt.wantAdded, added)
+			require.ElementsMatch(t, tt.wantRemoved, removed)
+			require.ElementsMatch(t, tt.wantChangedReqs, changedRequests)
+		})
+	}
+}
+
+func TestPoolQuotaMapSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		m           PoolQuotaMap
+		wantIPv4    int
+		wantIPv6    int
+		wantPerZone map[string]PoolQuota
+	}{
+		{
+			name:        "empty map",
+			m:           PoolQuotaMap{},
+			wantPerZone: map[string]PoolQuota{},
+		},
+		{
+			name: "single pool",
+			m: PoolQuotaMap{
+				"p0": {AvailabilityZone: "eu-west-1a", AvailableIPs: 10, AvailableIPv6s: 5},
+			},
+			wantIPv4: 10,
+			wantIPv6: 5,
+			wantPerZone: map[string]PoolQuota{
+				"eu-west-1a": {AvailabilityZone: "eu-west-1a", AvailableIPs: 10, AvailableIPv6s: 5},
+			},
+		},
+		{
+			name: "multiple pools, multiple zones",
+			m: PoolQuotaMap{
+				"p0": {AvailabilityZone: "eu-west-1a", AvailableIPs: 10, AvailableIPv6s: 5},
+				"p1": {AvailabilityZone: "eu-west-1a", AvailableIPs: 3, AvailableIPv6s: 0},
+				"p2": {AvailabilityZone: "eu-west-1b", AvailableIPs: 7, AvailableIPv6s: 2},
+			},
+			wantIPv4: 20,
+			wantIPv6: 7,
+			wantPerZone: map[string]PoolQuota{
+				"eu-west-1a": {AvailabilityZone: "eu-west-1a", AvailableIPs: 13, AvailableIPv6s: 5},
+				"eu-west-1b": {AvailabilityZone: "eu-west-1b", AvailableIPs: 7, AvailableIPv6s: 2},
+			},
+		},
+		{
+			name: "pool with no availability zone",
+			m: PoolQuotaMap{
+				"p0": {AvailableIPs: 4, AvailableIPv6s: 1},
+			},
+			wantIPv4: 4,
+			wantIPv6: 1,
+			wantPerZone: map[string]PoolQuota{
+				"": {AvailableIPs: 4, AvailableIPv6s: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIPv4, gotIPv6, gotPerZone := tt.m.Summary()
+			require.Equal(t, tt.wantIPv4, gotIPv4)
+			require.Equal(t, tt.wantIPv6, gotIPv6)
+			require.Equal(t, tt.wantPerZone, gotPerZone)
+		})
+	}
+}
+
+func TestIPAMSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    IPAMSpec
+		wantErr string
+	}{
+		{
+			name: "zero value is valid",
+			spec: IPAMSpec{},
+		},
+		{
+			name: "valid watermarks",
+			spec: IPAMSpec{MinAllocate: 8, PreAllocate: 16, MaxAboveWatermark: 4, MaxAllocate: 32},
+		},
+		{
+			name:    "negative min-allocate",
+			spec:    IPAMSpec{MinAllocate: -1},
+			wantErr: "min-allocate must not be negative",
+		},
+		{
+			name:    "negative max-allocate",
+			spec:    IPAMSpec{MaxAllocate: -1},
+			wantErr: "max-allocate must not be negative",
+		},
+		{
+			name:    "negative pre-allocate",
+			spec:    IPAMSpec{PreAllocate: -1},
+			wantErr: "pre-allocate must not be negative",
+		},
+		{
+			name:    "negative max-above-watermark",
+			spec:    IPAMSpec{MaxAboveWatermark: -1},
+			wantErr: "max-above-watermark must not be negative",
+		},
+		{
+			name:    "min-allocate exceeds max-allocate",
+			spec:    IPAMSpec{MinAllocate: 16, MaxAllocate: 8},
+			wantErr: "min-allocate (16) must not exceed max-allocate (8)",
+		},
+		{
+			name:    "pre-allocate exceeds max-allocate",
+			spec:    IPAMSpec{PreAllocate: 16, MaxAllocate: 8},
+			wantErr: "pre-allocate (16) must not exceed max-allocate (8)",
+		},
+		{
+			name: "min-allocate above max-allocate ignored when max-allocate unset",
+			spec: IPAMSpec{MinAllocate: 16},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestIPAMStatusReleaseHandshake exercises a full batch of the IP release
+// handshake: the operator marks a batch of IPs for release, the agent acks
+// some and nacks others, and the operator collects only the acked ones,
+// leaving the nacked and not-yet-responded IPs behind.
+func TestIPAMStatusReleaseHandshake(t *testing.T) {
+	var status IPAMStatus
+
+	status.MarkForRelease([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, IPv4Family)
+	require.Equal(t, map[string]IPReleaseStatus{
+		"10.0.0.1": IPReleaseStatus(ipamOption.IPAMMarkForRelease),
+		"10.0.0.2": IPReleaseStatus(ipamOption.IPAMMarkForRelease),
+		"10.0.0.3": IPReleaseStatus(ipamOption.IPAMMarkForRelease),
+	}, status.ReleaseIPs)
+	require.Empty(t, status.ReleaseIPv6s)
+
+	// Before the agent responds, nothing is ready for release yet.
+	require.Empty(t, status.CollectReadyForRelease(IPv4Family))
+
+	// Agent acks 10.0.0.1, nacks 10.0.0.2, and hasn't gotten to 10.0.0.3 yet.
+	status.ReleaseIPs["10.0.0.1"] = IPReleaseStatus(ipamOption.IPAMReadyForRelease)
+	status.ReleaseIPs["10.0.0.2"] = IPReleaseStatus(ipamOption.IPAMDoNotRelease)
+
+	require.ElementsMatch(t, []string{"10.0.0.1"}, status.CollectReadyForRelease(IPv4Family))
+
+	// Re-marking the batch must not clobber the agent's do-not-release
+	// verdict, nor the already-acked ready-for-release IP.
+	status.MarkForRelease([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, IPv4Family)
+	require.Equal(t, IPReleaseStatus(ipamOption.IPAMReadyForRelease), status.ReleaseIPs["10.0.0.1"])
+	require.Equal(t, IPReleaseStatus(ipamOption.IPAMDoNotRelease), status.ReleaseIPs["10.0.0.2"])
+	require.Equal(t, IPReleaseStatus(ipamOption.IPAMMarkForRelease), status.ReleaseIPs["10.0.0.3"])
+
+	// Once the operator has released an IP, re-marking it must not reopen
+	// the handshake.
+	status.ReleaseIPs["10.0.0.1"] = IPReleaseStatus(ipamOption.IPAMReleased)
+	status.MarkForRelease([]string{"10.0.0.1"}, IPv4Family)
+	require.Equal(t, IPReleaseStatus(ipamOption.IPAMReleased), status.ReleaseIPs["10.0.0.1"])
+
+	// IPv6 IPs are tracked independently in ReleaseIPv6s.
+	status.MarkForRelease([]string{"fd00::1"}, IPv6Family)
+	require.Equal(t, map[string]IPReleaseStatus{
+		"fd00::1": IPReleaseStatus(ipamOption.IPAMMarkForRelease),
+	}, status.ReleaseIPv6s)
+	require.Empty(t, status.CollectReadyForRelease(IPv6Family))
+
+	status.ReleaseIPv6s["fd00::1"] = IPReleaseStatus(ipamOption.IPAMReadyForRelease)
+	require.ElementsMatch(t, []string{"fd00::1"}, status.CollectReadyForRelease(IPv6Family))
+	// The IPv4 batch has no IP in the ready-for-release state any more:
+	// 10.0.0.1 was released, 10.0.0.2 was nacked, and 10.0.0.3 is still
+	// only marked, not yet acked by the agent.
+	require.Empty(t, status.CollectReadyForRelease(IPv4Family))
+}
+
+func TestLimitsUsablePerAdapter(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		wantIPv4 int
+		wantIPv6 int
+	}{
+		{
+			name:     "no reservation",
+			limits:   Limits{IPv4: 14, IPv6: 14},
+			wantIPv4: 14,
+			wantIPv6: 14,
+		},
+		{
+			name:     "reservation for the primary IP",
+			limits:   Limits{IPv4: 14, IPv6: 14, ReservedIPv4: 1, ReservedIPv6: 1},
+			wantIPv4: 13,
+			wantIPv6: 13,
+		},
+		{
+			name:     "reservation exceeding capacity is floored at zero",
+			limits:   Limits{IPv4: 1, IPv6: 0, ReservedIPv4: 4, ReservedIPv6: 4},
+			wantIPv4: 0,
+			wantIPv6: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantIPv4, tt.limits.UsableIPv4PerAdapter())
+			require.Equal(t, tt.wantIPv6, tt.limits.UsableIPv6PerAdapter())
+		})
+	}
+}
+
+func TestAllocationMapValidateUsed(t *testing.T) {
+	valid := AllocationMap{
+		"10.0.0.1": {Owner: "default/pod-a", Resource: "eni-1"},
+		"10.0.0.2": {Owner: "default/pod-b", Resource: "eni-1"},
+	}
+	require.NoError(t, valid.ValidateUsed())
+
+	require.NoError(t, AllocationMap{}.ValidateUsed())
+
+	missingOwner := AllocationMap{
+		"10.0.0.1": {Owner: "default/pod-a", Resource: "eni-1"},
+		"10.0.0.2": {Resource: "eni-1"},
+	}
+	err := missingOwner.ValidateUsed()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "10.0.0.2 has no owner")
+
+	missingResource := AllocationMap{
+		"10.0.0.1": {Owner: "default/pod-a"},
+	}
+	err = missingResource.ValidateUsed()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "10.0.0.1 has no resource")
+
+	missingBoth := AllocationMap{
+		"10.0.0.1": {},
+	}
+	err = missingBoth.ValidateUsed()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "10.0.0.1 has no owner")
+	require.ErrorContains(t, err, "10.0.0.1 has no resource")
+}
@@ -37,3 +37,9 @@ func RetryUpTo(n int) ErrorHandler {
 		return ErrorActionRetry
 	}
 }
+
+// ErrorActionDrop instructs to drop the event. Unlike ErrorActionStop this only
+// abandons the failing key: it is forgotten (clearing its retry history) so that
+// future updates to the same key are processed normally, rather than shutting
+// down the whole subscriber.
+var ErrorActionDrop ErrorAction = "drop"
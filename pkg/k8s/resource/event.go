@@ -4,6 +4,8 @@
 package resource
 
 import (
+	"log/slog"
+
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -13,6 +15,20 @@ const (
 	Sync   EventKind = "sync"
 	Upsert EventKind = "upsert"
 	Delete EventKind = "delete"
+
+	// Resync is emitted when the underlying informer had to relist the
+	// resource, e.g. after its watch connection expired or errored out
+	// ("too old resource version"). Unlike Sync, which is emitted exactly
+	// once after the initial listing, Resync may be emitted any number of
+	// times after that. It is followed by whatever Upsert/Delete events
+	// result from reconciling the relisted state against what was
+	// previously observed, in case any updates were missed while the watch
+	// was down; a relist of an empty set yields no such events at all. Consumers that track derived state keyed by what they've
+	// seen (e.g. for garbage collection) should treat Resync as a cue to
+	// re-validate that state against the store, since the relist may have
+	// surfaced staleness that incremental Upsert/Delete events alone
+	// wouldn't reveal.
+	Resync EventKind = "resync"
 )
 
 // Event emitted from resource.
@@ -21,6 +37,13 @@ type Event[T k8sRuntime.Object] struct {
 	Key    Key
 	Object T
 
+	// ResourceVersion is the apiserver resourceVersion of Object, as of
+	// when this event was observed. Empty for Sync and Resync events,
+	// which carry no Object. Consumers that need to detect staleness or
+	// resume a watch from where they left off can track this via a
+	// Subscription's LastResourceVersion.
+	ResourceVersion string
+
 	// Done marks the event as processed.  If err is non-nil, the
 	// key of the object is requeued and the processing retried at
 	// a later time with a potentially new version of the object.
@@ -29,3 +52,19 @@ type Event[T k8sRuntime.Object] struct {
 	// are gone, the finalizer will panic.
 	Done func(err error)
 }
+
+// LogAttrs returns the event as structured slog attributes, so consumers
+// that log events via slog (e.g. slog.Info("event", e.LogAttrs()...)) do so
+// with a stable set of attribute keys regardless of Kind. ResourceVersion is
+// omitted for Sync and Resync events, which carry none.
+func (e Event[T]) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("kind", string(e.Kind)),
+		slog.String("key", e.Key.String()),
+	}
+	switch e.Kind {
+	case Upsert, Delete:
+		attrs = append(attrs, slog.String("resourceVersion", e.ResourceVersion))
+	}
+	return attrs
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resource
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEventLogAttrs(t *testing.T) {
+	key := Key{Name: "foo", Namespace: "bar"}
+
+	tests := []struct {
+		name  string
+		event Event[*corev1.Pod]
+		want  []slog.Attr
+	}{
+		{
+			name:  "sync",
+			event: Event[*corev1.Pod]{Kind: Sync, Key: key},
+			want: []slog.Attr{
+				slog.String("kind", "sync"),
+				slog.String("key", "bar/foo"),
+			},
+		},
+		{
+			name:  "upsert",
+			event: Event[*corev1.Pod]{Kind: Upsert, Key: key, ResourceVersion: "123"},
+			want: []slog.Attr{
+				slog.String("kind", "upsert"),
+				slog.String("key", "bar/foo"),
+				slog.String("resourceVersion", "123"),
+			},
+		},
+		{
+			name:  "delete",
+			event: Event[*corev1.Pod]{Kind: Delete, Key: key, ResourceVersion: "124"},
+			want: []slog.Attr{
+				slog.String("kind", "delete"),
+				slog.String("key", "bar/foo"),
+				slog.String("resourceVersion", "124"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.event.LogAttrs())
+		})
+	}
+}
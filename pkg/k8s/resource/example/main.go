@@ -194,6 +194,13 @@ func (ps *PrintServices) processLoop(ctx context.Context) error {
 				// of garbage collection at startup: we now know what is the set of pods that
 				// existed at the api-server brief moment ago and can remove persisted
 				// data of pods that are not part of this set.
+			case resource.Resync:
+				// The watch had to be restarted with a full relist, e.g. because
+				// it errored out or expired. Some pod updates or deletions may
+				// have been missed while unwatched, so the same startup garbage
+				// collection logic triggered on Sync should be re-run once the
+				// Upsert/Delete events that follow have brought podLabels back
+				// in sync with the relisted state.
 			case resource.Upsert:
 				log.Infof("Pod %s updated", ev.Key)
 				podLabels[ev.Key] = labels.Map2Labels(ev.Object.Labels, labels.LabelSourceK8s)
@@ -4,6 +4,9 @@
 package resource
 
 import (
+	"fmt"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/tools/cache"
 )
@@ -17,11 +20,76 @@ type Key struct {
 	Namespace string
 }
 
+// String returns the string representation of the key, with Namespace and
+// Name joined by a "/". Any "/" or "\" occurring within Namespace or Name is
+// escaped with a "\", so that ParseKey can invert String for any Key,
+// including ones with slashes in the namespace or name. Kubernetes object
+// names and namespaces cannot contain either character in practice, so for
+// the common case String returns exactly what it always has.
 func (k Key) String() string {
 	if len(k.Namespace) > 0 {
-		return k.Namespace + "/" + k.Name
+		return escapeKeyPart(k.Namespace) + "/" + escapeKeyPart(k.Name)
+	}
+	return escapeKeyPart(k.Name)
+}
+
+// ParseKey parses a string produced by Key.String back into a Key, inverting
+// the escaping String applies to "/" and "\" in the namespace and name.
+func ParseKey(s string) (Key, error) {
+	parts, err := splitEscapedKey(s)
+	if err != nil {
+		return Key{}, err
+	}
+	switch len(parts) {
+	case 1:
+		return Key{Name: parts[0]}, nil
+	case 2:
+		return Key{Namespace: parts[0], Name: parts[1]}, nil
+	default:
+		return Key{}, fmt.Errorf("invalid key %q: expected at most one unescaped '/'", s)
+	}
+}
+
+var keyPartEscaper = strings.NewReplacer(`\`, `\\`, `/`, `\/`)
+
+// escapeKeyPart escapes "\" and "/" in s so that it can be unambiguously
+// joined with another part using an unescaped "/" separator.
+func escapeKeyPart(s string) string {
+	if !strings.ContainsAny(s, `\/`) {
+		return s
+	}
+	return keyPartEscaper.Replace(s)
+}
+
+// splitEscapedKey splits s on unescaped "/" separators, unescaping "\/" and
+// "\\" along the way.
+func splitEscapedKey(s string) ([]string, error) {
+	var parts []string
+	var cur strings.Builder
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			if c != '/' && c != '\\' {
+				return nil, fmt.Errorf("invalid key %q: invalid escape sequence '\\%c'", s, c)
+			}
+			cur.WriteByte(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '/':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("invalid key %q: trailing unescaped '\\'", s)
 	}
-	return k.Name
+	parts = append(parts, cur.String())
+	return parts, nil
 }
 
 func NewKey(obj any) Key {
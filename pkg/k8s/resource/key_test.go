@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStringParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       Key
+		wantPlain string // expected Key.String() output, when unambiguous
+	}{
+		{
+			name:      "name only",
+			key:       Key{Name: "foo"},
+			wantPlain: "foo",
+		},
+		{
+			name:      "namespace and name",
+			key:       Key{Namespace: "default", Name: "foo"},
+			wantPlain: "default/foo",
+		},
+		{
+			name: "name with slash",
+			key:  Key{Name: "foo/bar"},
+		},
+		{
+			name: "namespace and name with slash",
+			key:  Key{Namespace: "default", Name: "foo/bar"},
+		},
+		{
+			name: "namespace with slash",
+			key:  Key{Namespace: "ns/sub", Name: "foo"},
+		},
+		{
+			name: "name with backslash",
+			key:  Key{Name: `foo\bar`},
+		},
+		{
+			name: "name with backslash and slash",
+			key:  Key{Namespace: "default", Name: `foo\bar/baz`},
+		},
+		{
+			name: "empty name",
+			key:  Key{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.key.String()
+			if tt.wantPlain != "" {
+				assert.Equal(t, tt.wantPlain, s)
+			}
+
+			got, err := ParseKey(s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, got)
+		})
+	}
+}
+
+func TestParseKeyInvalid(t *testing.T) {
+	tests := []string{
+		`foo\`,  // trailing unescaped backslash
+		`foo\x`, // invalid escape sequence
+		`a/b/c`, // too many unescaped separators
+	}
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseKey(s)
+			require.Error(t, err)
+		})
+	}
+}
+
+func FuzzKeyStringParseRoundTrip(f *testing.F) {
+	f.Add("foo", "")
+	f.Add("foo", "default")
+	f.Add("foo/bar", "")
+	f.Add(`foo\bar`, "ns/sub")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, name, namespace string) {
+		k := Key{Name: name, Namespace: namespace}
+		s := k.String()
+
+		got, err := ParseKey(s)
+		if err != nil {
+			t.Fatalf("ParseKey(%q) failed to parse output of String(): %v", s, err)
+		}
+		if got != k {
+			t.Fatalf("round trip mismatch: got %+v, want %+v (via %q)", got, k, s)
+		}
+	})
+}
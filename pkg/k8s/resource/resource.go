@@ -6,26 +6,33 @@ package resource
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cilium/hive/cell"
 	"github.com/cilium/stream"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	k8smetrics "github.com/cilium/cilium/pkg/k8s/metrics"
 	"github.com/cilium/cilium/pkg/k8s/synced"
 	"github.com/cilium/cilium/pkg/k8s/watchers/resources"
 	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging/logfields"
 	"github.com/cilium/cilium/pkg/metrics"
 	"github.com/cilium/cilium/pkg/promise"
 )
@@ -76,6 +83,14 @@ type Resource[T k8sRuntime.Object] interface {
 	// store has synchronized and that Upsert events for objects in a synchronized store have been
 	// sent to the observer.
 	//
+	// If the underlying watch has to be restarted with a full relist, e.g. because it errored out
+	// or expired, a Resync event is emitted just before the Upsert/Delete events resulting from
+	// reconciling the relisted state, in case any updates were missed while unwatched:
+	//
+	//	..., Sync, Upsert, Upsert, (watch expires, relist happens), Resync, Upsert, Delete, Upsert, ...
+	//
+	// Unlike Sync, Resync may be emitted any number of times over the lifetime of the subscription.
+	//
 	// When Done() is called with non-nil error the error handler is invoked, which
 	// can ignore, requeue the event (by key) or close the channel. The default error handler
 	// will requeue.
@@ -94,6 +109,31 @@ type Resource[T k8sRuntime.Object] interface {
 	// Returns a non-nil error if context is cancelled or the resource
 	// has been stopped before store has synchronized.
 	Store(context.Context) (Store[T], error)
+
+	// Get is a convenience method for retrieving a single object by key.
+	// Blocks until the store has been synchronized or the context
+	// cancelled, same as Store(). Returns (zero, false, nil) if the object
+	// does not exist; the error is only non-nil if ctx fails to obtain the
+	// store.
+	Get(ctx context.Context, key Key) (item T, exists bool, err error)
+
+	// HasSynced returns true once the underlying informer's cache has
+	// synchronized, without blocking or starting the informer. Returns
+	// false before the resource has been started (e.g. before the first
+	// call to Events() or Store()).
+	HasSynced() bool
+
+	// Drain blocks until all subscribers obtained via Events() have stopped
+	// (their channel has been closed) and every event they were handed has
+	// been acknowledged with Done(). This is stronger than waiting for
+	// Events()'s channel to close: a subscriber may close its channel while
+	// still holding an event it forgot to ack.
+	//
+	// Returns nil once fully drained, or an error listing the still
+	// outstanding events if ctx is cancelled first. Intended to be called
+	// during shutdown, after the context passed to Events() has been
+	// cancelled, to detect subscribers that are stuck or leaking events.
+	Drain(ctx context.Context) error
 }
 
 // New creates a new Resource[T]. Use with hive.Provide:
@@ -144,6 +184,7 @@ func New[T k8sRuntime.Object](lc cell.Lifecycle, lw cache.ListerWatcher, opts ..
 		var obj T
 		return obj
 	}
+	r.opts.clock = clock.RealClock{}
 	for _, o := range opts {
 		o(&r.opts)
 	}
@@ -154,13 +195,18 @@ func New[T k8sRuntime.Object](lc cell.Lifecycle, lw cache.ListerWatcher, opts ..
 }
 
 type options struct {
-	transform      cache.TransformFunc             // if non-nil, the object is transformed with this function before storing
-	sourceObj      func() k8sRuntime.Object        // prototype for the object before it is transformed
-	indexers       cache.Indexers                  // map of the optional custom indexers to be added to the underlying resource informer
-	metricScope    string                          // the scope label used when recording metrics for the resource
-	name           string                          // the name label used for the workqueue metrics
-	releasable     bool                            // if true, the underlying informer will be stopped when the last subscriber cancels its subscription
-	crdSyncPromise promise.Promise[synced.CRDSync] // optional promise to wait for
+	transform         cache.TransformFunc             // if non-nil, the object is transformed with this function before storing
+	sourceObj         func() k8sRuntime.Object        // prototype for the object before it is transformed
+	indexers          cache.Indexers                  // map of the optional custom indexers to be added to the underlying resource informer
+	metricScope       string                          // the scope label used when recording metrics for the resource
+	name              string                          // the name label used for the workqueue metrics
+	releasable        bool                            // if true, the underlying informer will be stopped when the last subscriber cancels its subscription
+	crdSyncPromise    promise.Promise[synced.CRDSync] // optional promise to wait for
+	storeOnly         bool                            // if true, Events() never allocates a subscription and only Store() is usable
+	watchErrorHandler WatchErrorHandler               // if non-nil, called on persistent list/watch errors from the underlying informer
+	processingLatency bool                            // if true, observe the time from event delivery to Done() in KubernetesEventProcessingLatency
+	clock             clock.WithTicker                // clock used for the retry/rate-limiting workqueue, defaults to the real clock
+	resyncPeriod      time.Duration                   // if non-zero, the informer periodically relists at this cadence, redelivering Upsert for every object
 }
 
 type ResourceOption func(o *options)
@@ -200,6 +246,18 @@ func WithMetric(scope string) ResourceOption {
 	}
 }
 
+// WithStoreOnly marks the resource as only ever being consumed through Store().
+// The informer is still started as usual when either Store() or Events() is
+// called, but Events() never allocates a subscription or an event-processing
+// goroutine: it always returns a closed, empty channel. Use this for resources
+// where only the read-only store is needed, to avoid the memory and queue
+// overhead of an unused event stream.
+func WithStoreOnly() ResourceOption {
+	return func(o *options) {
+		o.storeOnly = true
+	}
+}
+
 // WithIndexers sets additional custom indexers on the resource store.
 func WithIndexers(indexers cache.Indexers) ResourceOption {
 	return func(o *options) {
@@ -207,7 +265,14 @@ func WithIndexers(indexers cache.Indexers) ResourceOption {
 	}
 }
 
-// WithName sets the name of the resource. Used for workqueue metrics.
+// WithName sets the name of the resource, identifying this particular
+// instance independently of WithMetric's scope. This matters when multiple
+// Resource[T] instances of the same underlying type are watched with
+// different list/watch parameters (e.g. two Pod resources with different
+// label selectors): without a distinct name they would otherwise be
+// indistinguishable in the per-resource workqueue metrics and in structured
+// logs. Defaults to the lowercased kind of T (as resolved from its
+// GroupVersionKind) when unset.
 func WithName(name string) ResourceOption {
 	return func(o *options) {
 		o.name = name
@@ -237,6 +302,59 @@ func WithStoppableInformer() ResourceOption {
 	}
 }
 
+// WatchErrorHandler is called when the resource's underlying informer fails
+// to list or watch the apiserver with an error that is not an expected,
+// transient watch closure (a watch timeout, a resource version expiry, or a
+// clean EOF, all of which client-go retries silently). This lets a caller
+// observe persistent failures, e.g. an RBAC rejection or an invalid field or
+// label selector, which would otherwise only be visible as the continued
+// absence of a Sync event.
+type WatchErrorHandler func(err error)
+
+// WithWatchErrorHandler registers a WatchErrorHandler to be invoked whenever
+// the resource's underlying informer fails to list or watch with a
+// persistent error. This is independent of WithErrorHandler, which controls
+// retries of individual failed events once they have reached a subscriber.
+func WithWatchErrorHandler(h WatchErrorHandler) ResourceOption {
+	return func(o *options) {
+		o.watchErrorHandler = h
+	}
+}
+
+// WithProcessingLatencyMetric enables observing, for every emitted event,
+// the time between the event being delivered to a subscriber on the
+// Events() channel and the subscriber calling Done() on it. The observation
+// is recorded in metrics.KubernetesEventProcessingLatency, labeled by the
+// resource's name (see WithName) and the event kind.
+func WithProcessingLatencyMetric() ResourceOption {
+	return func(o *options) {
+		o.processingLatency = true
+	}
+}
+
+// WithClock overrides the clock used by the retry/rate-limiting workqueue
+// backing each subscriber's Events() stream. Tests can use this with a fake
+// clock (e.g. k8s.io/utils/clock/testing) to deterministically advance time
+// and observe a retried event without waiting on the real clock. Defaults
+// to the real clock.
+func WithClock(c clock.WithTicker) ResourceOption {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithResyncPeriod sets the period at which the underlying informer
+// resyncs its local store, redelivering an Upsert event for every object
+// currently cached, in addition to the usual watch-driven events. Note that
+// this only replays what the store already has; it does not relist the
+// apiserver, so it cannot correct for drift the watch itself missed.
+// Defaults to zero, meaning no periodic resync is performed.
+func WithResyncPeriod(d time.Duration) ResourceOption {
+	return func(o *options) {
+		o.resyncPeriod = d
+	}
+}
+
 type resource[T k8sRuntime.Object] struct {
 	mu     lock.RWMutex
 	ctx    context.Context
@@ -255,6 +373,14 @@ type resource[T k8sRuntime.Object] struct {
 	storePromise  promise.Promise[Store[T]]
 	storeResolver promise.Resolver[Store[T]]
 
+	// outstanding tracks events that have been handed to a subscriber but
+	// not yet acknowledged with Done(), keyed by the subscriber and work
+	// item so that entries survive the subscriber being removed from
+	// 'subscribers' (e.g. by cancelling its Events() context) while it is
+	// still holding an unacknowledged event. Read and cleared by Drain().
+	outstandingMu lock.Mutex
+	outstanding   map[outstandingKey[T]]string
+
 	// meaningful for releasable resources only
 	refsMu      lock.Mutex
 	refs        uint64
@@ -284,6 +410,94 @@ func (r *resource[T]) Store(ctx context.Context) (Store[T], error) {
 	}).Await(ctx)
 }
 
+// HasSynced returns the resource's current sync state without blocking.
+func (r *resource[T]) HasSynced() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.synchronized
+}
+
+func (r *resource[T]) Get(ctx context.Context, key Key) (item T, exists bool, err error) {
+	store, err := r.Store(ctx)
+	if err != nil {
+		return item, false, err
+	}
+	return store.GetByKey(key)
+}
+
+// outstandingKey identifies an event handed to a subscriber that has not
+// yet been acknowledged with Done().
+type outstandingKey[T k8sRuntime.Object] struct {
+	sub  *subscriber[T]
+	item workItem
+}
+
+func workItemLabel(item workItem) string {
+	switch item := item.(type) {
+	case keyWorkItem:
+		return item.key.String()
+	case syncWorkItem:
+		return "<sync>"
+	case resyncWorkItem:
+		return "<resync>"
+	default:
+		return fmt.Sprintf("%T", item)
+	}
+}
+
+func (r *resource[T]) markOutstanding(sub *subscriber[T], item workItem) {
+	r.outstandingMu.Lock()
+	defer r.outstandingMu.Unlock()
+	if r.outstanding == nil {
+		r.outstanding = make(map[outstandingKey[T]]string)
+	}
+	r.outstanding[outstandingKey[T]{sub, item}] = workItemLabel(item)
+}
+
+func (r *resource[T]) clearOutstanding(sub *subscriber[T], item workItem) {
+	r.outstandingMu.Lock()
+	defer r.outstandingMu.Unlock()
+	delete(r.outstanding, outstandingKey[T]{sub, item})
+}
+
+func (r *resource[T]) outstandingLabels() []string {
+	r.outstandingMu.Lock()
+	defer r.outstandingMu.Unlock()
+	labels := make([]string, 0, len(r.outstanding))
+	for _, label := range r.outstanding {
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// drainPollInterval is how often Drain checks whether all subscribers have
+// stopped and all outstanding events have been acknowledged.
+const drainPollInterval = 20 * time.Millisecond
+
+func (r *resource[T]) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.mu.RLock()
+		numSubscribers := len(r.subscribers)
+		r.mu.RUnlock()
+
+		leaked := r.outstandingLabels()
+		if numSubscribers == 0 && len(leaked) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"resource.Drain: timed out with %d active subscriber(s) and %d unacknowledged event(s): %v",
+				numSubscribers, len(leaked), leaked)
+		case <-ticker.C:
+		}
+	}
+}
+
 func (r *resource[T]) metricEventProcessed(eventKind EventKind, status bool) {
 	if r.opts.metricScope == "" {
 		return
@@ -296,7 +510,7 @@ func (r *resource[T]) metricEventProcessed(eventKind EventKind, status bool) {
 
 	var action string
 	switch eventKind {
-	case Sync:
+	case Sync, Resync:
 		return
 	case Upsert:
 		action = "update"
@@ -307,6 +521,27 @@ func (r *resource[T]) metricEventProcessed(eventKind EventKind, status bool) {
 	metrics.KubernetesEventProcessed.WithLabelValues(r.opts.metricScope, action, result).Inc()
 }
 
+func (r *resource[T]) metricEventDropped(entry workItem) {
+	if r.opts.metricScope == "" {
+		return
+	}
+
+	action := "sync"
+	if _, ok := entry.(keyWorkItem); ok {
+		action = "key"
+	}
+
+	metrics.KubernetesEventDropped.WithLabelValues(r.opts.metricScope, action).Inc()
+}
+
+func (r *resource[T]) metricEventProcessingLatency(kind EventKind, deliveredAt time.Time) {
+	if !r.opts.processingLatency || deliveredAt.IsZero() {
+		return
+	}
+	metrics.KubernetesEventProcessingLatency.WithLabelValues(r.resourceName(), string(kind)).Observe(
+		time.Since(deliveredAt).Seconds())
+}
+
 func (r *resource[T]) metricEventReceived(action string, valid, equal bool) {
 	if r.opts.metricScope == "" {
 		return
@@ -393,6 +628,19 @@ func (r *resource[T]) startWhenNeeded() {
 	}
 }
 
+// Stop cancels the resource's context, which both tells the informer to
+// stop and cancels every still-subscribed Events() consumer's context, and
+// then waits for every goroutine r.wg was handed to (the informer's own
+// run loop, and one per live subscriber) to actually return.
+//
+// Subscriber goroutines close their Events() channel as the very last
+// thing they do before calling r.wg.Done(), so this Wait() only returns
+// once every subscriber's channel has been closed - the informer does not
+// finish stopping out from under a subscriber that is still reading or
+// could still be sent to. Callers that fork their own consumer off of
+// Events() (e.g. into a separate workerpool, as in pkg/k8s/resource/example)
+// still need to stop *that* consumer themselves; this guarantee only
+// covers the resource's own side of the channel.
 func (r *resource[T]) Stop(stopCtx cell.HookContext) error {
 	if r.opts.releasable {
 		// grab the refs lock to avoid a concurrent restart for releasable resource
@@ -408,6 +656,8 @@ func (r *resource[T]) Stop(stopCtx cell.HookContext) error {
 type eventsOpts struct {
 	rateLimiter  workqueue.RateLimiter
 	errorHandler ErrorHandler
+	maxRetries   int
+	subscription *Subscription
 }
 
 type EventsOpt func(*eventsOpts)
@@ -427,6 +677,31 @@ func WithErrorHandler(h ErrorHandler) EventsOpt {
 	}
 }
 
+// WithMaxRetries sets the maximum number of failed attempts at processing an
+// event before it is dropped: the n-th failure drops it, so it is retried at
+// most n-1 times. Once dropped, the event is logged and counted towards the
+// KubernetesEventDropped metric, and the key is forgotten so a subsequent update
+// to it is processed normally rather than being permanently blocked by a single
+// bad event.
+//
+// This takes precedence over the action returned by WithErrorHandler: once a
+// key has failed 'n' times, the event is dropped regardless of what the
+// configured error handler would have otherwise decided.
+func WithMaxRetries(n int) EventsOpt {
+	return func(o *eventsOpts) {
+		o.maxRetries = n
+	}
+}
+
+// WithSubscription gives the caller control over delivery of events to the
+// subscriber through sub's Pause() and Resume() methods. See Subscription
+// for how events occurring while paused are coalesced.
+func WithSubscription(sub *Subscription) EventsOpt {
+	return func(o *eventsOpts) {
+		o.subscription = sub
+	}
+}
+
 func (r *resource[T]) Observe(ctx context.Context, next func(Event[T]), complete func(error)) {
 	stream.FromChannel(r.Events(ctx)).Observe(ctx, next, complete)
 }
@@ -442,13 +717,24 @@ func (r *resource[T]) Observe(ctx context.Context, next func(Event[T]), complete
 // closed when context is cancelled.
 //
 // Options are supported to configure rate limiting of retries
-// (WithRateLimiter), error handling strategy (WithErrorHandler).
+// (WithRateLimiter), error handling strategy (WithErrorHandler) and pausing
+// delivery of events (WithSubscription).
 //
 // By default all errors are retried, the default rate limiter of workqueue
 // package is used and the channel is unbuffered.
 func (r *resource[T]) Events(ctx context.Context, opts ...EventsOpt) <-chan Event[T] {
+	if r.opts.storeOnly {
+		// This resource was constructed with WithStoreOnly(): still start the
+		// informer, as documented, but never allocate a subscription — just
+		// hand back a closed, empty channel.
+		r.markNeeded()
+		out := make(chan Event[T])
+		close(out)
+		return out
+	}
+
 	_, callerFile, callerLine, _ := runtime.Caller(1)
-	debugInfo := fmt.Sprintf("%T.Events() called from %s:%d", r, callerFile, callerLine)
+	debugInfo := fmt.Sprintf("%T(%s).Events() called from %s:%d", r, r.resourceName(), callerFile, callerLine)
 
 	options := eventsOpts{
 		errorHandler: AlwaysRetry, // Default error handling is to always retry.
@@ -469,7 +755,7 @@ func (r *resource[T]) Events(ctx context.Context, opts ...EventsOpt) <-chan Even
 		options:   options,
 		debugInfo: debugInfo,
 		wq: workqueue.NewRateLimitingQueueWithConfig(options.rateLimiter,
-			workqueue.RateLimitingQueueConfig{Name: r.resourceName()}),
+			workqueue.RateLimitingQueueConfig{Name: r.resourceName(), Clock: r.opts.clock}),
 	}
 
 	// Fork a goroutine to process the queued keys and pass them to the subscriber.
@@ -624,11 +910,21 @@ loop:
 			break
 		}
 
+		// Block here while the subscriber is paused, before looking up the
+		// object in the store below. Keys keep being queued as usual while
+		// we wait: since the queue won't add a key that's already pending,
+		// further upserts and deletes of this key are coalesced, and once
+		// we resume the store lookup below observes whatever the latest
+		// state ended up being.
+		s.options.subscription.wait(ctx)
+
 		var event Event[T]
 
 		switch workItem := workItem.(type) {
 		case syncWorkItem:
 			event.Kind = Sync
+		case resyncWorkItem:
+			event.Kind = Resync
 		case keyWorkItem:
 			obj, exists, err := store.GetByKey(workItem.key)
 			if !exists || err != nil {
@@ -648,6 +944,8 @@ loop:
 				event.Key = workItem.key
 				event.Object = obj
 			}
+			event.ResourceVersion = getResourceVersion(event.Object)
+			s.options.subscription.recordResourceVersion(event.ResourceVersion)
 		default:
 			panic(fmt.Sprintf("%T: unknown work item %T", s.r, workItem))
 		}
@@ -656,6 +954,7 @@ loop:
 		// If Done() is not called, a finalizer set on this object will be invoked
 		// which panics. If Done() is called, the finalizer is unset.
 		var eventDoneSentinel = new(bool)
+		var deliveredAt time.Time
 		event.Done = func(err error) {
 			runtime.SetFinalizer(eventDoneSentinel, nil)
 
@@ -666,16 +965,24 @@ loop:
 				lastKnownObjects.DeleteByUID(event.Key, event.Object)
 			}
 
+			s.r.clearOutstanding(s, workItem)
 			s.eventDone(workItem, err)
 
 			s.r.metricEventProcessed(event.Kind, err == nil)
+			s.r.metricEventProcessingLatency(event.Kind, deliveredAt)
 		}
 
 		// Add a finalizer to catch forgotten calls to Done().
 		runtime.SetFinalizer(eventDoneSentinel, doneFinalizer)
 
+		// Mark the event outstanding before handing it off: until Done() is
+		// called (either by the subscriber, or below on context cancellation)
+		// Drain() will consider it unacknowledged.
+		s.r.markOutstanding(s, workItem)
+
 		select {
 		case out <- event:
+			deliveredAt = time.Now()
 		case <-ctx.Done():
 			// Subscriber cancelled or resource is shutting down. We're not requiring
 			// the subscriber to drain the channel, so we're marking the event done here
@@ -706,6 +1013,10 @@ func (s *subscriber[T]) enqueueSync() {
 	s.wq.Add(syncWorkItem{})
 }
 
+func (s *subscriber[T]) enqueueResync() {
+	s.wq.Add(resyncWorkItem{})
+}
+
 func (s *subscriber[T]) enqueueKey(key Key) {
 	s.wq.Add(keyWorkItem{key})
 }
@@ -720,16 +1031,27 @@ func (s *subscriber[T]) eventDone(entry workItem, err error) {
 	if err != nil {
 		numRequeues := s.wq.NumRequeues(entry)
 
-		var action ErrorAction
+		var key Key
 		switch entry := entry.(type) {
 		case syncWorkItem:
-			action = s.options.errorHandler(Key{}, numRequeues, err)
+			key = Key{}
+		case resyncWorkItem:
+			key = Key{}
 		case keyWorkItem:
-			action = s.options.errorHandler(entry.key, numRequeues, err)
+			key = entry.key
 		default:
 			panic(fmt.Sprintf("keyQueue: unhandled entry %T", entry))
 		}
 
+		action := s.options.errorHandler(key, numRequeues, err)
+
+		// A maximum retry count takes precedence: once reached the event is
+		// dropped rather than retried or stopped, so a single permanently
+		// failing key cannot block the subscriber or wedge itself forever.
+		if s.options.maxRetries > 0 && numRequeues+1 >= s.options.maxRetries {
+			action = ErrorActionDrop
+		}
+
 		switch action {
 		case ErrorActionRetry:
 			s.wq.AddRateLimited(entry)
@@ -737,6 +1059,14 @@ func (s *subscriber[T]) eventDone(entry workItem, err error) {
 			s.wq.ShutDown()
 		case ErrorActionIgnore:
 			s.wq.Forget(entry)
+		case ErrorActionDrop:
+			log.WithError(err).
+				WithField(logfields.Resource, s.r.resourceName()).
+				WithField("key", key).
+				WithField("retries", numRequeues+1).
+				Warn("Dropping event after exceeding maximum retries")
+			s.r.metricEventDropped(entry)
+			s.wq.Forget(entry)
 		default:
 			panic(fmt.Sprintf("keyQueue: unknown action %q from error handler %v", action, s.options.errorHandler))
 		}
@@ -803,6 +1133,12 @@ type syncWorkItem struct{}
 
 func (syncWorkItem) isWorkItem() {}
 
+// resyncWorkItem marks that the informer has relisted and thus a 'Resync'
+// event can be emitted to the subscriber.
+type resyncWorkItem struct{}
+
+func (resyncWorkItem) isWorkItem() {}
+
 // keyWorkItem marks work for a specific key. Whether this is an upsert or delete
 // depends on the state of the store at the time this work item is processed.
 type keyWorkItem struct {
@@ -821,17 +1157,54 @@ func (p *wrapperController) Run(stopCh <-chan struct{}) {
 	p.Controller.Run(stopCh)
 }
 
+// relistTrackingListerWatcher wraps a ListerWatcher to detect relists: every
+// List() call after the first one means the reflector had to relist, e.g.
+// because its watch errored out or expired ("too old resource version").
+// onRelist is invoked for every such call, but never for the first (initial)
+// list.
+type relistTrackingListerWatcher struct {
+	cache.ListerWatcher
+	onRelist func()
+
+	listed atomic.Bool
+}
+
+func (w *relistTrackingListerWatcher) List(options metav1.ListOptions) (k8sRuntime.Object, error) {
+	obj, err := w.ListerWatcher.List(options)
+	if err != nil {
+		return obj, err
+	}
+	if !w.listed.CompareAndSwap(false, true) {
+		w.onRelist()
+	}
+	return obj, err
+}
+
 func (r *resource[T]) newInformer() (cache.Indexer, cache.Controller) {
 	clientState := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, r.opts.indexers)
 	opts := cache.DeltaFIFOOptions{KeyFunction: cache.MetaNamespaceKeyFunc, KnownObjects: clientState}
 	fifo := cache.NewDeltaFIFOWithOptions(opts)
 	transformer := r.opts.transform
 	cacheMutationDetector := cache.NewCacheMutationDetector(fmt.Sprintf("%T", r))
+	lw := &relistTrackingListerWatcher{
+		ListerWatcher: r.lw,
+		onRelist: func() {
+			// Enqueue the Resync event right away, rather than from the
+			// Process callback below: a relist that finds no changes queues
+			// no deltas at all, so Process may never run for it, yet
+			// subscribers still need to know the relist happened.
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			for _, sub := range r.subscribers {
+				sub.enqueueResync()
+			}
+		},
+	}
 	cfg := &cache.Config{
 		Queue:            fifo,
-		ListerWatcher:    r.lw,
+		ListerWatcher:    lw,
 		ObjectType:       r.opts.sourceObj(),
-		FullResyncPeriod: 0,
+		FullResyncPeriod: r.opts.resyncPeriod,
 		RetryOnError:     false,
 		Process: func(obj interface{}, isInInitialList bool) error {
 			// Processing of the deltas is done under the resource mutex. This
@@ -894,6 +1267,17 @@ func (r *resource[T]) newInformer() (cache.Indexer, cache.Controller) {
 			return nil
 		},
 	}
+	if r.opts.watchErrorHandler != nil {
+		cfg.WatchErrorHandler = func(_ *cache.Reflector, err error) {
+			switch {
+			case apierrors.IsResourceExpired(err), apierrors.IsGone(err), err == io.EOF, err == io.ErrUnexpectedEOF:
+				// Expected, transient watch closures that client-go retries
+				// silently; not persistent failures worth surfacing.
+			default:
+				r.opts.watchErrorHandler(err)
+			}
+		}
+	}
 	return clientState, &wrapperController{
 		Controller:            cache.New(cfg),
 		cacheMutationDetector: cacheMutationDetector,
@@ -912,6 +1296,15 @@ func getUID(obj k8sRuntime.Object) types.UID {
 	return meta.GetUID()
 }
 
+func getResourceVersion(obj k8sRuntime.Object) string {
+	meta, err := meta.Accessor(obj)
+	if err != nil {
+		// See getUID above for why this panics.
+		panic(fmt.Sprintf("BUG: meta.Accessor() failed on %T: %s", obj, err))
+	}
+	return meta.GetResourceVersion()
+}
+
 func merge[T any](c1, c2 <-chan T) <-chan T {
 	m := make(chan T)
 	go func() {
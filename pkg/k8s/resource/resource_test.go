@@ -17,21 +17,27 @@ import (
 
 	"github.com/cilium/hive/cell"
 	"github.com/cilium/hive/hivetest"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/cilium/cilium/pkg/hive"
 	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
 	"github.com/cilium/cilium/pkg/k8s/resource"
+	slim_corev1 "github.com/cilium/cilium/pkg/k8s/slim/k8s/api/core/v1"
 	"github.com/cilium/cilium/pkg/k8s/utils"
+	"github.com/cilium/cilium/pkg/metrics"
 )
 
 const testTimeout = time.Minute
@@ -253,6 +259,298 @@ func TestResource_WithFakeClient(t *testing.T) {
 	}
 }
 
+func TestResource_Pause(t *testing.T) {
+	var (
+		nodeName = "some-node"
+		node     = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            nodeName,
+				ResourceVersion: "0",
+			},
+		}
+
+		nodes          resource.Resource[*corev1.Node]
+		fakeClient, cs = k8sClient.NewFakeClientset()
+
+		events <-chan resource.Event[*corev1.Node]
+	)
+
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	sub := resource.NewSubscription()
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		nodesResource,
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+			events = nodes.Events(ctx, resource.WithSubscription(sub))
+		}))
+
+	tlog := hivetest.Logger(t)
+	require.NoError(t, hive.Start(tlog, ctx))
+	defer func() {
+		require.NoError(t, hive.Stop(tlog, context.TODO()))
+	}()
+
+	// Drain the initial upsert and sync.
+	ev := <-events
+	require.Equal(t, resource.Upsert, ev.Kind)
+	ev.Done(nil)
+	ev = <-events
+	require.Equal(t, resource.Sync, ev.Kind)
+	ev.Done(nil)
+
+	sub.Pause()
+
+	// Several updates while paused. Since only the key is queued, these
+	// are coalesced into at most one pending event for the node.
+	for i := 1; i <= 5; i++ {
+		node.ObjectMeta.ResourceVersion = strconv.Itoa(i)
+		fakeClient.KubernetesFakeClientset.Tracker().Update(
+			corev1.SchemeGroupVersion.WithResource("nodes"),
+			node.DeepCopy(), "")
+	}
+
+	// And finally a delete, also while paused.
+	fakeClient.KubernetesFakeClientset.Tracker().Delete(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		"", nodeName)
+
+	// No event should be observed while paused.
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event delivered while paused: %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	sub.Resume()
+
+	// Only the final state, the deletion, should be delivered: the
+	// intermediate upserts were coalesced away.
+	ev = <-events
+	require.Equal(t, resource.Delete, ev.Kind)
+	require.Equal(t, nodeName, ev.Key.Name)
+	ev.Done(nil)
+
+	cancel()
+	for range events {
+	}
+}
+
+// TestResource_LastResourceVersion verifies that a Subscription's
+// LastResourceVersion tracks the highest resourceVersion observed across a
+// sequence of upserts, and that it is exposed on the delivered events too.
+func TestResource_LastResourceVersion(t *testing.T) {
+	var (
+		nodeName = "some-node"
+		node     = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            nodeName,
+				ResourceVersion: "10",
+			},
+		}
+
+		nodes          resource.Resource[*corev1.Node]
+		fakeClient, cs = k8sClient.NewFakeClientset()
+
+		events <-chan resource.Event[*corev1.Node]
+	)
+
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	sub := resource.NewSubscription()
+	require.Equal(t, "", sub.LastResourceVersion())
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		nodesResource,
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+			events = nodes.Events(ctx, resource.WithSubscription(sub))
+		}))
+
+	tlog := hivetest.Logger(t)
+	require.NoError(t, hive.Start(tlog, ctx))
+	defer func() {
+		require.NoError(t, hive.Stop(tlog, context.TODO()))
+	}()
+
+	ev := <-events
+	require.Equal(t, resource.Upsert, ev.Kind)
+	require.Equal(t, "10", ev.ResourceVersion)
+	ev.Done(nil)
+	require.Equal(t, "10", sub.LastResourceVersion())
+
+	ev = <-events
+	require.Equal(t, resource.Sync, ev.Kind)
+	require.Equal(t, "", ev.ResourceVersion)
+	ev.Done(nil)
+	require.Equal(t, "10", sub.LastResourceVersion())
+
+	// A sequence of upserts with increasing resourceVersions. Each should
+	// push LastResourceVersion forward.
+	var lastSeen string
+	for _, rv := range []string{"11", "15", "20"} {
+		node.ObjectMeta.ResourceVersion = rv
+		fakeClient.KubernetesFakeClientset.Tracker().Update(
+			corev1.SchemeGroupVersion.WithResource("nodes"),
+			node.DeepCopy(), "")
+
+		ev = <-events
+		require.Equal(t, resource.Upsert, ev.Kind)
+		require.Equal(t, rv, ev.ResourceVersion)
+		ev.Done(nil)
+
+		seen := sub.LastResourceVersion()
+		require.Equal(t, rv, seen)
+		if lastSeen != "" {
+			require.Greater(t, seen, lastSeen)
+		}
+		lastSeen = seen
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+// spyWorkqueueMetricsProvider records the name every workqueue metric was
+// constructed with, so tests can assert that two Resource[T] instances of
+// the same type register under distinct metric series.
+type spyWorkqueueMetricsProvider struct {
+	mu    sync.Mutex
+	names map[string]int
+}
+
+func (p *spyWorkqueueMetricsProvider) record(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.names == nil {
+		p.names = map[string]int{}
+	}
+	p.names[name]++
+}
+
+func (p *spyWorkqueueMetricsProvider) seen(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.names[name] > 0
+}
+
+// noopQueueMetric implements every metric type workqueue.MetricsProvider can
+// hand out (GaugeMetric, CounterMetric, SettableGaugeMetric, HistogramMetric),
+// since none of them are of interest to this test beyond having been created
+// with the right name.
+type noopQueueMetric struct{}
+
+func (noopQueueMetric) Inc()            {}
+func (noopQueueMetric) Dec()            {}
+func (noopQueueMetric) Set(float64)     {}
+func (noopQueueMetric) Observe(float64) {}
+
+func (p *spyWorkqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	p.record(name)
+	return noopQueueMetric{}
+}
+
+func (p *spyWorkqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return noopQueueMetric{}
+}
+
+func (p *spyWorkqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return noopQueueMetric{}
+}
+
+func (p *spyWorkqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return noopQueueMetric{}
+}
+
+func (p *spyWorkqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return noopQueueMetric{}
+}
+
+func (p *spyWorkqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return noopQueueMetric{}
+}
+
+func (p *spyWorkqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return noopQueueMetric{}
+}
+
+var _ workqueue.MetricsProvider = &spyWorkqueueMetricsProvider{}
+
+// TestResource_WithName verifies that resource.WithName lets two Resource[T]
+// instances of the same underlying type be told apart in the per-resource
+// workqueue metrics: without distinct names they would both register under
+// the same default, type-derived series and their depth/latency/etc. metrics
+// would be indistinguishable.
+func TestResource_WithName(t *testing.T) {
+	spy := &spyWorkqueueMetricsProvider{}
+	workqueue.SetProvider(spy)
+	defer workqueue.SetProvider(noopMetricsProviderForRestore{})
+
+	_, cs := k8sClient.NewFakeClientset()
+	lw := utils.ListerWatcherFromTyped[*slim_corev1.PodList](cs.Slim().CoreV1().Pods(""))
+
+	lc := hivetest.Lifecycle(t)
+	defaultNamed := resource.New[*slim_corev1.Pod](lc, lw)
+	frontend := resource.New[*slim_corev1.Pod](lc, lw, resource.WithName("frontend-pods"))
+	backend := resource.New[*slim_corev1.Pod](lc, lw, resource.WithName("backend-pods"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// Allocating the workqueue happens synchronously in Events(), so no need
+	// to wait for the informer to start or sync.
+	defaultNamed.Events(ctx)
+	frontend.Events(ctx)
+	backend.Events(ctx)
+	cancel()
+
+	assert.True(t, spy.seen("pod"), "expected the default, unnamed resource to register under the type name")
+	assert.True(t, spy.seen("frontend-pods"), "expected frontend resource to register under its own name")
+	assert.True(t, spy.seen("backend-pods"), "expected backend resource to register under its own name")
+}
+
+// noopMetricsProviderForRestore is set back on workqueue.SetProvider after
+// TestResource_WithName so it doesn't leak a test spy into later tests.
+type noopMetricsProviderForRestore struct{}
+
+func (noopMetricsProviderForRestore) NewDepthMetric(string) workqueue.GaugeMetric {
+	return noopQueueMetric{}
+}
+func (noopMetricsProviderForRestore) NewAddsMetric(string) workqueue.CounterMetric {
+	return noopQueueMetric{}
+}
+func (noopMetricsProviderForRestore) NewLatencyMetric(string) workqueue.HistogramMetric {
+	return noopQueueMetric{}
+}
+func (noopMetricsProviderForRestore) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return noopQueueMetric{}
+}
+func (noopMetricsProviderForRestore) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return noopQueueMetric{}
+}
+func (noopMetricsProviderForRestore) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return noopQueueMetric{}
+}
+func (noopMetricsProviderForRestore) NewRetriesMetric(string) workqueue.CounterMetric {
+	return noopQueueMetric{}
+}
+
+var _ workqueue.MetricsProvider = noopMetricsProviderForRestore{}
+
 type createsAndDeletesListerWatcher struct {
 	events chan watch.Event
 }
@@ -276,6 +574,206 @@ func (lw *createsAndDeletesListerWatcher) Watch(options metav1.ListOptions) (wat
 var _ cache.ListerWatcher = &createsAndDeletesListerWatcher{}
 var _ watch.Interface = &createsAndDeletesListerWatcher{}
 
+// relistingListerWatcher always lists an empty set of nodes, and lets the
+// test force a relist by closing the current watch: the reflector treats a
+// closed watch as ended and restarts ListAndWatch, which relists.
+type relistingListerWatcher struct {
+	mu        sync.Mutex
+	listCalls int
+	watchCh   chan watch.Event
+}
+
+func (lw *relistingListerWatcher) List(options metav1.ListOptions) (k8sRuntime.Object, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.listCalls++
+	return &corev1.NodeList{}, nil
+}
+
+func (lw *relistingListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.watchCh = make(chan watch.Event)
+	return watch.NewProxyWatcher(lw.watchCh), nil
+}
+
+// forceRelist closes the current watch to simulate it erroring out or
+// expiring, which causes the reflector to relist.
+func (lw *relistingListerWatcher) forceRelist() {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	close(lw.watchCh)
+}
+
+var _ cache.ListerWatcher = &relistingListerWatcher{}
+
+// failingListerWatcher's List always fails with the given error, simulating
+// a persistent failure such as an RBAC rejection or an invalid selector.
+type failingListerWatcher struct {
+	err error
+}
+
+func (lw *failingListerWatcher) List(options metav1.ListOptions) (k8sRuntime.Object, error) {
+	return nil, lw.err
+}
+
+func (lw *failingListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return watch.NewEmptyWatch(), nil
+}
+
+var _ cache.ListerWatcher = &failingListerWatcher{}
+
+func TestResource_WatchErrorHandler(t *testing.T) {
+	var nodes resource.Resource[*corev1.Node]
+
+	wantErr := apierrors.NewForbidden(corev1.Resource("nodes"), "", errors.New("denied by policy"))
+	lw := &failingListerWatcher{err: wantErr}
+
+	var (
+		mu     sync.Mutex
+		gotErr error
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	hive := hive.New(
+		cell.Provide(
+			func(lc cell.Lifecycle) resource.Resource[*corev1.Node] {
+				return resource.New[*corev1.Node](lc, lw, resource.WithWatchErrorHandler(func(err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					gotErr = err
+				}))
+			}),
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	tlog := hivetest.Logger(t)
+	require.NoError(t, hive.Start(tlog, ctx))
+	defer func() {
+		require.NoError(t, hive.Stop(tlog, context.TODO()))
+	}()
+
+	// Start the informer; with an always-failing ListerWatcher the store
+	// never syncs, so the consumer would otherwise only see the absence of
+	// a Sync event.
+	events := nodes.Events(ctx)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return gotErr != nil
+	}, testTimeout, 10*time.Millisecond, "expected watch error handler to be invoked")
+
+	mu.Lock()
+	require.ErrorIs(t, gotErr, wantErr)
+	mu.Unlock()
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestResource_Resync(t *testing.T) {
+	var nodes resource.Resource[*corev1.Node]
+
+	lw := &relistingListerWatcher{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	hive := hive.New(
+		cell.Provide(
+			func(lc cell.Lifecycle) resource.Resource[*corev1.Node] {
+				return resource.New[*corev1.Node](lc, lw)
+			}),
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	tlog := hivetest.Logger(t)
+	require.NoError(t, hive.Start(tlog, ctx))
+
+	events := nodes.Events(ctx)
+
+	ev := <-events
+	require.Equal(t, resource.Sync, ev.Kind, "expected initial Sync event")
+	ev.Done(nil)
+
+	lw.forceRelist()
+
+	ev = <-events
+	require.Equal(t, resource.Resync, ev.Kind, "expected Resync event after relist")
+	ev.Done(nil)
+
+	// A second relist should emit another Resync event: unlike Sync, Resync
+	// is not a one-time event.
+	lw.forceRelist()
+
+	ev = <-events
+	require.Equal(t, resource.Resync, ev.Kind, "expected Resync event after second relist")
+	ev.Done(nil)
+
+	require.NoError(t, hive.Stop(tlog, context.TODO()))
+}
+
+// TestResource_WithResyncPeriod verifies that, with WithResyncPeriod set, the
+// informer periodically redelivers an Upsert event for every object in the
+// store even in the absence of any watch activity.
+func TestResource_WithResyncPeriod(t *testing.T) {
+	var (
+		nodeName = "some-node"
+		node     = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            nodeName,
+				ResourceVersion: "0",
+			},
+		}
+
+		nodes          resource.Resource[*corev1.Node]
+		fakeClient, cs = k8sClient.NewFakeClientset()
+	)
+
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		cell.Provide(func(lc cell.Lifecycle, c k8sClient.Clientset) resource.Resource[*corev1.Node] {
+			lw := utils.ListerWatcherFromTyped[*corev1.NodeList](c.CoreV1().Nodes())
+			return resource.New[*corev1.Node](lc, lw, resource.WithResyncPeriod(10*time.Millisecond))
+		}),
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	tlog := hivetest.Logger(t)
+	require.NoError(t, hive.Start(tlog, ctx))
+
+	events := nodes.Events(ctx)
+
+	// Drain events until the short resync period has redelivered Upsert
+	// for the node at least twice beyond the initial listing, without any
+	// further watch activity.
+	upserts := 0
+	for upserts < 3 {
+		ev := <-events
+		if ev.Kind == resource.Upsert {
+			require.Equal(t, nodeName, ev.Key.Name)
+			upserts++
+		}
+		ev.Done(nil)
+	}
+
+	require.NoError(t, hive.Stop(tlog, context.TODO()))
+}
+
 func TestResource_RepeatedDelete(t *testing.T) {
 	var (
 		nodeName = "some-node"
@@ -450,6 +948,58 @@ func TestResource_CompletionOnStop(t *testing.T) {
 	}
 }
 
+// TestResource_SubscriberDrainedBeforeStopReturns verifies the shutdown
+// ordering documented on resource.Stop: a subscriber forked into its own
+// goroutine (mirroring pkg/k8s/resource/example's workerpool-backed
+// consumer) sees its Events() channel close, and has returned, strictly
+// before hive.Stop (and so resource.Stop) returns. This is what rules out
+// a "send on closed channel" panic if such a consumer were to assume the
+// channel stays open for as long as it is still running.
+func TestResource_SubscriberDrainedBeforeStopReturns(t *testing.T) {
+	var nodes resource.Resource[*corev1.Node]
+
+	hive := hive.New(
+		k8sClient.FakeClientCell,
+		nodesResource,
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	tlog := hivetest.Logger(t)
+	if err := hive.Start(tlog, ctx); err != nil {
+		t.Fatalf("hive.Start failed: %s", err)
+	}
+
+	xs := nodes.Events(ctx)
+
+	// Consume events from a separate goroutine, like example's processLoop
+	// does from inside its own workerpool, signalling on 'done' once the
+	// channel closes.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range xs {
+			ev.Done(nil)
+		}
+	}()
+
+	// Stop the hive to stop the resource. This must not panic, and must
+	// not return until the subscriber goroutine above has observed the
+	// channel close and exited.
+	if err := hive.Stop(tlog, ctx); err != nil {
+		t.Fatalf("hive.Stop failed: %s", err)
+	}
+
+	select {
+	case <-done:
+	default:
+		t.Fatalf("hive.Stop returned before the subscriber's Events() channel was drained and closed")
+	}
+}
+
 func TestResource_WithTransform(t *testing.T) {
 	type StrippedNode = metav1.PartialObjectMetadata
 	var strippedNodes resource.Resource[*StrippedNode]
@@ -516,6 +1066,77 @@ func TestResource_WithTransform(t *testing.T) {
 
 }
 
+// TestResource_WithTransformStripsManagedFields verifies that a transform
+// which nils out ManagedFields, used to reduce memory overhead for large
+// clusters, is applied before objects reach the store and Events().
+func TestResource_WithTransformStripsManagedFields(t *testing.T) {
+	var nodes resource.Resource[*corev1.Node]
+	var fakeClient, cs = k8sClient.NewFakeClientset()
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "node",
+			ResourceVersion: "0",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubelet"},
+			},
+		},
+	}
+
+	stripManagedFields := func(obj *corev1.Node) (*corev1.Node, error) {
+		obj = obj.DeepCopy()
+		obj.ManagedFields = nil
+		return obj, nil
+	}
+
+	hive := hive.New(
+		cell.Provide(
+			func() k8sClient.Clientset { return cs },
+			func(lc cell.Lifecycle, c k8sClient.Clientset) resource.Resource[*corev1.Node] {
+				lw := utils.ListerWatcherFromTyped[*corev1.NodeList](c.CoreV1().Nodes())
+				return resource.New[*corev1.Node](lc, lw, resource.WithTransform(stripManagedFields))
+			}),
+
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	tlog := hivetest.Logger(t)
+	if err := hive.Start(tlog, ctx); err != nil {
+		t.Fatalf("hive.Start failed: %s", err)
+	}
+
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	events := nodes.Events(ctx)
+
+	event := <-events
+	assert.Equal(t, resource.Upsert, event.Kind)
+	assert.Empty(t, event.Object.ManagedFields)
+	event.Done(nil)
+
+	event = <-events
+	assert.Equal(t, resource.Sync, event.Kind)
+	event.Done(nil)
+
+	store, err := nodes.Store(ctx)
+	assert.NoError(t, err)
+	stored, exists, err := store.GetByKey(resource.Key{Name: "node"})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Empty(t, stored.ManagedFields)
+
+	// Stop the hive to stop the resource.
+	if err := hive.Stop(tlog, ctx); err != nil {
+		t.Fatalf("hive.Stop failed: %s", err)
+	}
+}
+
 func TestResource_WithoutIndexers(t *testing.T) {
 	var (
 		node = &corev1.Node{
@@ -712,21 +1333,107 @@ func TestResource_WithIndexers(t *testing.T) {
 	// retrieve the keys of the stored objects whose set of indexed values includes a specific value
 	keys, err := store.IndexKeys(indexName, indexValue)
 	if err != nil {
-		t.Fatalf("unexpected non-nil error from store.IndexKeys(%q, %q), got: %q", indexName, indexValue, err)
+		t.Fatalf("unexpected non-nil error from store.IndexKeys(%q, %q), got: %q", indexName, indexValue, err)
+	}
+	require.Len(t, keys, 1)
+	require.Equal(t, []string{indexValue}, keys)
+
+	// Stop the hive to stop the resource.
+	if err := hive.Stop(tlog, ctx); err != nil {
+		t.Fatalf("hive.Stop failed: %s", err)
+	}
+
+	// No more events should be observed.
+	ev, ok = <-events
+	if ok {
+		t.Fatalf("unexpected event still in channel: %v", ev)
+	}
+}
+
+// TestResource_WithIndexers_ServiceClusterIP demonstrates the common case of
+// looking up Services by their ClusterIP via a custom indexer, rather than by
+// the namespace/name resource.Key.
+func TestResource_WithIndexers_ServiceClusterIP(t *testing.T) {
+	var (
+		services = [...]*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "service-a",
+					Namespace:       "default",
+					ResourceVersion: "0",
+				},
+				Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "service-b",
+					Namespace:       "default",
+					ResourceVersion: "0",
+				},
+				Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.2"},
+			},
+		}
+		serviceResource resource.Resource[*corev1.Service]
+		fakeClient, cs  = k8sClient.NewFakeClientset()
+
+		clusterIPIndex     = "clusterip"
+		clusterIPIndexFunc = func(obj interface{}) ([]string, error) {
+			svc, ok := obj.(*corev1.Service)
+			if !ok {
+				return nil, errors.New("object is not a *corev1.Service")
+			}
+			if svc.Spec.ClusterIP == "" {
+				return nil, nil
+			}
+			return []string{svc.Spec.ClusterIP}, nil
+		}
+	)
+
+	for _, svc := range services {
+		fakeClient.KubernetesFakeClientset.Tracker().Create(
+			corev1.SchemeGroupVersion.WithResource("services"),
+			svc.DeepCopy(), svc.Namespace)
+	}
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		cell.Provide(
+			func(lc cell.Lifecycle, cs k8sClient.Clientset) resource.Resource[*corev1.Service] {
+				lw := utils.ListerWatcherFromTyped[*corev1.ServiceList](cs.CoreV1().Services(""))
+				return resource.New[*corev1.Service](
+					lc, lw,
+					resource.WithIndexers(cache.Indexers{clusterIPIndex: clusterIPIndexFunc}),
+				)
+			},
+		),
+		cell.Invoke(func(r resource.Resource[*corev1.Service]) {
+			serviceResource = r
+		}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	tlog := hivetest.Logger(t)
+	if err := hive.Start(tlog, ctx); err != nil {
+		t.Fatalf("hive.Start failed: %s", err)
+	}
+
+	store, err := serviceResource.Store(ctx)
+	if err != nil {
+		t.Fatalf("unexpected non-nil error from Store(), got: %q", err)
+	}
+
+	found, err := store.ByIndex(clusterIPIndex, "10.0.0.2")
+	if err != nil {
+		t.Fatalf("unexpected non-nil error from store.ByIndex(%q, %q), got: %q", clusterIPIndex, "10.0.0.2", err)
 	}
-	require.Len(t, keys, 1)
-	require.Equal(t, []string{indexValue}, keys)
+	require.Len(t, found, 1)
+	require.Equal(t, "service-b", found[0].Name)
 
-	// Stop the hive to stop the resource.
 	if err := hive.Stop(tlog, ctx); err != nil {
 		t.Fatalf("hive.Stop failed: %s", err)
 	}
-
-	// No more events should be observed.
-	ev, ok = <-events
-	if ok {
-		t.Fatalf("unexpected event still in channel: %v", ev)
-	}
 }
 
 var RetryFiveTimes resource.ErrorHandler = func(key resource.Key, numRetries int, err error) resource.ErrorAction {
@@ -866,6 +1573,261 @@ func TestResource_Retries(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestResource_MaxRetries(t *testing.T) {
+	var (
+		nodes          resource.Resource[*corev1.Node]
+		fakeClient, cs = k8sClient.NewFakeClientset()
+	)
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		cell.Provide(func(lc cell.Lifecycle, c k8sClient.Clientset) resource.Resource[*corev1.Node] {
+			nodesLW := utils.ListerWatcherFromTyped[*corev1.NodeList](c.CoreV1().Nodes())
+			return resource.New[*corev1.Node](lc, nodesLW)
+		}),
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tlog := hivetest.Logger(t)
+	err := hive.Start(tlog, ctx)
+	assert.NoError(t, err)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "max-retries-node",
+			ResourceVersion: "0",
+		},
+	}
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node, "")
+
+	const maxRetries = 3
+
+	eventsCtx, eventsCancel := context.WithCancel(ctx)
+	defer eventsCancel()
+
+	// Fail the upsert event maxRetries times. The errorHandler always asks to
+	// retry, but WithMaxRetries should take precedence once the retry count is
+	// reached and drop the event instead of retrying forever.
+	xs := nodes.Events(eventsCtx,
+		resource.WithRateLimiter(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond)),
+		resource.WithErrorHandler(resource.AlwaysRetry),
+		resource.WithMaxRetries(maxRetries))
+
+	expectedErr := errors.New("always fails")
+	var numUpsertAttempts atomic.Int64
+	var unblocked atomic.Bool
+
+	for ev := range xs {
+		switch ev.Kind {
+		case resource.Sync:
+			ev.Done(nil)
+		case resource.Upsert:
+			if unblocked.Load() {
+				// The key was unblocked after the event was dropped: let a
+				// later upsert of the same key succeed.
+				ev.Done(nil)
+				eventsCancel()
+				continue
+			}
+			n := numUpsertAttempts.Add(1)
+			if n > maxRetries {
+				t.Fatalf("event was attempted more than maxRetries times: %d", n)
+			}
+			ev.Done(expectedErr)
+			if n == maxRetries {
+				// The event should now have been dropped. Unblock the key by
+				// updating the node and confirm a fresh Upsert is delivered.
+				unblocked.Store(true)
+				node.Labels = map[string]string{"updated": "true"}
+				fakeClient.KubernetesFakeClientset.Tracker().Update(
+					corev1.SchemeGroupVersion.WithResource("nodes"),
+					node, "")
+			}
+		case resource.Delete:
+			t.Fatalf("unexpected delete of %s", ev.Key)
+		}
+	}
+
+	assert.Equal(t, int64(maxRetries), numUpsertAttempts.Load(), "expected event to be attempted exactly maxRetries times before being dropped")
+	assert.True(t, unblocked.Load(), "expected the key to be unblocked after the event was dropped")
+
+	err = hive.Stop(tlog, ctx)
+	assert.NoError(t, err)
+}
+
+func TestResource_StoreOnly(t *testing.T) {
+	var (
+		nodes          resource.Resource[*corev1.Node]
+		fakeClient, cs = k8sClient.NewFakeClientset()
+	)
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		cell.Provide(func(lc cell.Lifecycle, c k8sClient.Clientset) resource.Resource[*corev1.Node] {
+			nodesLW := utils.ListerWatcherFromTyped[*corev1.NodeList](c.CoreV1().Nodes())
+			return resource.New[*corev1.Node](lc, nodesLW, resource.WithStoreOnly())
+		}),
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tlog := hivetest.Logger(t)
+	err := hive.Start(tlog, ctx)
+	assert.NoError(t, err)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "store-only-node",
+			ResourceVersion: "0",
+		},
+	}
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node, "")
+
+	// Events() must never deliver anything for a store-only resource: the
+	// returned channel should be closed immediately.
+	xs := nodes.Events(ctx)
+	_, ok := <-xs
+	assert.False(t, ok, "expected Events() channel to be closed in store-only mode")
+
+	// Store() should still work as normal, with the informer running.
+	store, err := nodes.Store(ctx)
+	assert.NoError(t, err)
+	_, exists, err := store.GetByKey(resource.Key{Name: "store-only-node"})
+	assert.NoError(t, err)
+	assert.True(t, exists, "expected node to be present in store")
+
+	err = hive.Stop(tlog, ctx)
+	assert.NoError(t, err)
+}
+
+func TestResource_Get(t *testing.T) {
+	var (
+		nodeName = "some-node"
+		node     = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            nodeName,
+				ResourceVersion: "0",
+			},
+		}
+
+		nodes          resource.Resource[*corev1.Node]
+		fakeClient, cs = k8sClient.NewFakeClientset()
+	)
+
+	// Create the node before anything starts watching, to avoid a race
+	// with the "present" case below.
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		nodesResource,
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	tlog := hivetest.Logger(t)
+
+	// Get() blocks until the store has synchronized, so this exercises the
+	// pre-sync case: the call only returns once the informer has listed the
+	// node created above.
+	err := hive.Start(tlog, ctx)
+	assert.NoError(t, err)
+
+	got, exists, err := nodes.Get(ctx, resource.Key{Name: nodeName})
+	assert.NoError(t, err)
+	assert.True(t, exists, "expected node to be present")
+	assert.Equal(t, nodeName, got.Name)
+
+	_, exists, err = nodes.Get(ctx, resource.Key{Name: "does-not-exist"})
+	assert.NoError(t, err)
+	assert.False(t, exists, "expected absent node to not be found")
+
+	err = hive.Stop(tlog, ctx)
+	assert.NoError(t, err)
+}
+
+// TestResource_HasSynced verifies that HasSynced reflects the informer's
+// sync state without blocking: false before the resource has started, and
+// true once the initial list has completed.
+func TestResource_HasSynced(t *testing.T) {
+	nodeName := "some-node"
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            nodeName,
+			ResourceVersion: "0",
+		},
+	}
+
+	fakeClient, cs := k8sClient.NewFakeClientset()
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	lw := utils.ListerWatcherFromTyped[*corev1.NodeList](cs.CoreV1().Nodes())
+	lc := hivetest.Lifecycle(t)
+	nodes := resource.New[*corev1.Node](lc, lw)
+
+	// Before the informer has been started (by a first call to Events() or
+	// Store()), HasSynced must not block and must report false.
+	assert.False(t, nodes.HasSynced())
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// Get() blocks until the store has synchronized, so once it returns
+	// HasSynced must report true.
+	_, _, err := nodes.Get(ctx, resource.Key{Name: nodeName})
+	assert.NoError(t, err)
+	assert.True(t, nodes.HasSynced())
+}
+
+func TestResource_GetContextCancelled(t *testing.T) {
+	var (
+		nodes resource.Resource[*corev1.Node]
+		_, cs = k8sClient.NewFakeClientset()
+	)
+
+	hive := hive.New(
+		cell.Provide(func() k8sClient.Clientset { return cs }),
+		nodesResource,
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	startCtx, startCancel := context.WithTimeout(context.Background(), testTimeout)
+	defer startCancel()
+	tlog := hivetest.Logger(t)
+	err := hive.Start(tlog, startCtx)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, hive.Stop(tlog, startCtx))
+	}()
+
+	// An already-cancelled context means Get() must return an error
+	// promptly instead of blocking on sync.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = nodes.Get(ctx, resource.Key{Name: "irrelevant"})
+	assert.Error(t, err)
+}
+
 func TestResource_Observe(t *testing.T) {
 	var (
 		nodeName = "some-node"
@@ -1304,6 +2266,53 @@ func TestResource_SkippedDonePanics(t *testing.T) {
 	<-events
 }
 
+func TestResource_Drain(t *testing.T) {
+	var nodes resource.Resource[*corev1.Node]
+
+	hive := hive.New(
+		k8sClient.FakeClientCell,
+		nodesResource,
+		cell.Invoke(func(r resource.Resource[*corev1.Node]) {
+			nodes = r
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	tlog := hivetest.Logger(t)
+	if err := hive.Start(tlog, ctx); err != nil {
+		t.Fatalf("hive.Start failed: %s", err)
+	}
+
+	eventsCtx, eventsCancel := context.WithCancel(ctx)
+	xs := nodes.Events(eventsCtx)
+
+	// A slow consumer that receives the sync event but has not yet
+	// acknowledged it: Drain must block on it and time out.
+	ev := <-xs
+	assert.Equal(t, resource.Sync, ev.Kind)
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer drainCancel()
+	err := nodes.Drain(drainCtx)
+	require.Error(t, err, "expected Drain to time out while an event is unacknowledged")
+	assert.Contains(t, err.Error(), "unacknowledged event")
+
+	// Acknowledge the event and stop the subscriber; Drain should now
+	// return promptly.
+	ev.Done(nil)
+	eventsCancel()
+	<-xs // wait for the channel to close once the subscriber has stopped
+
+	drainCtx2, drainCancel2 := context.WithTimeout(context.Background(), testTimeout)
+	defer drainCancel2()
+	require.NoError(t, nodes.Drain(drainCtx2))
+
+	if err := hive.Stop(tlog, ctx); err != nil {
+		t.Fatalf("hive.Stop failed: %s", err)
+	}
+}
+
 //
 // Helpers
 //
@@ -1314,3 +2323,119 @@ var nodesResource = cell.Provide(
 		return resource.New[*corev1.Node](lc, lw)
 	},
 )
+
+// TestResource_ProcessingLatencyMetric verifies that, when
+// WithProcessingLatencyMetric is set, the time between an event being
+// delivered to a subscriber and the subscriber calling Done() on it is
+// observed in metrics.KubernetesEventProcessingLatency.
+func TestResource_ProcessingLatencyMetric(t *testing.T) {
+	tm, restore := metrics.NewTestMetrics()
+	defer restore()
+
+	_, cs := k8sClient.NewFakeClientset()
+	lw := utils.ListerWatcherFromTyped[*slim_corev1.PodList](cs.Slim().CoreV1().Pods(""))
+
+	lc := hivetest.Lifecycle(t)
+	pods := resource.New[*slim_corev1.Pod](lc, lw, resource.WithName("delayed-pods"), resource.WithProcessingLatencyMetric())
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	events := pods.Events(ctx)
+
+	// The fake clientset starts out empty, so the only event delivered is
+	// the initial sync.
+	ev, ok := <-events
+	require.True(t, ok, "events channel closed unexpectedly")
+	require.Equal(t, resource.Sync, ev.Kind)
+
+	const delay = 50 * time.Millisecond
+	time.Sleep(delay)
+	ev.Done(nil)
+	cancel()
+
+	families, err := tm.Snapshot()
+	require.NoError(t, err)
+
+	sum, count := histogramSumAndCount(t, families, metrics.Namespace+"_kubernetes_event_processing_latency_seconds",
+		prometheus.Labels{"resource": "delayed-pods", "kind": string(resource.Sync)})
+	require.Equal(t, float64(1), count, "expected exactly one observation")
+	require.GreaterOrEqual(t, sum, delay.Seconds(), "expected the observed latency to reflect the delay before Done()")
+}
+
+// histogramSumAndCount returns the sample sum and count of the histogram
+// within families that has the given fully-qualified name and matches
+// labels exactly, failing the test if no such histogram is found.
+func histogramSumAndCount(t *testing.T, families []*dto.MetricFamily, name string, labels prometheus.Labels) (float64, float64) {
+	t.Helper()
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.Metric {
+			got := make(prometheus.Labels, len(m.Label))
+			for _, l := range m.Label {
+				got[l.GetName()] = l.GetValue()
+			}
+			if len(got) != len(labels) {
+				continue
+			}
+			match := true
+			for k, v := range labels {
+				if got[k] != v {
+					match = false
+					break
+				}
+			}
+			if match && m.Histogram != nil {
+				return m.Histogram.GetSampleSum(), float64(m.Histogram.GetSampleCount())
+			}
+		}
+	}
+
+	t.Fatalf("no histogram named %q with labels %v found", name, labels)
+	return 0, 0
+}
+
+// TestResource_WithClock verifies that WithClock threads a clock into the
+// per-subscriber retry workqueue, so that a fake clock can be used to
+// deterministically observe a retried event without waiting on real time.
+func TestResource_WithClock(t *testing.T) {
+	_, cs := k8sClient.NewFakeClientset()
+	lw := utils.ListerWatcherFromTyped[*corev1.NodeList](cs.CoreV1().Nodes())
+
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+
+	lc := hivetest.Lifecycle(t)
+	nodes := resource.New[*corev1.Node](lc, lw, resource.WithClock(fakeClock))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	const retryDelay = 30 * time.Second
+	events := nodes.Events(ctx, resource.WithRateLimiter(workqueue.NewItemExponentialFailureRateLimiter(retryDelay, time.Minute)))
+
+	ev, ok := <-events
+	require.True(t, ok, "events channel closed unexpectedly")
+	require.Equal(t, resource.Sync, ev.Kind)
+	ev.Done(errors.New("forcing a retry"))
+
+	// The retry is scheduled retryDelay into the fake clock's future, so it
+	// must not be delivered yet.
+	select {
+	case <-events:
+		t.Fatalf("retry was delivered before the fake clock advanced")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Step(retryDelay)
+
+	ev, ok = <-events
+	require.True(t, ok, "events channel closed unexpectedly")
+	require.Equal(t, resource.Sync, ev.Kind)
+	ev.Done(nil)
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resource
+
+import (
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// SharedFactory constructs a Resource[T], e.g. a closure over resource.New
+// capturing a cell.Lifecycle and a ListerWatcher. It is only ever invoked by
+// Shared.Resource, and at most once per key.
+type SharedFactory[T k8sRuntime.Object] func() Resource[T]
+
+// Shared memoizes the Resource[T] instances built by a SharedFactory, keyed
+// by an arbitrary caller-chosen key (e.g. a list/watch selector or scope
+// name). Multiple independent consumers asking Shared.Resource for the same
+// key are handed the very same Resource[T], and therefore the single
+// underlying informer it starts, instead of each call to resource.New
+// starting its own. Every consumer still gets its own independent Events
+// subscription, with its own ack/retry queue, from the shared Resource[T] -
+// Shared only decides which Resource[T] instance to hand back, it plays no
+// part in event delivery itself.
+//
+// Shared is safe for concurrent use.
+type Shared[T k8sRuntime.Object] struct {
+	mu        lock.Mutex
+	instances map[string]Resource[T]
+}
+
+// NewShared returns a new, empty Shared[T].
+func NewShared[T k8sRuntime.Object]() *Shared[T] {
+	return &Shared[T]{instances: make(map[string]Resource[T])}
+}
+
+// Resource returns the Resource[T] registered for key, constructing it with
+// factory on the first call for that key. Subsequent calls for the same key
+// return the same instance without invoking factory again.
+func (s *Shared[T]) Resource(key string, factory SharedFactory[T]) Resource[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.instances[key]; ok {
+		return r
+	}
+
+	r := factory()
+	s.instances[key] = r
+	return r
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resource_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cilium/hive/hivetest"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	k8sClient "github.com/cilium/cilium/pkg/k8s/client"
+	"github.com/cilium/cilium/pkg/k8s/resource"
+	"github.com/cilium/cilium/pkg/k8s/utils"
+)
+
+// TestShared_Resource verifies that Shared[T].Resource only invokes the
+// factory once per key, handing back the same Resource[T] (and thus the
+// same underlying informer) to every caller asking for that key, while
+// still letting each of them subscribe independently via Events.
+func TestShared_Resource(t *testing.T) {
+	nodeName := "shared-node"
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName, ResourceVersion: "0"},
+	}
+
+	fakeClient, cs := k8sClient.NewFakeClientset()
+	fakeClient.KubernetesFakeClientset.Tracker().Create(
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+		node.DeepCopy(), "")
+
+	lc := hivetest.Lifecycle(t)
+	lw := utils.ListerWatcherFromTyped[*corev1.NodeList](cs.CoreV1().Nodes())
+
+	var factoryCalls atomic.Int32
+	factory := func() resource.Resource[*corev1.Node] {
+		factoryCalls.Add(1)
+		return resource.New[*corev1.Node](lc, lw)
+	}
+
+	shared := resource.NewShared[*corev1.Node]()
+
+	r1 := shared.Resource("nodes", factory)
+	r2 := shared.Resource("nodes", factory)
+	require.Same(t, r1, r2, "expected the same Resource[T] instance for the same key")
+	require.EqualValues(t, 1, factoryCalls.Load(), "factory should only be invoked once per key")
+
+	r3 := shared.Resource("other", factory)
+	require.NotSame(t, r1, r3, "expected a distinct Resource[T] instance for a different key")
+	require.EqualValues(t, 2, factoryCalls.Load())
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// Two independent subscribers off the one shared Resource[T] must each
+	// see the full event stream.
+	events1 := r1.Events(ctx)
+	events2 := r1.Events(ctx)
+
+	for _, events := range []<-chan resource.Event[*corev1.Node]{events1, events2} {
+		ev := <-events
+		require.Equal(t, resource.Upsert, ev.Kind)
+		ev.Done(nil)
+		ev = <-events
+		require.Equal(t, resource.Sync, ev.Kind)
+		ev.Done(nil)
+	}
+}
@@ -49,6 +49,25 @@ type typedStore[T k8sRuntime.Object] struct {
 
 var _ Store[*corev1.Node] = &typedStore[*corev1.Node]{}
 
+// NewStore constructs an already-synced, in-memory Store containing objs.
+// This is meant for unit tests of Store consumers that would otherwise need
+// to stand up a fake clientset and informer just to obtain a Store: the
+// returned Store implements the full interface, including ByIndex/IndexKeys
+// (which always return an error, since no indexers are configured) and a
+// no-op Release.
+func NewStore[T k8sRuntime.Object](objs []T) Store[T] {
+	indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	for _, obj := range objs {
+		if err := indexer.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	return &typedStore[T]{
+		store:   indexer,
+		release: func() {},
+	}
+}
+
 func (s *typedStore[T]) List() []T {
 	items := s.store.List()
 	result := make([]T, len(items))
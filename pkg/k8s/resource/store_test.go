@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cilium/cilium/pkg/k8s/resource"
+)
+
+func TestNewStore(t *testing.T) {
+	pod1 := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-a"}}
+	pod2 := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-b"}}
+
+	store := resource.NewStore[*corev1.Pod]([]*corev1.Pod{pod1, pod2})
+
+	// Release is a no-op but must not panic.
+	defer store.Release()
+
+	assert.ElementsMatch(t, []*corev1.Pod{pod1, pod2}, store.List())
+
+	got, exists, err := store.Get(pod1)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, pod1, got)
+
+	got, exists, err = store.GetByKey(resource.Key{Namespace: "ns-b", Name: "pod-2"})
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, pod2, got)
+
+	_, exists, err = store.GetByKey(resource.Key{Namespace: "ns-a", Name: "missing"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	var keys []resource.Key
+	for it := store.IterKeys(); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	assert.ElementsMatch(t, []resource.Key{
+		{Namespace: "ns-a", Name: "pod-1"},
+		{Namespace: "ns-b", Name: "pod-2"},
+	}, keys)
+
+	// No indexers are configured, so indexed lookups fail with an error
+	// and no results.
+	byIndex, err := store.ByIndex("namespace", "ns-a")
+	require.Error(t, err)
+	assert.Nil(t, byIndex)
+
+	assert.NotNil(t, store.CacheStore())
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package resource
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// Subscription is a handle for controlling event delivery of a subscription
+// obtained via Events(WithSubscription(sub)). It is safe for concurrent use.
+//
+// While paused, the underlying resource keeps tracking upserts and deletes
+// as usual, but delivery to the subscriber's channel is held back. Since
+// only object keys are queued for delivery and a key already queued is not
+// queued again, events for the same key that occur while paused are
+// coalesced: only the latest state of the object (including its deletion)
+// is delivered once Resume() is called.
+type Subscription struct {
+	mu       lock.Mutex
+	paused   bool
+	resumeCh chan struct{}
+
+	lastResourceVersion    uint64
+	lastResourceVersionStr string
+}
+
+// NewSubscription returns a new Subscription, initially not paused.
+func NewSubscription() *Subscription {
+	return &Subscription{resumeCh: make(chan struct{})}
+}
+
+// Pause holds back delivery of events to the subscriber until Resume is
+// called.
+func (s *Subscription) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+// Resume resumes delivery of events that were held back by Pause.
+func (s *Subscription) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		s.paused = false
+		close(s.resumeCh)
+		s.resumeCh = make(chan struct{})
+	}
+}
+
+// wait blocks while the subscription is paused, returning early if ctx is
+// cancelled. It is a no-op on a nil Subscription, which is the case when
+// the subscriber did not ask for one via WithSubscription.
+func (s *Subscription) wait(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	for {
+		s.mu.Lock()
+		if !s.paused {
+			s.mu.Unlock()
+			return
+		}
+		resumeCh := s.resumeCh
+		s.mu.Unlock()
+
+		select {
+		case <-resumeCh:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recordResourceVersion updates the latest observed resource version if rv
+// is numerically greater than the one already recorded. It is a no-op on a
+// nil Subscription, and ignores empty or non-numeric resource versions
+// (e.g. the empty ResourceVersion of Sync and Resync events).
+func (s *Subscription) recordResourceVersion(rv string) {
+	if s == nil || rv == "" {
+		return
+	}
+	v, err := strconv.ParseUint(rv, 10, 64)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v > s.lastResourceVersion {
+		s.lastResourceVersion = v
+		s.lastResourceVersionStr = rv
+	}
+}
+
+// LastResourceVersion returns the numerically highest apiserver
+// resourceVersion among the events observed by this subscription, or the
+// empty string if none has been observed yet. Safe to call concurrently with event delivery.
+func (s *Subscription) LastResourceVersion() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResourceVersionStr
+}
@@ -156,6 +156,22 @@ func (fr *fakeResource[T]) Store(context.Context) (resource.Store[T], error) {
 	return &fakeStore[T]{}, nil
 }
 
+func (fr *fakeResource[T]) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (fr *fakeResource[T]) HasSynced() bool {
+	return true
+}
+
+func (fr *fakeResource[T]) Get(ctx context.Context, key resource.Key) (item T, exists bool, err error) {
+	store, err := fr.Store(ctx)
+	if err != nil {
+		return item, false, err
+	}
+	return store.GetByKey(key)
+}
+
 func blueNode() *v2.CiliumNode {
 	return &v2.CiliumNode{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// APILimiterStats is a snapshot of the summary statistics reported for a
+// single API limiter. It mirrors the subset of rate.MetricsValues that
+// APILimiterCollector exposes.
+type APILimiterStats struct {
+	MeanWaitDuration            float64
+	MinWaitDuration             float64
+	MaxWaitDuration             float64
+	MeanProcessingDuration      float64
+	EstimatedProcessingDuration float64
+	CurrentRequestsInFlight     float64
+	ParallelRequests            float64
+	Limit                       float64
+	Burst                       float64
+}
+
+// APILimiterCollector is a prometheus.Collector which exposes the summary
+// statistics of a single API limiter as individual gauges, e.g.
+// cilium_api_limiter_wait_duration_seconds_mean, rather than a single gauge
+// vector keyed by an "api_call" and "value" label pair. Baking the
+// statistic into the metric name instead of a label keeps the number of
+// exported series proportional to the number of API limiters rather than
+// to the product of API limiters and statistics.
+type APILimiterCollector struct {
+	mu    lock.Mutex
+	stats APILimiterStats
+
+	waitDurationMean            *prometheus.Desc
+	waitDurationMin             *prometheus.Desc
+	waitDurationMax             *prometheus.Desc
+	processingDurationMean      *prometheus.Desc
+	processingDurationEstimated *prometheus.Desc
+	requestsInFlight            *prometheus.Desc
+	requestsLimit               *prometheus.Desc
+	rateLimit                   *prometheus.Desc
+	rateBurst                   *prometheus.Desc
+}
+
+// NewAPILimiterCollector returns an APILimiterCollector for the API limiter
+// named name. The returned collector reports a stale (zero-valued) snapshot
+// until Update is called at least once, which callers are expected to do
+// from their rate.MetricsObserver implementation.
+func NewAPILimiterCollector(name string) *APILimiterCollector {
+	constLabels := prometheus.Labels{"api_call": name}
+
+	return &APILimiterCollector{
+		waitDurationMean: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "wait_duration_seconds_mean"),
+			"Mean wait time for api calls", nil, constLabels,
+		),
+		waitDurationMin: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "wait_duration_seconds_min"),
+			"Minimum wait time for api calls", nil, constLabels,
+		),
+		waitDurationMax: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "wait_duration_seconds_max"),
+			"Maximum wait time for api calls", nil, constLabels,
+		),
+		processingDurationMean: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "processing_duration_seconds_mean"),
+			"Mean processing time of api call", nil, constLabels,
+		),
+		processingDurationEstimated: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "processing_duration_seconds_estimated"),
+			"Estimated processing time of api call", nil, constLabels,
+		),
+		requestsInFlight: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "requests_in_flight"),
+			"Current requests in flight", nil, constLabels,
+		),
+		requestsLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "requests_in_flight_limit"),
+			"Limit on the number of requests in flight", nil, constLabels,
+		),
+		rateLimit: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "rate_limit"),
+			"Current rate limiting configuration", nil, constLabels,
+		),
+		rateBurst: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, SubsystemAPILimiter, "rate_limit_burst"),
+			"Current rate limiting burst configuration", nil, constLabels,
+		),
+	}
+}
+
+// Update replaces the snapshot of statistics reported on the next scrape.
+func (c *APILimiterCollector) Update(stats APILimiterStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = stats
+}
+
+func (c *APILimiterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.waitDurationMean
+	ch <- c.waitDurationMin
+	ch <- c.waitDurationMax
+	ch <- c.processingDurationMean
+	ch <- c.processingDurationEstimated
+	ch <- c.requestsInFlight
+	ch <- c.requestsLimit
+	ch <- c.rateLimit
+	ch <- c.rateBurst
+}
+
+func (c *APILimiterCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	stats := c.stats
+	c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.waitDurationMean, prometheus.GaugeValue, stats.MeanWaitDuration)
+	ch <- prometheus.MustNewConstMetric(c.waitDurationMin, prometheus.GaugeValue, stats.MinWaitDuration)
+	ch <- prometheus.MustNewConstMetric(c.waitDurationMax, prometheus.GaugeValue, stats.MaxWaitDuration)
+	ch <- prometheus.MustNewConstMetric(c.processingDurationMean, prometheus.GaugeValue, stats.MeanProcessingDuration)
+	ch <- prometheus.MustNewConstMetric(c.processingDurationEstimated, prometheus.GaugeValue, stats.EstimatedProcessingDuration)
+	ch <- prometheus.MustNewConstMetric(c.requestsInFlight, prometheus.GaugeValue, stats.CurrentRequestsInFlight)
+	ch <- prometheus.MustNewConstMetric(c.requestsLimit, prometheus.GaugeValue, stats.ParallelRequests)
+	ch <- prometheus.MustNewConstMetric(c.rateLimit, prometheus.GaugeValue, stats.Limit)
+	ch <- prometheus.MustNewConstMetric(c.rateBurst, prometheus.GaugeValue, stats.Burst)
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/metrics/metric"
+)
+
+// TestAPILimiterCollectorSeriesCount verifies that, once an API limiter
+// stops being used, the shared "api_call","value" gauge vectors keep
+// exposing its stale series forever (nothing ever calls
+// DeleteLabelValues for it), while an APILimiterCollector only ever
+// reports series for the limiter it was constructed for, so dropping it
+// from the registry removes its series entirely. For the same set of
+// currently active limiters, the collector scheme therefore reports fewer
+// series than the vector scheme has accumulated over time.
+func TestAPILimiterCollectorSeriesCount(t *testing.T) {
+	waitDuration := newGaugeVec(metric.GaugeOpts{
+		ConfigName: Namespace + "_" + SubsystemAPILimiter + "_wait_duration_seconds_legacy_test",
+		Namespace:  Namespace, Subsystem: SubsystemAPILimiter, Name: "wait_duration_seconds_legacy_test",
+		Help: "Test-only copy of the legacy APILimiterWaitDuration gauge vector",
+	}, []string{"api_call", "value"})
+	processingDuration := newGaugeVec(metric.GaugeOpts{
+		ConfigName: Namespace + "_" + SubsystemAPILimiter + "_processing_duration_seconds_legacy_test",
+		Namespace:  Namespace, Subsystem: SubsystemAPILimiter, Name: "processing_duration_seconds_legacy_test",
+		Help: "Test-only copy of the legacy APILimiterProcessingDuration gauge vector",
+	}, []string{"api_call", "value"})
+	requestsInFlight := newGaugeVec(metric.GaugeOpts{
+		ConfigName: Namespace + "_" + SubsystemAPILimiter + "_requests_in_flight_legacy_test",
+		Namespace:  Namespace, Subsystem: SubsystemAPILimiter, Name: "requests_in_flight_legacy_test",
+		Help: "Test-only copy of the legacy APILimiterRequestsInFlight gauge vector",
+	}, []string{"api_call", "value"})
+	rateLimit := newGaugeVec(metric.GaugeOpts{
+		ConfigName: Namespace + "_" + SubsystemAPILimiter + "_rate_limit_legacy_test",
+		Namespace:  Namespace, Subsystem: SubsystemAPILimiter, Name: "rate_limit_legacy_test",
+		Help: "Test-only copy of the legacy APILimiterRateLimit gauge vector",
+	}, []string{"api_call", "value"})
+
+	setLegacyValues := func(apiCall string) {
+		waitDuration.WithLabelValues(apiCall, "mean").Set(0.1)
+		waitDuration.WithLabelValues(apiCall, "min").Set(0.01)
+		waitDuration.WithLabelValues(apiCall, "max").Set(0.5)
+		processingDuration.WithLabelValues(apiCall, "mean").Set(0.2)
+		processingDuration.WithLabelValues(apiCall, "estimated").Set(0.3)
+		requestsInFlight.WithLabelValues(apiCall, "in-flight").Set(1)
+		requestsInFlight.WithLabelValues(apiCall, "limit").Set(4)
+		rateLimit.WithLabelValues(apiCall, "limit").Set(10)
+		rateLimit.WithLabelValues(apiCall, "burst").Set(20)
+	}
+
+	// "retired-call" was used in the past and is no longer an active API
+	// limiter, but its series are never cleaned up from the shared vectors.
+	setLegacyValues("retired-call")
+	// "get-endpoints" is the only currently active limiter.
+	setLegacyValues("get-endpoints")
+
+	legacySeries := countSeries(t, waitDuration, processingDuration, requestsInFlight, rateLimit)
+	require.Equal(t, 18, legacySeries)
+
+	// The collector scheme exposes only currently registered limiters:
+	// "retired-call" simply has no collector registered anymore.
+	collector := NewAPILimiterCollector("get-endpoints")
+	collector.Update(APILimiterStats{
+		MeanWaitDuration:            0.1,
+		MinWaitDuration:             0.01,
+		MaxWaitDuration:             0.5,
+		MeanProcessingDuration:      0.2,
+		EstimatedProcessingDuration: 0.3,
+		CurrentRequestsInFlight:     1,
+		ParallelRequests:            4,
+		Limit:                       10,
+		Burst:                       20,
+	})
+
+	collectorSeries := countSeries(t, collector)
+	require.Equal(t, 9, collectorSeries)
+
+	require.Less(t, collectorSeries, legacySeries)
+}
+
+func countSeries(t *testing.T, cs ...prometheus.Collector) int {
+	t.Helper()
+
+	reg := prometheus.NewPedanticRegistry()
+	for _, c := range cs {
+		require.NoError(t, reg.Register(c))
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var n int
+	for _, f := range families {
+		n += len(f.Metric)
+	}
+	return n
+}
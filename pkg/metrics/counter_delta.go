@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// CounterDeltaTracker computes, for any number of counters, the amount by
+// which each one has increased since the previous call to Delta for that
+// same counter. It is intended for debug endpoints that want a rate-like
+// signal (e.g. "drops in the last interval") without having to wire up a
+// separate recording rule.
+//
+// A CounterDeltaTracker is safe for concurrent use.
+type CounterDeltaTracker struct {
+	mu   lock.Mutex
+	last map[prometheus.Counter]float64
+}
+
+// NewCounterDeltaTracker returns a new, empty CounterDeltaTracker.
+func NewCounterDeltaTracker() *CounterDeltaTracker {
+	return &CounterDeltaTracker{last: make(map[prometheus.Counter]float64)}
+}
+
+// Delta returns the amount by which m has increased since the previous call
+// to Delta for m. The first call for a given counter returns its current
+// value, as there is no prior observation to diff against.
+//
+// If m's current value is lower than the last observed value, the counter is
+// assumed to have been reset (e.g. the process restarted), and Delta returns
+// the current value rather than a negative number.
+func (t *CounterDeltaTracker) Delta(m prometheus.Counter) float64 {
+	current := GetCounterValue(m)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last, ok := t.last[m]
+	t.last[m] = current
+
+	if !ok || current < last {
+		return current
+	}
+	return current - last
+}
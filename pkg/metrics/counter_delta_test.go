@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCounter implements prometheus.Counter with a directly settable value,
+// so that tests can simulate a counter resetting to a lower value, which a
+// real prometheus.Counter cannot do via its exported API.
+type fakeCounter struct {
+	prometheus.Counter
+	value float64
+}
+
+func (f *fakeCounter) Write(pm *dto.Metric) error {
+	v := f.value
+	pm.Counter = &dto.Counter{Value: &v}
+	return nil
+}
+
+func TestCounterDeltaTracker(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test_counter_delta_tracker",
+	})
+
+	tracker := NewCounterDeltaTracker()
+
+	// First call has no prior observation, so the delta is the current value.
+	counter.Add(5)
+	require.Equal(t, 5.0, tracker.Delta(counter))
+
+	// Subsequent calls return the increase since the last call.
+	counter.Add(3)
+	require.Equal(t, 3.0, tracker.Delta(counter))
+
+	require.Equal(t, 0.0, tracker.Delta(counter))
+
+	// A counter whose value drops below the last observed value (e.g. the
+	// process restarted and the series started over from zero) must be
+	// treated as a reset rather than yielding a negative delta.
+	reset := &fakeCounter{value: 8}
+	tracker2 := NewCounterDeltaTracker()
+	require.Equal(t, 8.0, tracker2.Delta(reset)) // establish a baseline value
+
+	reset.value = 2
+	require.Equal(t, 2.0, tracker2.Delta(reset))
+
+	reset.value = 5
+	require.Equal(t, 3.0, tracker2.Delta(reset))
+
+	// Reuse the same tracker for an independent counter to confirm it
+	// doesn't interfere with reset's baseline.
+	other := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test_counter_delta_tracker_other",
+	})
+	other.Add(10)
+	require.Equal(t, 10.0, tracker2.Delta(other))
+	require.Equal(t, 0.0, tracker2.Delta(reset))
+}
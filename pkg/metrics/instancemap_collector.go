@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	ipamTypes "github.com/cilium/cilium/pkg/ipam/types"
+)
+
+type instanceMapCollector struct {
+	instanceMap *ipamTypes.InstanceMap
+
+	numInstancesDesc *prometheus.Desc
+	numAddressesDesc *prometheus.Desc
+}
+
+// NewInstanceMapCollector returns a prometheus.Collector which, on every
+// scrape, reads the number of instances and addresses tracked by m and
+// exposes them as gauges labelled with the given const labels. Unlike
+// IPAMCapacity, which is Set() periodically from a background loop, the
+// values here are computed lazily, directly from the InstanceMap, so there
+// is no need to keep a cache in sync with it.
+func NewInstanceMapCollector(m *ipamTypes.InstanceMap, labels map[string]string) prometheus.Collector {
+	return &instanceMapCollector{
+		instanceMap: m,
+		numInstancesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "", "ipam_instancemap_instances"),
+			"Number of instances tracked in the IPAM InstanceMap",
+			nil, labels,
+		),
+		numAddressesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "", "ipam_instancemap_addresses"),
+			"Number of addresses tracked in the IPAM InstanceMap",
+			nil, labels,
+		),
+	}
+}
+
+func (c *instanceMapCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.numInstancesDesc
+	ch <- c.numAddressesDesc
+}
+
+func (c *instanceMapCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.numInstancesDesc, prometheus.GaugeValue, float64(c.instanceMap.NumInstances()))
+	ch <- prometheus.MustNewConstMetric(c.numAddressesDesc, prometheus.GaugeValue, float64(c.instanceMap.NumAddresses()))
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	ipamTypes "github.com/cilium/cilium/pkg/ipam/types"
+)
+
+// fakeInterface is a minimal ipamTypes.Interface implementation used to
+// populate an InstanceMap for TestInstanceMapCollector.
+type fakeInterface struct {
+	id        string
+	typ       string
+	addresses []string
+}
+
+func (f *fakeInterface) InterfaceID() string {
+	return f.id
+}
+
+func (f *fakeInterface) InterfaceType() string {
+	return f.typ
+}
+
+func (f *fakeInterface) ForeachAddress(instanceID string, fn ipamTypes.AddressIterator) error {
+	for _, ip := range f.addresses {
+		if err := fn(instanceID, f.id, ip, "", ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeInterface) DeepCopyInterface() ipamTypes.Interface {
+	addresses := make([]string, len(f.addresses))
+	copy(addresses, f.addresses)
+	return &fakeInterface{id: f.id, typ: f.typ, addresses: addresses}
+}
+
+func TestInstanceMapCollector(t *testing.T) {
+	m := ipamTypes.NewInstanceMap()
+	m.Update("i-1", ipamTypes.InterfaceRevision{Resource: &fakeInterface{id: "eni-1", addresses: []string{"1.1.1.1", "2.2.2.2"}}})
+	m.Update("i-2", ipamTypes.InterfaceRevision{Resource: &fakeInterface{id: "eni-2", addresses: []string{"3.3.3.3"}}})
+
+	collector := NewInstanceMapCollector(m, map[string]string{"allocator": "test"})
+
+	expected := `
+		# HELP cilium_ipam_instancemap_addresses Number of addresses tracked in the IPAM InstanceMap
+		# TYPE cilium_ipam_instancemap_addresses gauge
+		cilium_ipam_instancemap_addresses{allocator="test"} 3
+		# HELP cilium_ipam_instancemap_instances Number of instances tracked in the IPAM InstanceMap
+		# TYPE cilium_ipam_instancemap_instances gauge
+		cilium_ipam_instancemap_instances{allocator="test"} 2
+	`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	// Updating the underlying InstanceMap must be reflected on the next scrape
+	// without re-registering the collector, since Collect() reads it lazily.
+	m.Update("i-3", ipamTypes.InterfaceRevision{Resource: &fakeInterface{id: "eni-3", addresses: []string{"4.4.4.4"}}})
+
+	updated := `
+		# HELP cilium_ipam_instancemap_addresses Number of addresses tracked in the IPAM InstanceMap
+		# TYPE cilium_ipam_instancemap_addresses gauge
+		cilium_ipam_instancemap_addresses{allocator="test"} 4
+		# HELP cilium_ipam_instancemap_instances Number of instances tracked in the IPAM InstanceMap
+		# TYPE cilium_ipam_instancemap_instances gauge
+		cilium_ipam_instancemap_instances{allocator="test"} 3
+	`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(updated)))
+}
@@ -91,10 +91,11 @@ func (cv *counterVec) Opts() metricpkg.Opts { return metricpkg.Opts{} }
 
 type observer struct{}
 
-func (o *observer) Observe(float64)      {}
-func (o *observer) IsEnabled() bool      { return false }
-func (o *observer) SetEnabled(bool)      {}
-func (o *observer) Opts() metricpkg.Opts { return metricpkg.Opts{} }
+func (o *observer) Observe(float64)                               {}
+func (o *observer) ObserveWithExemplar(float64, prometheus.Labels) {}
+func (o *observer) IsEnabled() bool                               { return false }
+func (o *observer) SetEnabled(bool)                               {}
+func (o *observer) Opts() metricpkg.Opts                          { return metricpkg.Opts{} }
 
 // Histogram
 
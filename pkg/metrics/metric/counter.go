@@ -8,14 +8,18 @@ import (
 	dto "github.com/prometheus/client_model/go"
 )
 
-func NewCounter(opts CounterOpts) Counter {
-	return &counter{
+func NewCounter(opts CounterOpts, options ...Option) Counter {
+	c := &counter{
 		Counter: prometheus.NewCounter(opts.toPrometheus()),
 		metric: metric{
 			enabled: !opts.Disabled,
 			opts:    Opts(opts),
 		},
 	}
+	for _, o := range options {
+		o(&c.metric)
+	}
+	return c
 }
 
 type Counter interface {
@@ -30,9 +34,20 @@ type counter struct {
 	metric
 }
 
+func (c *counter) Describe(descs chan<- *prometheus.Desc) {
+	c.Counter.Describe(descs)
+	if d := c.aliasDesc(); d != nil {
+		descs <- d
+	}
+}
+
 func (c *counter) Collect(metricChan chan<- prometheus.Metric) {
-	if c.enabled {
-		c.Counter.Collect(metricChan)
+	if !c.enabled {
+		return
+	}
+	c.Counter.Collect(metricChan)
+	if d := c.aliasDesc(); d != nil {
+		metricChan <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, c.Get())
 	}
 }
 
@@ -25,3 +25,30 @@ func TestCounterWithLabels(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, ms, 4)
 }
+
+func TestCounterWithAlias(t *testing.T) {
+	c := NewCounter(CounterOpts{
+		Namespace: "cilium",
+		Subsystem: "subsystem",
+		Name:      "test",
+		Help:      "A test counter",
+	}, WithAlias("cilium_old_subsystem_test"))
+	c.Add(5)
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(c)
+
+	families, err := r.Gather()
+	assert.NoError(t, err)
+
+	byName := make(map[string]float64, len(families))
+	for _, mf := range families {
+		assert.Len(t, mf.Metric, 1)
+		byName[mf.GetName()] = mf.Metric[0].GetCounter().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{
+		"cilium_subsystem_test":     5,
+		"cilium_old_subsystem_test": 5,
+	}, byName)
+}
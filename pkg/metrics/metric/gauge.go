@@ -8,14 +8,18 @@ import (
 	dto "github.com/prometheus/client_model/go"
 )
 
-func NewGauge(opts GaugeOpts) Gauge {
-	return &gauge{
+func NewGauge(opts GaugeOpts, options ...Option) Gauge {
+	g := &gauge{
 		Gauge: prometheus.NewGauge(opts.toPrometheus()),
 		metric: metric{
 			enabled: !opts.Disabled,
 			opts:    Opts(opts),
 		},
 	}
+	for _, o := range options {
+		o(&g.metric)
+	}
+	return g
 }
 
 type Gauge interface {
@@ -30,9 +34,20 @@ type gauge struct {
 	metric
 }
 
+func (g *gauge) Describe(descs chan<- *prometheus.Desc) {
+	g.Gauge.Describe(descs)
+	if d := g.aliasDesc(); d != nil {
+		descs <- d
+	}
+}
+
 func (g *gauge) Collect(metricChan chan<- prometheus.Metric) {
-	if g.enabled {
-		g.Gauge.Collect(metricChan)
+	if !g.enabled {
+		return
+	}
+	g.Gauge.Collect(metricChan)
+	if d := g.aliasDesc(); d != nil {
+		metricChan <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, g.Get())
 	}
 }
 
@@ -25,3 +25,30 @@ func TestGaugeWithLabels(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, ms, 4)
 }
+
+func TestGaugeWithAlias(t *testing.T) {
+	g := NewGauge(GaugeOpts{
+		Namespace: "cilium",
+		Subsystem: "subsystem",
+		Name:      "test",
+		Help:      "A test gauge",
+	}, WithAlias("cilium_old_subsystem_test"))
+	g.Set(7)
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(g)
+
+	families, err := r.Gather()
+	assert.NoError(t, err)
+
+	byName := make(map[string]float64, len(families))
+	for _, mf := range families {
+		assert.Len(t, mf.Metric, 1)
+		byName[mf.GetName()] = mf.Metric[0].GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{
+		"cilium_subsystem_test":     7,
+		"cilium_old_subsystem_test": 7,
+	}, byName)
+}
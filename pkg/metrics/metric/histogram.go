@@ -51,6 +51,13 @@ func (h *histogram) Observe(val float64) {
 type Observer interface {
 	prometheus.Observer
 	WithMetadata
+
+	// ObserveWithExemplar behaves like Observe, but additionally attaches
+	// the given labels as an exemplar if the underlying Observer supports
+	// exemplars (e.g. a Histogram backed by Prometheus client_golang, which
+	// implements prometheus.ExemplarObserver). If it doesn't, this is
+	// equivalent to Observe and the exemplar labels are discarded.
+	ObserveWithExemplar(val float64, exemplar prometheus.Labels)
 }
 
 type observer struct {
@@ -71,6 +78,21 @@ func (o *observer) Observe(val float64) {
 	}
 }
 
+// ObserveWithExemplar behaves like Observe, but additionally attaches
+// exemplar to the observation if the wrapped prometheus.Observer supports
+// exemplars. This lets a latency spike be correlated back to, e.g., the
+// trace that produced it.
+func (o *observer) ObserveWithExemplar(val float64, exemplar prometheus.Labels) {
+	if !o.enabled {
+		return
+	}
+	if eo, ok := o.Observer.(prometheus.ExemplarObserver); ok {
+		eo.ObserveWithExemplar(val, exemplar)
+		return
+	}
+	o.Observer.Observe(val)
+}
+
 // NewHistogramVec creates a new Vec[Observer] (i.e. Histogram Vec) based on the provided HistogramOpts and
 // partitioned by the given label names.
 func NewHistogramVec(opts HistogramOpts, labelNames []string) *histogramVec {
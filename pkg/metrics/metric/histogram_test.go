@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +36,61 @@ func TestHistogramWithLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestHistogramObserveWithExemplar(t *testing.T) {
+	o := NewHistogramVec(HistogramOpts{
+		Namespace: "cilium",
+		Subsystem: "subsystem",
+		Name:      "test_exemplar",
+		Buckets:   []float64{1, 2, 4, 8},
+	}, []string{"foo"})
+	r := prometheus.NewRegistry()
+	r.MustRegister(o)
+
+	o.WithLabelValues("bar").ObserveWithExemplar(1, prometheus.Labels{"trace_id": "deadbeef"})
+
+	ms, err := dumpMetrics(o)
+	assert.NoError(t, err)
+	assert.Len(t, ms, 1)
+
+	var exemplar *dto.Exemplar
+	for _, bucket := range ms[0].Histogram.Bucket {
+		if bucket.GetExemplar() != nil {
+			exemplar = bucket.GetExemplar()
+		}
+	}
+	if assert.NotNil(t, exemplar, "expected an exemplar to be attached to a bucket") {
+		assert.Equal(t, "trace_id", exemplar.Label[0].GetName())
+		assert.Equal(t, "deadbeef", exemplar.Label[0].GetValue())
+	}
+}
+
+func TestHistogramOptsToPrometheusNativeHistogram(t *testing.T) {
+	ho := HistogramOpts{
+		Namespace:                   "cilium",
+		Subsystem:                   "subsystem",
+		Name:                        "test_native",
+		NativeHistogramBucketFactor: 1.1,
+	}
+	po := ho.toPrometheus()
+	assert.Equal(t, 1.1, po.NativeHistogramBucketFactor)
+
+	// Registering a HistogramVec built from opts with a bucket factor set
+	// must not fail: the native histogram config has to actually reach
+	// prometheus.HistogramOpts, not just be accepted by our own struct.
+	o := NewHistogramVec(ho, []string{"foo"})
+	r := prometheus.NewRegistry()
+	assert.NoError(t, r.Register(o))
+}
+
+func TestHistogramObserveWithExemplarDisabled(t *testing.T) {
+	o := NewHistogramVec(HistogramOpts{
+		Namespace: "cilium",
+		Subsystem: "subsystem",
+		Name:      "test_exemplar_disabled",
+		Disabled:  true,
+	}, []string{"foo"})
+
+	// Must not panic when the metric is disabled.
+	o.WithLabelValues("bar").ObserveWithExemplar(1, prometheus.Labels{"trace_id": "deadbeef"})
+}
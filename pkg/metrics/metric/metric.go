@@ -30,6 +30,31 @@ type metric struct {
 	enabled bool
 	opts    Opts
 	labels  *labelSet
+	alias   string
+}
+
+// Option configures optional metadata on a metric, passed as trailing
+// arguments to the Newxxx constructors in this package.
+type Option func(*metric)
+
+// WithAlias causes the metric to also be exported under oldConfigName, with
+// an identical value to the canonical metric. This is used to avoid
+// breaking dashboards or alerts immediately when renaming a metric: both
+// the new and old names are emitted side by side for a release, and the
+// alias is dropped once consumers have migrated.
+func WithAlias(oldConfigName string) Option {
+	return func(m *metric) {
+		m.alias = oldConfigName
+	}
+}
+
+// aliasDesc returns the Desc used to also export this metric under its
+// deprecated name, or nil if WithAlias was never applied.
+func (b *metric) aliasDesc() *prometheus.Desc {
+	if b.alias == "" {
+		return nil
+	}
+	return prometheus.NewDesc(b.alias, b.opts.Help, nil, b.opts.ConstLabels)
 }
 
 // forEachLabelVector performs a product of all possible label value combinations
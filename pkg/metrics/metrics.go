@@ -12,6 +12,8 @@ package metrics
 
 import (
 	"context"
+	"maps"
+	"reflect"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -72,6 +74,9 @@ const (
 	// SubsystemAPILimiter is the subsystem to scope metrics related to the API limiter package.
 	SubsystemAPILimiter = "api_limiter"
 
+	// SubsystemEnvoy is the subsystem to scope metrics related to the Envoy proxy and its xDS server.
+	SubsystemEnvoy = "envoy"
+
 	// CiliumAgentNamespace is used to scope metrics from the Cilium Agent
 	CiliumAgentNamespace = "cilium"
 
@@ -95,6 +100,10 @@ const (
 	// LabelAttempts is the number of attempts it took to complete the operation
 	LabelAttempts = "attempts"
 
+	// LabelConfigName is the ConfigName of the metric a registry operation
+	// acted on.
+	LabelConfigName = "config_name"
+
 	// Labels
 
 	// LabelValueFalse is the string value for true metric label values.
@@ -176,6 +185,10 @@ const (
 	// LabelAction is the label used to defined what kind of action was performed in a metric
 	LabelAction = "action"
 
+	// LabelResource is the label used to identify the specific resource a
+	// metric is about, e.g. the name of a resource.Resource[T].
+	LabelResource = "resource"
+
 	// LabelSubsystem is the label used to refer to any of the child process
 	// started by cilium (Envoy, monitor, etc..)
 	LabelSubsystem = "subsystem"
@@ -266,6 +279,41 @@ var (
 
 	registryResolver, registry = promise.New[*Registry]()
 
+	// registryDeferredOpsTotal counts calls to the global metrics functions
+	// (Register, RegisterList, ...) that had to be deferred to a goroutine
+	// because the registry promise had not resolved within withRegistry's
+	// 1 second budget. A non-zero value means hive bootstrap was slow enough
+	// to delay metric registration past that timeout. This is a plain
+	// prometheus.Counter rather than a metric.Counter since it must keep
+	// counting during the exact bootstrap window in which the usual
+	// LegacyMetrics-backed metrics are still NoOps.
+	registryDeferredOpsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "metrics_registry_deferred_total",
+		Help:      "Number of metric operations deferred because the metrics registry was not yet initialized",
+	})
+
+	// registryResolutionDelay observes, for each deferred operation, how
+	// long it took after the 1 second timeout for the registry promise to
+	// eventually resolve.
+	registryResolutionDelay = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "metrics_registry_resolution_delay_seconds",
+		Help:      "Time taken for the metrics registry to resolve after a metric operation started deferring to it",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// registryRegistrationFailuresTotal counts calls to Registry.Register
+	// (and, by extension, RegisterList) that failed, labeled by the
+	// ConfigName of the collector that could not be registered. Register's
+	// error return is frequently ignored by callers, so this is often the
+	// only visible sign that a metric silently failed to register.
+	registryRegistrationFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "metrics_registry_registration_failures_total",
+		Help:      "Number of metric registrations that failed, labeled by the metric's ConfigName",
+	}, []string{LabelConfigName})
+
 	BPFMapPressure = true
 
 	// BootstrapTimes is the durations of cilium-agent bootstrap sequence.
@@ -346,6 +394,17 @@ var (
 	// CNPs with empty or non-existing CIDRGroupRefs are not considered.
 	CIDRGroupsReferenced = NoOpGauge
 
+	// Envoy xDS
+
+	// EnvoyXDSPendingCompletions is the number of xDS resource updates that are
+	// still waiting for an ACK from one or more Envoy nodes.
+	EnvoyXDSPendingCompletions = NoOpGauge
+
+	// EnvoyXDSOldestPendingCompletionSeconds is the age, in seconds, of the
+	// oldest xDS resource update that is still waiting for an ACK. This can be
+	// used to detect Envoy pushes that are stuck without being acknowledged.
+	EnvoyXDSOldestPendingCompletionSeconds = NoOpGauge
+
 	// CIDRGroupTranslationTimeStats is the time taken to translate the policy field `FromCIDRGroupRef`
 	// after the referenced CIDRGroups have been updated or deleted.
 	CIDRGroupTranslationTimeStats = NoOpHistogram
@@ -447,6 +506,16 @@ var (
 	// labeled by scope, action, valid data and equalness.
 	KubernetesEventReceived = NoOpCounterVec
 
+	// KubernetesEventDropped is the number of Kubernetes events dropped
+	// labeled by scope and action, e.g. after exceeding the maximum number
+	// of retries.
+	KubernetesEventDropped = NoOpCounterVec
+
+	// KubernetesEventProcessingLatency is the time elapsed between a
+	// Kubernetes resource event being handed to a consumer and the consumer
+	// calling Done() on it, labeled by resource name and event kind.
+	KubernetesEventProcessingLatency = NoOpObserverVec
+
 	// Kubernetes interactions
 
 	// KubernetesAPIInteractions is the total time taken to process an API call made
@@ -602,12 +671,13 @@ var (
 
 	// WorkQueueLatency is the latency of how long an item stays in the workqueue
 	WorkQueueLatency = metric.NewHistogramVec(metric.HistogramOpts{
-		ConfigName: Namespace + "_" + SubsystemWorkQueue + "_queue_duration_seconds",
-		Namespace:  Namespace,
-		Subsystem:  SubsystemWorkQueue,
-		Name:       "queue_duration_seconds",
-		Help:       "How long in seconds an item stays in workqueue before being requested.",
-		Buckets:    prometheus.ExponentialBuckets(10e-9, 10, 10),
+		ConfigName:                  Namespace + "_" + SubsystemWorkQueue + "_queue_duration_seconds",
+		Namespace:                   Namespace,
+		Subsystem:                   SubsystemWorkQueue,
+		Name:                        "queue_duration_seconds",
+		Help:                        "How long in seconds an item stays in workqueue before being requested.",
+		Buckets:                     prometheus.ExponentialBuckets(10e-9, 10, 10),
+		NativeHistogramBucketFactor: NativeHistogramLatencyBucketFactor,
 	}, []string{"name"})
 
 	// WorkQueueDuration is the duration of how long processing an item for the workqueue
@@ -653,109 +723,214 @@ var (
 )
 
 type LegacyMetrics struct {
-	BootstrapTimes                   metric.Vec[metric.Observer]
-	APIInteractions                  metric.Vec[metric.Observer]
-	NodeConnectivityStatus           metric.DeletableVec[metric.Gauge]
-	NodeConnectivityLatency          metric.DeletableVec[metric.Gauge]
-	Endpoint                         metric.GaugeFunc
-	EndpointMaxIfindex               metric.Gauge
-	EndpointRegenerationTotal        metric.Vec[metric.Counter]
-	EndpointStateCount               metric.Vec[metric.Gauge]
-	EndpointRegenerationTimeStats    metric.Vec[metric.Observer]
-	EndpointPropagationDelay         metric.Vec[metric.Observer]
-	Policy                           metric.Gauge
-	PolicyRegenerationCount          metric.Counter
-	PolicyRegenerationTimeStats      metric.Vec[metric.Observer]
-	PolicyRevision                   metric.Gauge
-	PolicyChangeTotal                metric.Vec[metric.Counter]
-	PolicyEndpointStatus             metric.Vec[metric.Gauge]
-	PolicyImplementationDelay        metric.Vec[metric.Observer]
-	CIDRGroupsReferenced             metric.Gauge
-	CIDRGroupTranslationTimeStats    metric.Histogram
-	Identity                         metric.Vec[metric.Gauge]
-	IdentityLabelSources             metric.Vec[metric.Gauge]
-	EventTS                          metric.Vec[metric.Gauge]
-	EventLagK8s                      metric.Gauge
-	ProxyRedirects                   metric.Vec[metric.Gauge]
-	ProxyPolicyL7Total               metric.Vec[metric.Counter]
-	ProxyUpstreamTime                metric.Vec[metric.Observer]
-	ProxyDatapathUpdateTimeout       metric.Counter
-	ConntrackGCRuns                  metric.Vec[metric.Counter]
-	ConntrackGCKeyFallbacks          metric.Vec[metric.Counter]
-	ConntrackGCSize                  metric.Vec[metric.Gauge]
-	NatGCSize                        metric.Vec[metric.Gauge]
-	ConntrackGCDuration              metric.Vec[metric.Observer]
-	ConntrackDumpResets              metric.Vec[metric.Counter]
-	SignalsHandled                   metric.Vec[metric.Counter]
-	ServicesEventsCount              metric.Vec[metric.Counter]
-	ServiceImplementationDelay       metric.Vec[metric.Observer]
-	ErrorsWarnings                   metric.Vec[metric.Counter]
-	ControllerRuns                   metric.Vec[metric.Counter]
-	ControllerRunsDuration           metric.Vec[metric.Observer]
-	SubprocessStart                  metric.Vec[metric.Counter]
-	KubernetesEventProcessed         metric.Vec[metric.Counter]
-	KubernetesEventReceived          metric.Vec[metric.Counter]
-	KubernetesAPIInteractions        metric.Vec[metric.Observer]
-	KubernetesAPIRateLimiterLatency  metric.Vec[metric.Observer]
-	KubernetesAPICallsTotal          metric.Vec[metric.Counter]
-	KubernetesCNPStatusCompletion    metric.Vec[metric.Observer]
-	TerminatingEndpointsEvents       metric.Counter
-	IPAMEvent                        metric.Vec[metric.Counter]
-	IPAMCapacity                     metric.Vec[metric.Gauge]
-	KVStoreOperationsDuration        metric.Vec[metric.Observer]
-	KVStoreEventsQueueDuration       metric.Vec[metric.Observer]
-	KVStoreQuorumErrors              metric.Vec[metric.Counter]
-	FQDNGarbageCollectorCleanedTotal metric.Counter
-	FQDNActiveNames                  metric.Vec[metric.Gauge]
-	FQDNActiveIPs                    metric.Vec[metric.Gauge]
-	FQDNAliveZombieConnections       metric.Vec[metric.Gauge]
-	FQDNSelectors                    metric.Gauge
-	FQDNSemaphoreRejectedTotal       metric.Counter
-	IPCacheErrorsTotal               metric.Vec[metric.Counter]
-	IPCacheEventsTotal               metric.Vec[metric.Counter]
-	BPFSyscallDuration               metric.Vec[metric.Observer]
-	BPFMapOps                        metric.Vec[metric.Counter]
-	BPFMapCapacity                   metric.Vec[metric.Gauge]
-	TriggerPolicyUpdateTotal         metric.Vec[metric.Counter]
-	TriggerPolicyUpdateFolds         metric.Gauge
-	TriggerPolicyUpdateCallDuration  metric.Vec[metric.Observer]
-	VersionMetric                    metric.Vec[metric.Gauge]
-	APILimiterWaitHistoryDuration    metric.Vec[metric.Observer]
-	APILimiterWaitDuration           metric.Vec[metric.Gauge]
-	APILimiterProcessingDuration     metric.Vec[metric.Gauge]
-	APILimiterRequestsInFlight       metric.Vec[metric.Gauge]
-	APILimiterRateLimit              metric.Vec[metric.Gauge]
-	APILimiterAdjustmentFactor       metric.Vec[metric.Gauge]
-	APILimiterProcessedRequests      metric.Vec[metric.Counter]
-	WorkQueueDepth                   metric.Vec[metric.Gauge]
-	WorkQueueAddsTotal               metric.Vec[metric.Counter]
-	WorkQueueLatency                 metric.Vec[metric.Observer]
-	WorkQueueDuration                metric.Vec[metric.Observer]
-	WorkQueueUnfinishedWork          metric.Vec[metric.Gauge]
-	WorkQueueLongestRunningProcessor metric.Vec[metric.Gauge]
-	WorkQueueRetries                 metric.Vec[metric.Counter]
+	BootstrapTimes                         metric.Vec[metric.Observer]
+	APIInteractions                        metric.Vec[metric.Observer]
+	NodeConnectivityStatus                 metric.DeletableVec[metric.Gauge]
+	NodeConnectivityLatency                metric.DeletableVec[metric.Gauge]
+	Endpoint                               metric.GaugeFunc
+	EndpointMaxIfindex                     metric.Gauge
+	EndpointRegenerationTotal              metric.Vec[metric.Counter]
+	EndpointStateCount                     metric.Vec[metric.Gauge]
+	EndpointRegenerationTimeStats          metric.Vec[metric.Observer]
+	EndpointPropagationDelay               metric.Vec[metric.Observer]
+	Policy                                 metric.Gauge
+	PolicyRegenerationCount                metric.Counter
+	PolicyRegenerationTimeStats            metric.Vec[metric.Observer]
+	PolicyRevision                         metric.Gauge
+	PolicyChangeTotal                      metric.Vec[metric.Counter]
+	PolicyEndpointStatus                   metric.Vec[metric.Gauge]
+	PolicyImplementationDelay              metric.Vec[metric.Observer]
+	CIDRGroupsReferenced                   metric.Gauge
+	EnvoyXDSPendingCompletions             metric.Gauge
+	EnvoyXDSOldestPendingCompletionSeconds metric.Gauge
+	CIDRGroupTranslationTimeStats          metric.Histogram
+	Identity                               metric.Vec[metric.Gauge]
+	IdentityLabelSources                   metric.Vec[metric.Gauge]
+	EventTS                                metric.Vec[metric.Gauge]
+	EventLagK8s                            metric.Gauge
+	ProxyRedirects                         metric.Vec[metric.Gauge]
+	ProxyPolicyL7Total                     metric.Vec[metric.Counter]
+	ProxyUpstreamTime                      metric.Vec[metric.Observer]
+	ProxyDatapathUpdateTimeout             metric.Counter
+	ConntrackGCRuns                        metric.Vec[metric.Counter]
+	ConntrackGCKeyFallbacks                metric.Vec[metric.Counter]
+	ConntrackGCSize                        metric.Vec[metric.Gauge]
+	NatGCSize                              metric.Vec[metric.Gauge]
+	ConntrackGCDuration                    metric.Vec[metric.Observer]
+	ConntrackDumpResets                    metric.Vec[metric.Counter]
+	SignalsHandled                         metric.Vec[metric.Counter]
+	ServicesEventsCount                    metric.Vec[metric.Counter]
+	ServiceImplementationDelay             metric.Vec[metric.Observer]
+	ErrorsWarnings                         metric.Vec[metric.Counter]
+	ControllerRuns                         metric.Vec[metric.Counter]
+	ControllerRunsDuration                 metric.Vec[metric.Observer]
+	SubprocessStart                        metric.Vec[metric.Counter]
+	KubernetesEventProcessed               metric.Vec[metric.Counter]
+	KubernetesEventReceived                metric.Vec[metric.Counter]
+	KubernetesEventDropped                 metric.Vec[metric.Counter]
+	KubernetesEventProcessingLatency       metric.Vec[metric.Observer]
+	KubernetesAPIInteractions              metric.Vec[metric.Observer]
+	KubernetesAPIRateLimiterLatency        metric.Vec[metric.Observer]
+	KubernetesAPICallsTotal                metric.Vec[metric.Counter]
+	KubernetesCNPStatusCompletion          metric.Vec[metric.Observer]
+	TerminatingEndpointsEvents             metric.Counter
+	IPAMEvent                              metric.Vec[metric.Counter]
+	IPAMCapacity                           metric.Vec[metric.Gauge]
+	KVStoreOperationsDuration              metric.Vec[metric.Observer]
+	KVStoreEventsQueueDuration             metric.Vec[metric.Observer]
+	KVStoreQuorumErrors                    metric.Vec[metric.Counter]
+	FQDNGarbageCollectorCleanedTotal       metric.Counter
+	FQDNActiveNames                        metric.Vec[metric.Gauge]
+	FQDNActiveIPs                          metric.Vec[metric.Gauge]
+	FQDNAliveZombieConnections             metric.Vec[metric.Gauge]
+	FQDNSelectors                          metric.Gauge
+	FQDNSemaphoreRejectedTotal             metric.Counter
+	IPCacheErrorsTotal                     metric.Vec[metric.Counter]
+	IPCacheEventsTotal                     metric.Vec[metric.Counter]
+	BPFSyscallDuration                     metric.Vec[metric.Observer]
+	BPFMapOps                              metric.Vec[metric.Counter]
+	BPFMapCapacity                         metric.Vec[metric.Gauge]
+	TriggerPolicyUpdateTotal               metric.Vec[metric.Counter]
+	TriggerPolicyUpdateFolds               metric.Gauge
+	TriggerPolicyUpdateCallDuration        metric.Vec[metric.Observer]
+	VersionMetric                          metric.Vec[metric.Gauge]
+	APILimiterWaitHistoryDuration          metric.Vec[metric.Observer]
+	APILimiterWaitDuration                 metric.Vec[metric.Gauge]
+	APILimiterProcessingDuration           metric.Vec[metric.Gauge]
+	APILimiterRequestsInFlight             metric.Vec[metric.Gauge]
+	APILimiterRateLimit                    metric.Vec[metric.Gauge]
+	APILimiterAdjustmentFactor             metric.Vec[metric.Gauge]
+	APILimiterProcessedRequests            metric.Vec[metric.Counter]
+	WorkQueueDepth                         metric.Vec[metric.Gauge]
+	WorkQueueAddsTotal                     metric.Vec[metric.Counter]
+	WorkQueueLatency                       metric.Vec[metric.Observer]
+	WorkQueueDuration                      metric.Vec[metric.Observer]
+	WorkQueueUnfinishedWork                metric.Vec[metric.Gauge]
+	WorkQueueLongestRunningProcessor       metric.Vec[metric.Gauge]
+	WorkQueueRetries                       metric.Vec[metric.Counter]
 }
 
-func NewLegacyMetrics() *LegacyMetrics {
+// LatencyBuckets returns the standard set of histogram buckets (in seconds)
+// used for latency metrics in NewLegacyMetrics that don't have a more
+// specific set of buckets of their own. Keeping these metrics on a shared
+// bucket set makes their values comparable to each other.
+func LatencyBuckets() []float64 {
+	return []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+}
+
+// NativeHistogramLatencyBucketFactor is the metric.HistogramOpts.NativeHistogramBucketFactor
+// used by the latency histograms that are high enough cardinality or
+// resolution to benefit from being exposed as native histograms to a
+// Prometheus server that scrapes them with native histograms enabled. These
+// histograms keep their classic buckets as well, so scrapers without native
+// histogram support see the same buckets as before. 1.1
+// keeps each bucket within 10% of the previous one, which is a reasonable
+// cost/accuracy trade-off recommended by the upstream client library.
+const NativeHistogramLatencyBucketFactor = 1.1
+
+// SizeBuckets returns the standard set of histogram buckets (in bytes) used
+// for size metrics in NewLegacyMetrics that don't have a more specific set
+// of buckets of their own.
+func SizeBuckets() []float64 {
+	return []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+}
+
+// globalConstLabels are merged into the ConstLabels of every metric
+// constructed by NewLegacyMetrics, on top of whatever labels the metric
+// definition itself carries. This lets multi-cluster deployments tag every
+// agent metric with e.g. a "cluster" label without editing each metric
+// definition individually.
+var globalConstLabels prometheus.Labels
+
+// SetGlobalConstLabels configures the constant labels merged into every
+// metric constructed by a subsequent call to NewLegacyMetrics. It has no
+// effect on metrics constructed before it is called, so it must be called
+// before NewLegacyMetrics. The default, an unset or empty labels map,
+// leaves metrics unaffected.
+func SetGlobalConstLabels(labels map[string]string) {
+	globalConstLabels = labels
+}
+
+// withGlobalConstLabels merges globalConstLabels into constLabels, giving
+// precedence to constLabels on key collision so that a metric's own const
+// labels are never silently overridden by the global ones.
+func withGlobalConstLabels(constLabels prometheus.Labels) prometheus.Labels {
+	if len(globalConstLabels) == 0 {
+		return constLabels
+	}
+
+	merged := make(prometheus.Labels, len(globalConstLabels)+len(constLabels))
+	maps.Copy(merged, globalConstLabels)
+	maps.Copy(merged, constLabels)
+	return merged
+}
+
+func newCounter(opts metric.CounterOpts) metric.Counter {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewCounter(opts)
+}
+
+func newCounterVec(opts metric.CounterOpts, labelNames []string) metric.DeletableVec[metric.Counter] {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewCounterVec(opts, labelNames)
+}
+
+func newCounterVecWithLabels(opts metric.CounterOpts, labels metric.Labels) metric.DeletableVec[metric.Counter] {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewCounterVecWithLabels(opts, labels)
+}
+
+func newGauge(opts metric.GaugeOpts) metric.Gauge {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewGauge(opts)
+}
+
+func newGaugeVec(opts metric.GaugeOpts, labelNames []string) metric.DeletableVec[metric.Gauge] {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewGaugeVec(opts, labelNames)
+}
+
+func newHistogram(opts metric.HistogramOpts) metric.Histogram {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewHistogram(opts)
+}
+
+func newHistogramVec(opts metric.HistogramOpts, labelNames []string) metric.Vec[metric.Observer] {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewHistogramVec(opts, labelNames)
+}
+
+func newHistogramVecWithLabels(opts metric.HistogramOpts, labels metric.Labels) metric.Vec[metric.Observer] {
+	opts.ConstLabels = withGlobalConstLabels(opts.ConstLabels)
+	return metric.NewHistogramVecWithLabels(opts, labels)
+}
+
+// buildLegacyMetrics constructs a fresh LegacyMetrics. Unlike NewLegacyMetrics,
+// it does not install the result as the package-level metric globals (e.g.
+// ErrorsWarnings), which makes it safe to call more than once, such as from
+// NewTestMetrics.
+func buildLegacyMetrics() *LegacyMetrics {
 	lm := &LegacyMetrics{
-		BootstrapTimes: metric.NewHistogramVec(metric.HistogramOpts{
+		BootstrapTimes: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemAgent + "_bootstrap_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAgent,
 			Name:       "bootstrap_seconds",
 			Help:       "Duration of bootstrap sequence",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelScope, LabelOutcome}),
 
-		APIInteractions: metric.NewHistogramVec(metric.HistogramOpts{
+		APIInteractions: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemAgent + "_api_process_time_seconds",
 
 			Namespace: Namespace,
 			Subsystem: SubsystemAgent,
 			Name:      "api_process_time_seconds",
 			Help:      "Duration of processed API calls labeled by path, method and return code.",
+			Buckets:   LatencyBuckets(),
 		}, []string{LabelPath, LabelMethod, LabelAPIReturnCode}),
 
-		EndpointRegenerationTotal: metric.NewCounterVecWithLabels(metric.CounterOpts{
+		EndpointRegenerationTotal: newCounterVecWithLabels(metric.CounterOpts{
 			ConfigName: Namespace + "_endpoint_regenerations_total",
 
 			Namespace: Namespace,
@@ -768,7 +943,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			},
 		}),
 
-		EndpointStateCount: metric.NewGaugeVec(metric.GaugeOpts{
+		EndpointStateCount: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_endpoint_state",
 			Namespace:  Namespace,
 			Name:       "endpoint_state",
@@ -777,43 +952,45 @@ func NewLegacyMetrics() *LegacyMetrics {
 			[]string{"endpoint_state"},
 		),
 
-		EndpointRegenerationTimeStats: metric.NewHistogramVec(metric.HistogramOpts{
+		EndpointRegenerationTimeStats: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_endpoint_regeneration_time_stats_seconds",
 
 			Namespace: Namespace,
 			Name:      "endpoint_regeneration_time_stats_seconds",
 			Help:      "Endpoint regeneration time stats labeled by the scope",
+			Buckets:   LatencyBuckets(),
 		}, []string{LabelScope, LabelStatus}),
 
-		Policy: metric.NewGauge(metric.GaugeOpts{
+		Policy: newGauge(metric.GaugeOpts{
 			ConfigName: Namespace + "_policy",
 			Namespace:  Namespace,
 			Name:       "policy",
 			Help:       "Number of policies currently loaded",
 		}),
 
-		PolicyRegenerationCount: metric.NewCounter(metric.CounterOpts{
+		PolicyRegenerationCount: newCounter(metric.CounterOpts{
 			ConfigName: Namespace + "_policy_regeneration_total",
 			Namespace:  Namespace,
 			Name:       "policy_regeneration_total",
 			Help:       "Total number of successful policy regenerations",
 		}),
 
-		PolicyRegenerationTimeStats: metric.NewHistogramVec(metric.HistogramOpts{
+		PolicyRegenerationTimeStats: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_policy_regeneration_time_stats_seconds",
 			Namespace:  Namespace,
 			Name:       "policy_regeneration_time_stats_seconds",
 			Help:       "Policy regeneration time stats labeled by the scope",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelScope, LabelStatus}),
 
-		PolicyRevision: metric.NewGauge(metric.GaugeOpts{
+		PolicyRevision: newGauge(metric.GaugeOpts{
 			ConfigName: Namespace + "_policy_max_revision",
 			Namespace:  Namespace,
 			Name:       "policy_max_revision",
 			Help:       "Highest policy revision number in the agent",
 		}),
 
-		PolicyChangeTotal: metric.NewCounterVecWithLabels(metric.CounterOpts{
+		PolicyChangeTotal: newCounterVecWithLabels(metric.CounterOpts{
 			ConfigName: Namespace + "_policy_change_total",
 
 			Namespace: Namespace,
@@ -826,7 +1003,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			},
 		}),
 
-		PolicyEndpointStatus: metric.NewGaugeVec(metric.GaugeOpts{
+		PolicyEndpointStatus: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_policy_endpoint_enforcement_status",
 
 			Namespace: Namespace,
@@ -834,7 +1011,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:      "Number of endpoints labeled by policy enforcement status",
 		}, []string{LabelPolicyEnforcement}),
 
-		PolicyImplementationDelay: metric.NewHistogramVecWithLabels(metric.HistogramOpts{
+		PolicyImplementationDelay: newHistogramVecWithLabels(metric.HistogramOpts{
 			ConfigName: Namespace + "_policy_implementation_delay",
 
 			Namespace: Namespace,
@@ -847,7 +1024,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			},
 		}),
 
-		CIDRGroupsReferenced: metric.NewGauge(metric.GaugeOpts{
+		CIDRGroupsReferenced: newGauge(metric.GaugeOpts{
 			ConfigName: Namespace + "cidrgroups_referenced",
 
 			Namespace: Namespace,
@@ -855,16 +1032,33 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:      "Number of CNPs and CCNPs referencing at least one CiliumCIDRGroup. CNPs with empty or non-existing CIDRGroupRefs are not considered",
 		}),
 
-		CIDRGroupTranslationTimeStats: metric.NewHistogram(metric.HistogramOpts{
+		EnvoyXDSPendingCompletions: newGauge(metric.GaugeOpts{
+			ConfigName: Namespace + "envoy_xds_pending_completions",
+			Namespace:  Namespace,
+			Subsystem:  SubsystemEnvoy,
+			Name:       "xds_pending_completions",
+			Help:       "Number of xDS resource updates awaiting an ACK from one or more Envoy nodes",
+		}),
+
+		EnvoyXDSOldestPendingCompletionSeconds: newGauge(metric.GaugeOpts{
+			ConfigName: Namespace + "envoy_xds_oldest_pending_completion_seconds",
+			Namespace:  Namespace,
+			Subsystem:  SubsystemEnvoy,
+			Name:       "xds_oldest_pending_completion_seconds",
+			Help:       "Age in seconds of the oldest xDS resource update awaiting an ACK, used to detect stuck Envoy pushes",
+		}),
+
+		CIDRGroupTranslationTimeStats: newHistogram(metric.HistogramOpts{
 			ConfigName: Namespace + "cidrgroup_translation_time_stats_seconds",
 			Disabled:   true,
 
 			Namespace: Namespace,
 			Name:      "cidrgroup_translation_time_stats_seconds",
 			Help:      "CIDRGroup translation time stats",
+			Buckets:   LatencyBuckets(),
 		}),
 
-		Identity: metric.NewGaugeVec(metric.GaugeOpts{
+		Identity: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_identity",
 
 			Namespace: Namespace,
@@ -872,7 +1066,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:      "Number of identities currently allocated",
 		}, []string{LabelType}),
 
-		IdentityLabelSources: metric.NewGaugeVec(metric.GaugeOpts{
+		IdentityLabelSources: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_identity_label_sources",
 
 			Namespace: Namespace,
@@ -880,14 +1074,14 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:      "Number of identities which contain at least one label of the given label source",
 		}, []string{LabelSource}),
 
-		EventTS: metric.NewGaugeVec(metric.GaugeOpts{
+		EventTS: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_event_ts",
 			Namespace:  Namespace,
 			Name:       "event_ts",
 			Help:       "Last timestamp when Cilium received an event from a control plane source, per resource and per action",
 		}, []string{LabelEventSource, LabelScope, LabelAction}),
 
-		EventLagK8s: metric.NewGauge(metric.GaugeOpts{
+		EventLagK8s: newGauge(metric.GaugeOpts{
 			ConfigName:  Namespace + "_k8s_event_lag_seconds",
 			Disabled:    true,
 			Namespace:   Namespace,
@@ -896,7 +1090,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			ConstLabels: prometheus.Labels{"source": LabelEventSourceK8s},
 		}),
 
-		ProxyRedirects: metric.NewGaugeVec(metric.GaugeOpts{
+		ProxyRedirects: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_proxy_redirects",
 
 			Namespace: Namespace,
@@ -904,7 +1098,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:      "Number of redirects installed for endpoints, labeled by protocol",
 		}, []string{LabelProtocolL7}),
 
-		ProxyPolicyL7Total: metric.NewCounterVecWithLabels(metric.CounterOpts{
+		ProxyPolicyL7Total: newCounterVecWithLabels(metric.CounterOpts{
 			ConfigName: Namespace + "_policy_l7_total",
 			Namespace:  Namespace,
 			Name:       "policy_l7_total",
@@ -920,14 +1114,16 @@ func NewLegacyMetrics() *LegacyMetrics {
 			},
 		}),
 
-		ProxyUpstreamTime: metric.NewHistogramVec(metric.HistogramOpts{
-			ConfigName: Namespace + "_proxy_upstream_reply_seconds",
-			Namespace:  Namespace,
-			Name:       "proxy_upstream_reply_seconds",
-			Help:       "Seconds waited to get a reply from a upstream server",
+		ProxyUpstreamTime: newHistogramVec(metric.HistogramOpts{
+			ConfigName:                  Namespace + "_proxy_upstream_reply_seconds",
+			Namespace:                   Namespace,
+			Name:                        "proxy_upstream_reply_seconds",
+			Help:                        "Seconds waited to get a reply from a upstream server",
+			Buckets:                     LatencyBuckets(),
+			NativeHistogramBucketFactor: NativeHistogramLatencyBucketFactor,
 		}, []string{"error", LabelProtocolL7, LabelScope}),
 
-		ProxyDatapathUpdateTimeout: metric.NewCounter(metric.CounterOpts{
+		ProxyDatapathUpdateTimeout: newCounter(metric.CounterOpts{
 			ConfigName: Namespace + "_proxy_datapath_update_timeout_total",
 			Disabled:   true,
 
@@ -936,7 +1132,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:      "Number of total datapath update timeouts due to FQDN IP updates",
 		}),
 
-		ConntrackGCRuns: metric.NewCounterVec(metric.CounterOpts{
+		ConntrackGCRuns: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_conntrack_gc_runs_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemDatapath,
@@ -945,7 +1141,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 				"labeled by completion status",
 		}, []string{LabelDatapathFamily, LabelProtocol, LabelStatus}),
 
-		ConntrackGCKeyFallbacks: metric.NewCounterVec(metric.CounterOpts{
+		ConntrackGCKeyFallbacks: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_conntrack_gc_key_fallbacks_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemDatapath,
@@ -953,7 +1149,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of times a key fallback was needed when iterating over the BPF map",
 		}, []string{LabelDatapathFamily, LabelProtocol}),
 
-		ConntrackGCSize: metric.NewGaugeVec(metric.GaugeOpts{
+		ConntrackGCSize: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_conntrack_gc_entries",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemDatapath,
@@ -962,7 +1158,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 				"of a garbage collector run labeled by datapath family.",
 		}, []string{LabelDatapathFamily, LabelProtocol, LabelStatus}),
 
-		NatGCSize: metric.NewGaugeVec(metric.GaugeOpts{
+		NatGCSize: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_nat_gc_entries",
 			Disabled:   true,
 			Namespace:  Namespace,
@@ -972,16 +1168,17 @@ func NewLegacyMetrics() *LegacyMetrics {
 				"of a garbage collector run labeled by datapath family.",
 		}, []string{LabelDatapathFamily, LabelDirection, LabelStatus}),
 
-		ConntrackGCDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		ConntrackGCDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_conntrack_gc_duration_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemDatapath,
 			Name:       "conntrack_gc_duration_seconds",
 			Help: "Duration in seconds of the garbage collector process " +
 				"labeled by datapath family and completion status",
+			Buckets: LatencyBuckets(),
 		}, []string{LabelDatapathFamily, LabelProtocol, LabelStatus}),
 
-		ConntrackDumpResets: metric.NewCounterVec(metric.CounterOpts{
+		ConntrackDumpResets: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_conntrack_dump_resets_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemDatapath,
@@ -989,7 +1186,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of conntrack dump resets. Happens when a BPF entry gets removed while dumping the map is in progress",
 		}, []string{LabelDatapathArea, LabelDatapathName, LabelDatapathFamily}),
 
-		SignalsHandled: metric.NewCounterVec(metric.CounterOpts{
+		SignalsHandled: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemDatapath + "_signals_handled_total",
 
 			Namespace: Namespace,
@@ -999,67 +1196,85 @@ func NewLegacyMetrics() *LegacyMetrics {
 				"labeled by signal type, data and completion status",
 		}, []string{LabelSignalType, LabelSignalData, LabelStatus}),
 
-		ServicesEventsCount: metric.NewCounterVec(metric.CounterOpts{
+		ServicesEventsCount: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_services_events_total",
 			Namespace:  Namespace,
 			Name:       "services_events_total",
 			Help:       "Number of services events labeled by action type",
 		}, []string{LabelAction}),
 
-		ServiceImplementationDelay: metric.NewHistogramVec(metric.HistogramOpts{
+		ServiceImplementationDelay: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_service_implementation_delay",
 			Namespace:  Namespace,
 			Name:       "service_implementation_delay",
 			Help: "Duration in seconds to propagate the data plane programming of a service, its network and endpoints " +
 				"from the time the service or the service pod was changed excluding the event queue latency",
+			Buckets: LatencyBuckets(),
 		}, []string{LabelAction}),
 
 		ErrorsWarnings: newErrorsWarningsMetric(),
 
-		ControllerRuns: metric.NewCounterVec(metric.CounterOpts{
+		ControllerRuns: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_controllers_runs_total",
 			Namespace:  Namespace,
 			Name:       "controllers_runs_total",
 			Help:       "Number of times that a controller process was run labeled by completion status",
 		}, []string{LabelStatus}),
 
-		ControllerRunsDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		ControllerRunsDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_controllers_runs_duration_seconds",
 			Namespace:  Namespace,
 			Name:       "controllers_runs_duration_seconds",
 			Help:       "Duration in seconds of the controller process labeled by completion status",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelStatus}),
 
-		SubprocessStart: metric.NewCounterVec(metric.CounterOpts{
+		SubprocessStart: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_subprocess_start_total",
 			Namespace:  Namespace,
 			Name:       "subprocess_start_total",
 			Help:       "Number of times that Cilium has started a subprocess, labeled by subsystem",
 		}, []string{LabelSubsystem}),
 
-		KubernetesEventProcessed: metric.NewCounterVec(metric.CounterOpts{
+		KubernetesEventProcessed: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_kubernetes_events_total",
 			Namespace:  Namespace,
 			Name:       "kubernetes_events_total",
 			Help:       "Number of Kubernetes events processed labeled by scope, action and execution result",
 		}, []string{LabelScope, LabelAction, LabelStatus}),
 
-		KubernetesEventReceived: metric.NewCounterVec(metric.CounterOpts{
+		KubernetesEventReceived: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_kubernetes_events_received_total",
 			Namespace:  Namespace,
 			Name:       "kubernetes_events_received_total",
 			Help:       "Number of Kubernetes events received labeled by scope, action, valid data and equalness",
 		}, []string{LabelScope, LabelAction, "valid", "equal"}),
 
-		KubernetesAPIInteractions: metric.NewHistogramVec(metric.HistogramOpts{
+		KubernetesEventDropped: newCounterVec(metric.CounterOpts{
+			ConfigName: Namespace + "_kubernetes_events_dropped_total",
+			Namespace:  Namespace,
+			Name:       "kubernetes_events_dropped_total",
+			Help:       "Number of Kubernetes events dropped labeled by scope and action, e.g. after exceeding the maximum number of retries",
+		}, []string{LabelScope, LabelAction}),
+
+		KubernetesEventProcessingLatency: newHistogramVec(metric.HistogramOpts{
+			ConfigName: Namespace + "_kubernetes_event_processing_latency_seconds",
+			Namespace:  Namespace,
+			Name:       "kubernetes_event_processing_latency_seconds",
+			Help:       "Time elapsed between a Kubernetes resource event being handed to a consumer and the consumer calling Done() on it, labeled by resource name and event kind",
+			Buckets:    LatencyBuckets(),
+		}, []string{LabelResource, LabelKind}),
+
+		KubernetesAPIInteractions: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemK8sClient + "_api_latency_time_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemK8sClient,
 			Name:       "api_latency_time_seconds",
 			Help:       "Duration of processed API calls labeled by path and method.",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelPath, LabelMethod}),
 
-		KubernetesAPIRateLimiterLatency: metric.NewHistogramVec(metric.HistogramOpts{
+		KubernetesAPIRateLimiterLatency: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemK8sClient + "_rate_limiter_duration_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemK8sClient,
@@ -1068,7 +1283,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Buckets:    []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1.0, 2.0, 4.0, 8.0, 15.0, 30.0, 60.0},
 		}, []string{LabelPath, LabelMethod}),
 
-		KubernetesAPICallsTotal: metric.NewCounterVec(metric.CounterOpts{
+		KubernetesAPICallsTotal: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemK8sClient + "_api_calls_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemK8sClient,
@@ -1076,15 +1291,16 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of API calls made to kube-apiserver labeled by host, method and return code.",
 		}, []string{"host", LabelMethod, LabelAPIReturnCode}),
 
-		KubernetesCNPStatusCompletion: metric.NewHistogramVec(metric.HistogramOpts{
+		KubernetesCNPStatusCompletion: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemK8s + "_cnp_status_completion_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemK8s,
 			Name:       "cnp_status_completion_seconds",
 			Help:       "Duration in seconds in how long it took to complete a CNP status update",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelAttempts, LabelOutcome}),
 
-		TerminatingEndpointsEvents: metric.NewCounter(metric.CounterOpts{
+		TerminatingEndpointsEvents: newCounter(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemK8s + "_terminating_endpoints_events_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemK8s,
@@ -1092,29 +1308,30 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of terminating endpoint events received from Kubernetes",
 		}),
 
-		IPAMEvent: metric.NewCounterVec(metric.CounterOpts{
+		IPAMEvent: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_ipam_events_total",
 			Namespace:  Namespace,
 			Name:       "ipam_events_total",
 			Help:       "Number of IPAM events received labeled by action and datapath family type",
 		}, []string{LabelAction, LabelDatapathFamily}),
 
-		IPAMCapacity: metric.NewGaugeVec(metric.GaugeOpts{
+		IPAMCapacity: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_ipam_capacity",
 			Namespace:  Namespace,
 			Name:       "ipam_capacity",
 			Help:       "Total number of IPs in the IPAM pool labeled by family",
 		}, []string{LabelDatapathFamily}),
 
-		KVStoreOperationsDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		KVStoreOperationsDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemKVStore + "_operations_duration_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemKVStore,
 			Name:       "operations_duration_seconds",
 			Help:       "Duration in seconds of kvstore operations",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelScope, LabelKind, LabelAction, LabelOutcome}),
 
-		KVStoreEventsQueueDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		KVStoreEventsQueueDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemKVStore + "_events_queue_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemKVStore,
@@ -1123,7 +1340,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Buckets:    []float64{.002, .005, .01, .015, .025, .05, .1, .25, .5, .75, 1},
 		}, []string{LabelScope, LabelAction}),
 
-		KVStoreQuorumErrors: metric.NewCounterVec(metric.CounterOpts{
+		KVStoreQuorumErrors: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemKVStore + "_quorum_errors_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemKVStore,
@@ -1131,7 +1348,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of quorum errors",
 		}, []string{LabelError}),
 
-		IPCacheErrorsTotal: metric.NewCounterVec(metric.CounterOpts{
+		IPCacheErrorsTotal: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemIPCache + "_errors_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemIPCache,
@@ -1139,7 +1356,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of errors interacting with the IP to Identity cache",
 		}, []string{LabelType, LabelError}),
 
-		IPCacheEventsTotal: metric.NewCounterVec(metric.CounterOpts{
+		IPCacheEventsTotal: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemIPCache + "_events_total",
 			Disabled:   true,
 			Namespace:  Namespace,
@@ -1148,7 +1365,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of events interacting with the IP to Identity cache",
 		}, []string{LabelType}),
 
-		FQDNGarbageCollectorCleanedTotal: metric.NewCounter(metric.CounterOpts{
+		FQDNGarbageCollectorCleanedTotal: newCounter(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemFQDN + "_gc_deletions_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemFQDN,
@@ -1156,7 +1373,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of FQDNs that have been cleaned on FQDN Garbage collector job",
 		}),
 
-		FQDNActiveNames: metric.NewGaugeVec(metric.GaugeOpts{
+		FQDNActiveNames: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemFQDN + "_active_names",
 			Disabled:   true,
 			Namespace:  Namespace,
@@ -1165,7 +1382,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of domains inside the DNS cache that have not expired (by TTL), per endpoint",
 		}, []string{LabelPeerEndpoint}),
 
-		FQDNActiveIPs: metric.NewGaugeVec(metric.GaugeOpts{
+		FQDNActiveIPs: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemFQDN + "_active_ips",
 			Disabled:   true,
 			Namespace:  Namespace,
@@ -1174,7 +1391,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of IPs inside the DNS cache associated with a domain that has not expired (by TTL), per endpoint",
 		}, []string{LabelPeerEndpoint}),
 
-		FQDNAliveZombieConnections: metric.NewGaugeVec(metric.GaugeOpts{
+		FQDNAliveZombieConnections: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemFQDN + "_alive_zombie_connections",
 			Disabled:   true,
 			Namespace:  Namespace,
@@ -1183,7 +1400,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of IPs associated with domains that have expired (by TTL) yet still associated with an active connection (aka zombie), per endpoint",
 		}, []string{LabelPeerEndpoint}),
 
-		FQDNSelectors: metric.NewGauge(metric.GaugeOpts{
+		FQDNSelectors: newGauge(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemFQDN + "_selectors",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemFQDN,
@@ -1191,7 +1408,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of registered ToFQDN selectors",
 		}),
 
-		FQDNSemaphoreRejectedTotal: metric.NewCounter(metric.CounterOpts{
+		FQDNSemaphoreRejectedTotal: newCounter(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemFQDN + "_semaphore_rejected_total",
 			Disabled:   true,
 			Namespace:  Namespace,
@@ -1200,16 +1417,17 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Number of DNS request rejected by the DNS Proxy's admission semaphore",
 		}),
 
-		BPFSyscallDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		BPFSyscallDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemBPF + "_syscall_duration_seconds",
 			Disabled:   true,
 			Namespace:  Namespace,
 			Subsystem:  SubsystemBPF,
 			Name:       "syscall_duration_seconds",
 			Help:       "Duration of BPF system calls",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelOperation, LabelOutcome}),
 
-		BPFMapOps: metric.NewCounterVec(metric.CounterOpts{
+		BPFMapOps: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemBPF + "_map_ops_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemBPF,
@@ -1217,7 +1435,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Total operations on map, tagged by map name",
 		}, []string{LabelMapName, LabelOperation, LabelOutcome}),
 
-		BPFMapCapacity: metric.NewGaugeVec(metric.GaugeOpts{
+		BPFMapCapacity: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemBPF + "_map_capacity",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemBPF,
@@ -1225,7 +1443,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Capacity of map, tagged by map group. All maps with a capacity of 65536 are grouped under 'default'",
 		}, []string{LabelMapGroup}),
 
-		TriggerPolicyUpdateTotal: metric.NewCounterVec(metric.CounterOpts{
+		TriggerPolicyUpdateTotal: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemTriggers + "_policy_update_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemTriggers,
@@ -1233,7 +1451,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Total number of policy update trigger invocations labeled by reason",
 		}, []string{"reason"}),
 
-		TriggerPolicyUpdateFolds: metric.NewGauge(metric.GaugeOpts{
+		TriggerPolicyUpdateFolds: newGauge(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemTriggers + "_policy_update_folds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemTriggers,
@@ -1241,31 +1459,37 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Current number of folds",
 		}),
 
-		TriggerPolicyUpdateCallDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		TriggerPolicyUpdateCallDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemTriggers + "_policy_update_call_duration_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemTriggers,
 			Name:       "policy_update_call_duration_seconds",
 			Help:       "Duration of policy update trigger",
+			Buckets:    LatencyBuckets(),
 		}, []string{LabelType}),
 
-		VersionMetric: metric.NewGaugeVec(metric.GaugeOpts{
+		VersionMetric: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_version",
 			Namespace:  Namespace,
 			Name:       "version",
 			Help:       "Cilium version",
 		}, []string{LabelVersion, LabelVersionRevision, LabelArch}),
 
-		APILimiterWaitHistoryDuration: metric.NewHistogramVec(metric.HistogramOpts{
+		APILimiterWaitHistoryDuration: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_wait_history_duration_seconds",
 			Disabled:   true,
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
 			Name:       "wait_history_duration_seconds",
 			Help:       "Histogram over duration of waiting period for API calls subjects to rate limiting",
+			Buckets:    LatencyBuckets(),
 		}, []string{"api_call"}),
 
-		APILimiterWaitDuration: metric.NewGaugeVec(metric.GaugeOpts{
+		// Deprecated: the "api_call","value" label pair multiplies series by
+		// the number of API limiters and statistics. Prefer registering an
+		// APILimiterCollector per limiter, which bakes the statistic into
+		// the metric name instead.
+		APILimiterWaitDuration: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_wait_duration_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
@@ -1273,7 +1497,8 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Current wait time for api calls",
 		}, []string{"api_call", "value"}),
 
-		APILimiterProcessingDuration: metric.NewGaugeVec(metric.GaugeOpts{
+		// Deprecated: see APILimiterWaitDuration.
+		APILimiterProcessingDuration: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_processing_duration_seconds",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
@@ -1281,7 +1506,8 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Current processing time of api call",
 		}, []string{"api_call", "value"}),
 
-		APILimiterRequestsInFlight: metric.NewGaugeVec(metric.GaugeOpts{
+		// Deprecated: see APILimiterWaitDuration.
+		APILimiterRequestsInFlight: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_requests_in_flight",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
@@ -1289,7 +1515,8 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Current requests in flight",
 		}, []string{"api_call", "value"}),
 
-		APILimiterRateLimit: metric.NewGaugeVec(metric.GaugeOpts{
+		// Deprecated: see APILimiterWaitDuration.
+		APILimiterRateLimit: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_rate_limit",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
@@ -1297,7 +1524,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Current rate limiting configuration",
 		}, []string{"api_call", "value"}),
 
-		APILimiterAdjustmentFactor: metric.NewGaugeVec(metric.GaugeOpts{
+		APILimiterAdjustmentFactor: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_adjustment_factor",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
@@ -1305,7 +1532,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Current adjustment factor while auto adjusting",
 		}, []string{"api_call"}),
 
-		APILimiterProcessedRequests: metric.NewCounterVec(metric.CounterOpts{
+		APILimiterProcessedRequests: newCounterVec(metric.CounterOpts{
 			ConfigName: Namespace + "_" + SubsystemAPILimiter + "_processed_requests_total",
 			Namespace:  Namespace,
 			Subsystem:  SubsystemAPILimiter,
@@ -1313,7 +1540,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Help:       "Total number of API requests processed",
 		}, []string{"api_call", LabelOutcome, LabelAPIReturnCode}),
 
-		EndpointPropagationDelay: metric.NewHistogramVec(metric.HistogramOpts{
+		EndpointPropagationDelay: newHistogramVec(metric.HistogramOpts{
 			ConfigName: Namespace + "_endpoint_propagation_delay_seconds",
 			Namespace:  Namespace,
 			Name:       "endpoint_propagation_delay_seconds",
@@ -1321,7 +1548,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			Buckets:    []float64{.05, .1, 1, 5, 30, 60, 120, 240, 300, 600},
 		}, []string{}),
 
-		NodeConnectivityStatus: metric.NewGaugeVec(metric.GaugeOpts{
+		NodeConnectivityStatus: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_node_connectivity_status",
 			Namespace:  Namespace,
 			Name:       "node_connectivity_status",
@@ -1335,7 +1562,7 @@ func NewLegacyMetrics() *LegacyMetrics {
 			LabelType,
 		}),
 
-		NodeConnectivityLatency: metric.NewGaugeVec(metric.GaugeOpts{
+		NodeConnectivityLatency: newGaugeVec(metric.GaugeOpts{
 			ConfigName: Namespace + "_node_connectivity_latency_seconds",
 			Namespace:  Namespace,
 			Name:       "node_connectivity_latency_seconds",
@@ -1368,12 +1595,51 @@ func NewLegacyMetrics() *LegacyMetrics {
 		Name:       "endpoint_max_ifindex",
 		Help:       "Maximum interface index observed for existing endpoints",
 	}
-	lm.EndpointMaxIfindex = metric.NewGauge(ifindexOpts)
+	lm.EndpointMaxIfindex = newGauge(ifindexOpts)
 
 	v := version.GetCiliumVersion()
 	lm.VersionMetric.WithLabelValues(v.Version, v.Revision, v.Arch)
 	lm.BPFMapCapacity.WithLabelValues("default").Set(DefaultMapCapacity)
 
+	return lm
+}
+
+// NewLegacyMetrics builds a fresh LegacyMetrics and installs it as the
+// current package-level metric globals, for use by code that refers to
+// those variables directly (e.g. logging_hook.go's ErrorsWarnings) rather
+// than taking a *LegacyMetrics.
+func NewLegacyMetrics() *LegacyMetrics {
+	lm := buildLegacyMetrics()
+	installLegacyMetricsGlobals(lm)
+	return lm
+}
+
+// Collectors returns every field of lm that is a populated, enabled
+// prometheus.Collector, skipping fields left at their NoOp default (or
+// otherwise disabled via metric.WithMetadata.IsEnabled). Operator and agent
+// share LegacyMetrics but register different subsets of it, so this lets a
+// caller register with Prometheus exactly the metrics it built for itself.
+func (lm *LegacyMetrics) Collectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	v := reflect.ValueOf(lm).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		if wm, ok := field.Interface().(metric.WithMetadata); ok && !wm.IsEnabled() {
+			continue
+		}
+		if c, ok := field.Interface().(prometheus.Collector); ok {
+			collectors = append(collectors, c)
+		}
+	}
+	return collectors
+}
+
+// installLegacyMetricsGlobals assigns every field of lm to its
+// corresponding package-level metric variable.
+func installLegacyMetricsGlobals(lm *LegacyMetrics) {
 	BootstrapTimes = lm.BootstrapTimes
 	APIInteractions = lm.APIInteractions
 	NodeConnectivityStatus = lm.NodeConnectivityStatus
@@ -1392,6 +1658,8 @@ func NewLegacyMetrics() *LegacyMetrics {
 	PolicyEndpointStatus = lm.PolicyEndpointStatus
 	PolicyImplementationDelay = lm.PolicyImplementationDelay
 	CIDRGroupsReferenced = lm.CIDRGroupsReferenced
+	EnvoyXDSPendingCompletions = lm.EnvoyXDSPendingCompletions
+	EnvoyXDSOldestPendingCompletionSeconds = lm.EnvoyXDSOldestPendingCompletionSeconds
 	CIDRGroupTranslationTimeStats = lm.CIDRGroupTranslationTimeStats
 	Identity = lm.Identity
 	IdentityLabelSources = lm.IdentityLabelSources
@@ -1416,6 +1684,8 @@ func NewLegacyMetrics() *LegacyMetrics {
 	SubprocessStart = lm.SubprocessStart
 	KubernetesEventProcessed = lm.KubernetesEventProcessed
 	KubernetesEventReceived = lm.KubernetesEventReceived
+	KubernetesEventDropped = lm.KubernetesEventDropped
+	KubernetesEventProcessingLatency = lm.KubernetesEventProcessingLatency
 	KubernetesAPIInteractions = lm.KubernetesAPIInteractions
 	KubernetesAPIRateLimiterLatency = lm.KubernetesAPIRateLimiterLatency
 	KubernetesAPICallsTotal = lm.KubernetesAPICallsTotal
@@ -1448,8 +1718,140 @@ func NewLegacyMetrics() *LegacyMetrics {
 	APILimiterRateLimit = lm.APILimiterRateLimit
 	APILimiterAdjustmentFactor = lm.APILimiterAdjustmentFactor
 	APILimiterProcessedRequests = lm.APILimiterProcessedRequests
+}
 
-	return lm
+// snapshotLegacyMetricsGlobals captures the current package-level metric
+// globals in a LegacyMetrics, so they can later be restored with
+// installLegacyMetricsGlobals.
+func snapshotLegacyMetricsGlobals() *LegacyMetrics {
+	return &LegacyMetrics{
+		BootstrapTimes:                         BootstrapTimes,
+		APIInteractions:                        APIInteractions,
+		NodeConnectivityStatus:                 NodeConnectivityStatus,
+		NodeConnectivityLatency:                NodeConnectivityLatency,
+		Endpoint:                               Endpoint,
+		EndpointMaxIfindex:                     EndpointMaxIfindex,
+		EndpointRegenerationTotal:              EndpointRegenerationTotal,
+		EndpointStateCount:                     EndpointStateCount,
+		EndpointRegenerationTimeStats:          EndpointRegenerationTimeStats,
+		EndpointPropagationDelay:               EndpointPropagationDelay,
+		Policy:                                 Policy,
+		PolicyRegenerationCount:                PolicyRegenerationCount,
+		PolicyRegenerationTimeStats:            PolicyRegenerationTimeStats,
+		PolicyRevision:                         PolicyRevision,
+		PolicyChangeTotal:                      PolicyChangeTotal,
+		PolicyEndpointStatus:                   PolicyEndpointStatus,
+		PolicyImplementationDelay:              PolicyImplementationDelay,
+		CIDRGroupsReferenced:                   CIDRGroupsReferenced,
+		EnvoyXDSPendingCompletions:             EnvoyXDSPendingCompletions,
+		EnvoyXDSOldestPendingCompletionSeconds: EnvoyXDSOldestPendingCompletionSeconds,
+		CIDRGroupTranslationTimeStats:          CIDRGroupTranslationTimeStats,
+		Identity:                               Identity,
+		IdentityLabelSources:                   IdentityLabelSources,
+		EventTS:                                EventTS,
+		EventLagK8s:                            EventLagK8s,
+		ProxyRedirects:                         ProxyRedirects,
+		ProxyPolicyL7Total:                     ProxyPolicyL7Total,
+		ProxyUpstreamTime:                      ProxyUpstreamTime,
+		ProxyDatapathUpdateTimeout:             ProxyDatapathUpdateTimeout,
+		ConntrackGCRuns:                        ConntrackGCRuns,
+		ConntrackGCKeyFallbacks:                ConntrackGCKeyFallbacks,
+		ConntrackGCSize:                        ConntrackGCSize,
+		NatGCSize:                              NatGCSize,
+		ConntrackGCDuration:                    ConntrackGCDuration,
+		ConntrackDumpResets:                    ConntrackDumpResets,
+		SignalsHandled:                         SignalsHandled,
+		ServicesEventsCount:                    ServicesEventsCount,
+		ServiceImplementationDelay:             ServiceImplementationDelay,
+		ErrorsWarnings:                         ErrorsWarnings,
+		ControllerRuns:                         ControllerRuns,
+		ControllerRunsDuration:                 ControllerRunsDuration,
+		SubprocessStart:                        SubprocessStart,
+		KubernetesEventProcessed:               KubernetesEventProcessed,
+		KubernetesEventReceived:                KubernetesEventReceived,
+		KubernetesEventDropped:                 KubernetesEventDropped,
+		KubernetesEventProcessingLatency:       KubernetesEventProcessingLatency,
+		KubernetesAPIInteractions:              KubernetesAPIInteractions,
+		KubernetesAPIRateLimiterLatency:        KubernetesAPIRateLimiterLatency,
+		KubernetesAPICallsTotal:                KubernetesAPICallsTotal,
+		KubernetesCNPStatusCompletion:          KubernetesCNPStatusCompletion,
+		TerminatingEndpointsEvents:             TerminatingEndpointsEvents,
+		IPAMEvent:                              IPAMEvent,
+		IPAMCapacity:                           IPAMCapacity,
+		KVStoreOperationsDuration:              KVStoreOperationsDuration,
+		KVStoreEventsQueueDuration:             KVStoreEventsQueueDuration,
+		KVStoreQuorumErrors:                    KVStoreQuorumErrors,
+		FQDNGarbageCollectorCleanedTotal:       FQDNGarbageCollectorCleanedTotal,
+		FQDNActiveNames:                        FQDNActiveNames,
+		FQDNActiveIPs:                          FQDNActiveIPs,
+		FQDNAliveZombieConnections:             FQDNAliveZombieConnections,
+		FQDNSelectors:                          FQDNSelectors,
+		FQDNSemaphoreRejectedTotal:             FQDNSemaphoreRejectedTotal,
+		IPCacheErrorsTotal:                     IPCacheErrorsTotal,
+		IPCacheEventsTotal:                     IPCacheEventsTotal,
+		BPFSyscallDuration:                     BPFSyscallDuration,
+		BPFMapOps:                              BPFMapOps,
+		BPFMapCapacity:                         BPFMapCapacity,
+		TriggerPolicyUpdateTotal:               TriggerPolicyUpdateTotal,
+		TriggerPolicyUpdateFolds:               TriggerPolicyUpdateFolds,
+		TriggerPolicyUpdateCallDuration:        TriggerPolicyUpdateCallDuration,
+		VersionMetric:                          VersionMetric,
+		APILimiterWaitHistoryDuration:          APILimiterWaitHistoryDuration,
+		APILimiterWaitDuration:                 APILimiterWaitDuration,
+		APILimiterProcessingDuration:           APILimiterProcessingDuration,
+		APILimiterRequestsInFlight:             APILimiterRequestsInFlight,
+		APILimiterRateLimit:                    APILimiterRateLimit,
+		APILimiterAdjustmentFactor:             APILimiterAdjustmentFactor,
+		APILimiterProcessedRequests:            APILimiterProcessedRequests,
+		WorkQueueDepth:                         WorkQueueDepth,
+		WorkQueueAddsTotal:                     WorkQueueAddsTotal,
+		WorkQueueLatency:                       WorkQueueLatency,
+		WorkQueueDuration:                      WorkQueueDuration,
+		WorkQueueUnfinishedWork:                WorkQueueUnfinishedWork,
+		WorkQueueLongestRunningProcessor:       WorkQueueLongestRunningProcessor,
+		WorkQueueRetries:                       WorkQueueRetries,
+	}
+}
+
+// NewTestMetrics builds a fresh LegacyMetrics registered against an
+// isolated prometheus.Registry, so its metrics can be gathered without
+// colliding with the process-wide registry or with any other test. It also
+// installs the built LegacyMetrics as the package-level metric globals, so
+// that metric-emitting code which refers to those globals directly (rather
+// than taking a *LegacyMetrics) can be exercised too.
+//
+// The returned restore func puts the previous globals back; callers must
+// call it once the test is done, e.g. via t.Cleanup.
+func NewTestMetrics() (*TestMetrics, func()) {
+	previous := snapshotLegacyMetricsGlobals()
+
+	lm := buildLegacyMetrics()
+	installLegacyMetricsGlobals(lm)
+
+	reg := prometheus.NewPedanticRegistry()
+	v := reflect.ValueOf(lm).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if c, ok := v.Field(i).Interface().(prometheus.Collector); ok {
+			reg.MustRegister(c)
+		}
+	}
+
+	return &TestMetrics{LegacyMetrics: lm, registry: reg}, func() {
+		installLegacyMetricsGlobals(previous)
+	}
+}
+
+// TestMetrics is a LegacyMetrics built by NewTestMetrics for use in tests.
+type TestMetrics struct {
+	*LegacyMetrics
+
+	registry *prometheus.Registry
+}
+
+// Snapshot gathers every metric family currently registered, for tests to
+// assert against without having to reach into the underlying registry.
+func (tm *TestMetrics) Snapshot() ([]*dto.MetricFamily, error) {
+	return tm.registry.Gather()
 }
 
 // InitOperatorMetrics is used to init legacy metrics necessary during operator init.
@@ -1458,7 +1860,7 @@ func InitOperatorMetrics() {
 }
 
 func newErrorsWarningsMetric() metric.Vec[metric.Counter] {
-	return metric.NewCounterVec(metric.CounterOpts{
+	return newCounterVec(metric.CounterOpts{
 		ConfigName: Namespace + "_errors_warnings_total",
 		Namespace:  Namespace,
 		Name:       "errors_warnings_total",
@@ -1588,14 +1990,29 @@ func withRegistry(fn func(reg *Registry)) {
 	}
 	cancel()
 
+	onResolved := recordDeferredRegistryOp()
+
 	go func() {
 		reg, err := registry.Await(context.Background())
 		if err == nil {
+			onResolved()
 			fn(reg)
 		}
 	}()
 }
 
+// recordDeferredRegistryOp records that withRegistry is about to defer its
+// caller because the registry promise had not resolved within its timeout.
+// It returns a function to call once the promise does resolve, which
+// observes the resulting delay.
+func recordDeferredRegistryOp() func() {
+	registryDeferredOpsTotal.Inc()
+	deferredAt := time.Now()
+	return func() {
+		registryResolutionDelay.Observe(time.Since(deferredAt).Seconds())
+	}
+}
+
 // GetCounterValue returns the current value
 // stored for the counter
 func GetCounterValue(m prometheus.Counter) float64 {
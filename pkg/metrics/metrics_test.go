@@ -6,9 +6,14 @@ package metrics
 import (
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/metrics/metric"
 	"github.com/cilium/cilium/pkg/option"
 )
 
@@ -64,3 +69,206 @@ func TestGaugeWithThreshold(t *testing.T) {
 	require.Len(t, metrics, initMetricLen)
 	require.Equal(t, underThreshold, GetGaugeValue(gauge.gauge))
 }
+
+// histogramBuckets returns the upper bounds of the buckets configured on a
+// histogram vector, as observed via the metrics it exposes.
+func histogramBuckets(t *testing.T, c prometheus.Collector, labelValues ...string) []float64 {
+	t.Helper()
+
+	promReg := prometheus.NewPedanticRegistry()
+	require.NoError(t, promReg.Register(c))
+
+	vec := c.(metric.Vec[metric.Observer])
+	vec.WithLabelValues(labelValues...).Observe(0)
+
+	families, err := promReg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Len(t, families[0].Metric, 1)
+
+	var bounds []float64
+	for _, b := range families[0].Metric[0].Histogram.Bucket {
+		bounds = append(bounds, b.GetUpperBound())
+	}
+	return bounds
+}
+
+// TestGlobalConstLabels verifies that SetGlobalConstLabels merges the
+// configured labels into every metric constructed by a subsequent
+// NewLegacyMetrics call, that the merge does not affect metrics built before
+// it is called, and that it does not override labels a metric already
+// defines.
+func TestGlobalConstLabels(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConstLabels(nil) })
+
+	unaffected := NewLegacyMetrics()
+	require.Empty(t, constLabelValue(t, unaffected.PolicyRegenerationCount, "cluster"))
+
+	SetGlobalConstLabels(map[string]string{"cluster": "test-cluster"})
+	lm := NewLegacyMetrics()
+
+	require.Equal(t, "test-cluster", constLabelValue(t, lm.PolicyRegenerationCount, "cluster"))
+	require.Equal(t, "test-cluster", constLabelValue(t, lm.Policy, "cluster"))
+
+	require.Empty(t, constLabelValue(t, unaffected.PolicyRegenerationCount, "cluster"))
+}
+
+// constLabelValue gathers c and returns the value of the named label on its
+// first exposed metric, or the empty string if the label is not present.
+func constLabelValue(t *testing.T, c prometheus.Collector, label string) string {
+	t.Helper()
+
+	promReg := prometheus.NewPedanticRegistry()
+	require.NoError(t, promReg.Register(c))
+
+	families, err := promReg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.NotEmpty(t, families[0].Metric)
+
+	for _, l := range families[0].Metric[0].Label {
+		if l.GetName() == label {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+// TestNewTestMetrics verifies that the LoggingHook, which refers to the
+// ErrorsWarnings global directly rather than taking a *LegacyMetrics,
+// increments the isolated metric built by NewTestMetrics, and that the
+// previous globals are back in place once restore is called.
+func TestNewTestMetrics(t *testing.T) {
+	previousErrorsWarnings := ErrorsWarnings
+
+	tm, restore := NewTestMetrics()
+	require.NotSame(t, previousErrorsWarnings, ErrorsWarnings, "NewTestMetrics should have installed its own ErrorsWarnings as the global")
+
+	hook := NewLoggingHook()
+	err := hook.Fire(&logrus.Entry{
+		Level: logrus.WarnLevel,
+		Data:  logrus.Fields{logfields.LogSubsys: "test"},
+	})
+	require.NoError(t, err)
+
+	families, err := tm.Snapshot()
+	require.NoError(t, err)
+	require.Equal(t, float64(1), counterValue(t, families, Namespace+"_errors_warnings_total", prometheus.Labels{"level": "warning", "subsystem": "test"}))
+
+	restore()
+	require.Same(t, previousErrorsWarnings, ErrorsWarnings, "restore should have put the previous global back")
+}
+
+// counterValue returns the value of the counter within families that has
+// the given fully-qualified name and matches labels exactly, failing the
+// test if no such counter is found.
+func counterValue(t *testing.T, families []*dto.MetricFamily, name string, labels prometheus.Labels) float64 {
+	t.Helper()
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.Metric {
+			got := make(prometheus.Labels, len(m.Label))
+			for _, l := range m.Label {
+				got[l.GetName()] = l.GetValue()
+			}
+			if len(got) == len(labels) {
+				match := true
+				for k, v := range labels {
+					if got[k] != v {
+						match = false
+						break
+					}
+				}
+				if match {
+					return m.Counter.GetValue()
+				}
+			}
+		}
+	}
+
+	t.Fatalf("no counter named %q with labels %v found", name, labels)
+	return 0
+}
+
+// TestLegacyMetricsCollectors verifies that Collectors returns exactly the
+// fields that are populated and enabled, skipping the NoOp defaults left on
+// an otherwise-empty LegacyMetrics, and picking up a field once it is set to
+// an enabled metric.
+func TestLegacyMetricsCollectors(t *testing.T) {
+	lm := &LegacyMetrics{}
+	require.Empty(t, lm.Collectors(), "a zero-value LegacyMetrics has no populated fields to collect")
+
+	lm.PolicyRegenerationCount = metric.NewCounter(metric.CounterOpts{})
+	require.Len(t, lm.Collectors(), 1)
+
+	lm.ErrorsWarnings = NoOpCounterVec
+	require.Len(t, lm.Collectors(), 1, "a NoOp field must not be collected")
+
+	lm.Policy = metric.NewGauge(metric.GaugeOpts{})
+	require.Len(t, lm.Collectors(), 2)
+}
+
+// TestRecordDeferredRegistryOp verifies that the bookkeeping withRegistry
+// performs when it takes its deferred path - incrementing
+// registryDeferredOpsTotal and, once the registry eventually resolves,
+// observing the resulting delay into registryResolutionDelay - actually
+// reaches those two collectors. withRegistry's decision to defer is driven
+// by the package-level registry promise, which other tests in this package
+// resolve once and for all, so it cannot be forced here; this instead
+// exercises the exact code path withRegistry runs when it does defer.
+func TestRecordDeferredRegistryOp(t *testing.T) {
+	before := counterTotal(t, registryDeferredOpsTotal)
+	onResolved := recordDeferredRegistryOp()
+	require.Equal(t, before+1, counterTotal(t, registryDeferredOpsTotal))
+
+	beforeObservations := histogramObservations(t, registryResolutionDelay)
+	onResolved()
+	require.Equal(t, beforeObservations+1, histogramObservations(t, registryResolutionDelay))
+}
+
+// counterTotal gathers the current value of a prometheus.Counter.
+func counterTotal(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+
+	var m dto.Metric
+	require.NoError(t, c.Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+// histogramObservations gathers the number of observations recorded by a
+// prometheus.Histogram.
+func histogramObservations(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	require.NoError(t, h.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestLatencyBucketsAdoptedByHistograms(t *testing.T) {
+	lm := NewLegacyMetrics()
+
+	tests := []struct {
+		name       string
+		vec        prometheus.Collector
+		labelCount int
+	}{
+		{"EndpointRegenerationTimeStats", lm.EndpointRegenerationTimeStats, 2},
+		{"PolicyRegenerationTimeStats", lm.PolicyRegenerationTimeStats, 2},
+		{"ProxyUpstreamTime", lm.ProxyUpstreamTime, 3},
+		{"KVStoreOperationsDuration", lm.KVStoreOperationsDuration, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labelValues := make([]string, tt.labelCount)
+			for i := range labelValues {
+				labelValues[i] = "test"
+			}
+			require.Equal(t, LatencyBuckets(), histogramBuckets(t, tt.vec, labelValues...))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/cilium/hive"
@@ -60,6 +61,10 @@ type Registry struct {
 	inner *prometheus.Registry
 
 	params RegistryParams
+
+	// metrics holds every metric known to the registry, keyed by its
+	// ConfigName, including metrics that are currently disabled.
+	metrics map[string]metricpkg.WithMetadata
 }
 
 func NewRegistry(params RegistryParams) *Registry {
@@ -104,7 +109,46 @@ func NewRegistry(params RegistryParams) *Registry {
 
 // Register registers a collector
 func (r *Registry) Register(c prometheus.Collector) error {
-	return r.inner.Register(c)
+	if err := r.inner.Register(c); err != nil {
+		registryRegistrationFailuresTotal.WithLabelValues(collectorConfigName(c)).Inc()
+		return err
+	}
+	return nil
+}
+
+// collectorConfigName returns the ConfigName of c if it is one of the
+// metrics defined by the metric package, or its fully qualified
+// prometheus name otherwise. It is best-effort and only intended for
+// labeling registration failures.
+func collectorConfigName(c prometheus.Collector) string {
+	if m, ok := c.(metricpkg.WithMetadata); ok {
+		return m.Opts().GetConfigName()
+	}
+
+	descs := make(chan *prometheus.Desc, 1)
+	go func() {
+		c.Describe(descs)
+		close(descs)
+	}()
+
+	desc, ok := <-descs
+	if !ok {
+		return "unknown"
+	}
+
+	// prometheus.Desc does not expose its fqName directly, but it is the
+	// first quoted string in its String() representation, e.g.
+	// Desc{fqName: "cilium_foo", help: "...", ...}.
+	s := desc.String()
+	start := strings.Index(s, `"`)
+	if start == -1 {
+		return "unknown"
+	}
+	end := strings.Index(s[start+1:], `"`)
+	if end == -1 {
+		return "unknown"
+	}
+	return s[start+1 : start+1+end]
 }
 
 // Unregister unregisters a collector
@@ -127,11 +171,15 @@ func (r *Registry) Reinitialize() {
 		)))
 	r.MustRegister(newStatusCollector())
 	r.MustRegister(newbpfCollector())
+	r.MustRegister(registryDeferredOpsTotal)
+	r.MustRegister(registryResolutionDelay)
+	r.MustRegister(registryRegistrationFailuresTotal)
 
 	metrics := make(map[string]metricpkg.WithMetadata)
 	for i, autoMetric := range r.params.AutoMetrics {
 		metrics[autoMetric.Opts().GetConfigName()] = r.params.AutoMetrics[i]
 	}
+	r.metrics = metrics
 
 	// This is a bodge for a very specific feature, inherited from the old `Daemon.additionalMetrics`.
 	// We should really find a more generic way to handle such cases.
@@ -202,6 +250,18 @@ func (r *Registry) RegisterList(list []prometheus.Collector) error {
 	return nil
 }
 
+// ListConfigNames returns the ConfigName of every metric known to the
+// registry, including metrics that are currently disabled, so that
+// configuration referring to a metric by name can be validated against it.
+func (r *Registry) ListConfigNames() []string {
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // DumpMetrics gets the current Cilium metrics and dumps all into a
 // models.Metrics structure.If metrics cannot be retrieved, returns an error
 func (r *Registry) DumpMetrics() ([]*models.Metric, error) {
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/pkg/lock"
+	"github.com/cilium/cilium/pkg/option"
+)
+
+func TestRegistryListConfigNames(t *testing.T) {
+	lm := NewLegacyMetrics()
+
+	reg := NewRegistry(RegistryParams{
+		DaemonConfig: &option.DaemonConfig{ConfigPatchMutex: new(lock.RWMutex)},
+		AutoMetrics:  provideMetrics(lm).Metrics,
+	})
+
+	names := reg.ListConfigNames()
+	require.NotEmpty(t, names)
+	require.IsIncreasing(t, names)
+
+	require.Contains(t, names, Namespace+"_identity")
+
+	// CIDRGroupTranslationTimeStats is disabled by default, but should
+	// still be listed so that config validation recognizes its name.
+	require.Contains(t, names, Namespace+"cidrgroup_translation_time_stats_seconds")
+}
+
+// TestRegistryRegisterFailureCountsMetric verifies that a failed Register
+// call - here, a duplicate registration of the same collector - bumps
+// registryRegistrationFailuresTotal for that collector's ConfigName, since
+// Register's error return is often ignored by callers and this counter is
+// otherwise the only visible sign of the failure.
+func TestRegistryRegisterFailureCountsMetric(t *testing.T) {
+	reg := NewRegistry(RegistryParams{
+		DaemonConfig: &option.DaemonConfig{ConfigPatchMutex: new(lock.RWMutex)},
+	})
+
+	c := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "test_registry_register_failure_metric",
+		Help:      "Counter used only by TestRegistryRegisterFailureCountsMetric.",
+	})
+
+	require.NoError(t, reg.Register(c))
+
+	before := counterTotal(t, registryRegistrationFailuresTotal.WithLabelValues(collectorConfigName(c)))
+	require.Error(t, reg.Register(c))
+	require.Equal(t, before+1, counterTotal(t, registryRegistrationFailuresTotal.WithLabelValues(collectorConfigName(c))))
+}
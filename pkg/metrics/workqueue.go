@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"k8s.io/client-go/util/workqueue"
+)
+
+// WorkQueueMetricsProvider returns a workqueue.MetricsProvider backed by the
+// WorkQueue* metric vecs declared in this package, labeled by the queue's
+// name. It lets ad-hoc workqueues outside of pkg/k8s/watchers (which installs
+// its own provider via workqueue.SetProvider) be instrumented consistently by
+// passing the provider directly to workqueue.NewWithConfig,
+// workqueue.NewRateLimitingQueueWithConfig or similar constructors that take
+// a config with a MetricsProvider field.
+func WorkQueueMetricsProvider() workqueue.MetricsProvider {
+	return workQueueMetricsProvider{}
+}
+
+type workQueueMetricsProvider struct{}
+
+func (workQueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return WorkQueueDepth.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return WorkQueueAddsTotal.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return WorkQueueLatency.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return WorkQueueDuration.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return WorkQueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return WorkQueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return WorkQueueRetries.WithLabelValues(name)
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkQueueMetricsProviderTracksDepth(t *testing.T) {
+	const queueName = "test-queue-metrics-provider"
+
+	queue := workqueue.NewWithConfig(workqueue.QueueConfig{
+		Name:            queueName,
+		MetricsProvider: WorkQueueMetricsProvider(),
+	})
+	defer queue.ShutDown()
+
+	require.Equal(t, float64(0), WorkQueueDepth.WithLabelValues(queueName).Get())
+
+	queue.Add("item")
+	require.Equal(t, float64(1), WorkQueueDepth.WithLabelValues(queueName).Get())
+
+	queue.Get()
+	require.Equal(t, float64(0), WorkQueueDepth.WithLabelValues(queueName).Get())
+}
@@ -636,6 +636,14 @@ func (m *manager) NodeUpdated(n nodeTypes.Node) {
 		m.mutex.Unlock()
 		oldNode := entry.node
 		entry.node = n
+		if oldNode.EncryptionKeyChanged(n) {
+			oldKey, newKey := oldNode.EncryptionKeys(n)
+			log.WithFields(logrus.Fields{
+				logfields.NodeName: n.Name,
+				logfields.OldSPI:   oldKey,
+				logfields.SPI:      newKey,
+			}).Info("Node encryption key changed")
+		}
 		if dpUpdate {
 			var errs error
 			m.Iter(func(nh datapath.NodeHandler) {
@@ -160,6 +160,22 @@ type NodeRegistrar struct {
 	*store.SharedStore
 
 	registerStore *store.SharedStore
+
+	// StorePrefix overrides the kvstore prefix under which the node store
+	// is kept. Empty preserves the default (NodeStorePrefix), which is
+	// what every single-tenant deployment wants. Multi-tenant KVStoreMesh
+	// setups that need to scope a cluster's node state into a dedicated
+	// namespace can set this before RegisterNode is called.
+	StorePrefix string
+}
+
+// storePrefix returns the kvstore prefix under which the node store is
+// kept, defaulting to NodeStorePrefix unless overridden via StorePrefix.
+func (nr *NodeRegistrar) storePrefix() string {
+	if nr.StorePrefix != "" {
+		return nr.StorePrefix
+	}
+	return NodeStorePrefix
 }
 
 // RegisterObserver implements the store.Observer interface and sends
@@ -250,7 +266,7 @@ func (nr *NodeRegistrar) RegisterNode(n *nodeTypes.Node, manager NodeExtendedMan
 
 	// Join the shared store holding node information of entire cluster
 	nodeStore, err := store.JoinSharedStore(store.Configuration{
-		Prefix:               NodeStorePrefix,
+		Prefix:               nr.storePrefix(),
 		KeyCreator:           ValidatingKeyCreator(),
 		SharedKeyDeleteDelay: defaults.NodeDeleteDelay,
 		Observer:             NewNodeObserver(manager, source.KVStore),
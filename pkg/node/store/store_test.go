@@ -4,14 +4,67 @@
 package store
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
 
+	"github.com/cilium/cilium/pkg/kvstore"
 	"github.com/cilium/cilium/pkg/node/types"
+	"github.com/cilium/cilium/pkg/option"
+	"github.com/cilium/cilium/pkg/testutils"
 )
 
+func TestNodeRegistrarStorePrefixDefault(t *testing.T) {
+	var nr NodeRegistrar
+	require.Equal(t, NodeStorePrefix, nr.storePrefix())
+
+	nr.StorePrefix = "cilium/state/nodes/cluster-a/v1"
+	require.Equal(t, "cilium/state/nodes/cluster-a/v1", nr.storePrefix())
+}
+
+type fakeNodeExtendedManager struct {
+	synced bool
+}
+
+func (m *fakeNodeExtendedManager) NodeUpdated(n types.Node) {}
+func (m *fakeNodeExtendedManager) NodeDeleted(n types.Node) {}
+func (m *fakeNodeExtendedManager) NodeSync()                { m.synced = true }
+
+// TestNodeRegistrarStorePrefixKVStore asserts that RegisterNode uses a
+// configured StorePrefix, rather than the default NodeStorePrefix, for the
+// local node's kvstore key.
+func TestNodeRegistrarStorePrefixKVStore(t *testing.T) {
+	testutils.IntegrationTest(t)
+	for _, backendName := range []string{"etcd", "consul"} {
+		t.Run(backendName, func(t *testing.T) {
+			kvstore.SetupDummy(t, backendName)
+
+			oldKVStore := option.Config.KVStore
+			option.Config.KVStore = backendName
+			t.Cleanup(func() { option.Config.KVStore = oldKVStore })
+
+			nr := &NodeRegistrar{StorePrefix: "cilium/state/nodes/cluster-a/v1"}
+			manager := &fakeNodeExtendedManager{}
+
+			n := &types.Node{Name: "node1"}
+			require.NoError(t, nr.RegisterNode(n, manager))
+			t.Cleanup(func() { nr.SharedStore.Close(context.Background()) })
+
+			require.True(t, manager.synced)
+
+			kvs, err := kvstore.Client().ListPrefix(context.Background(), nr.StorePrefix)
+			require.NoError(t, err)
+			require.Contains(t, kvs, nr.StorePrefix+"/node1")
+
+			defaultKVs, err := kvstore.Client().ListPrefix(context.Background(), NodeStorePrefix)
+			require.NoError(t, err)
+			require.NotContains(t, defaultKVs, NodeStorePrefix+"/node1")
+		})
+	}
+}
+
 func TestValidatingNode(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -7,10 +7,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
+	"net/netip"
 	"path"
 	"slices"
+	"strconv"
+	"strings"
 
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/cilium/cilium/api/v1/models"
@@ -37,6 +42,19 @@ func (nn Identity) String() string {
 	return path.Join(nn.Cluster, nn.Name)
 }
 
+// setCIDRPool records that podCIDR was allocated from the named IPAM pool,
+// so that it can later be looked up via Node.PoolForCIDR. pool is the empty
+// string for CIDRs that come from the flat, poolless Spec.IPAM.PodCIDRs.
+func (n *Node) setCIDRPool(podCIDR *cidr.CIDR, pool string) {
+	if pool == "" {
+		return
+	}
+	if n.CIDRPools == nil {
+		n.CIDRPools = map[string]string{}
+	}
+	n.CIDRPools[podCIDR.String()] = pool
+}
+
 // appendAllocCDIR sets or appends the given podCIDR to the node.
 // If the IPv4/IPv6AllocCIDR is already set, we add the podCIDR as a secondary
 // alloc CIDR.
@@ -85,6 +103,7 @@ func ParseCiliumNode(n *ciliumv2.CiliumNode) (node Node) {
 			ipnet, err := cidr.ParseCIDR(string(podCIDR))
 			if err == nil {
 				node.appendAllocCDIR(ipnet)
+				node.setCIDRPool(ipnet, pool.Pool)
 			}
 		}
 	}
@@ -267,6 +286,13 @@ type Node struct {
 
 	// BootID is a unique node identifier generated on boot
 	BootID string
+
+	// CIDRPools maps each CIDR (in its string form) in IPv4AllocCIDR,
+	// IPv6AllocCIDR, IPv4SecondaryAllocCIDRs or IPv6SecondaryAllocCIDRs that
+	// was allocated from a named IPAM pool (CiliumNode.Spec.IPAM.Pools) to
+	// the name of that pool. CIDRs that come from the flat, poolless
+	// Spec.IPAM.PodCIDRs have no entry here. Use PoolForCIDR to query it.
+	CIDRPools map[string]string
 }
 
 // Fullname returns the node's full name including the cluster name if a
@@ -279,6 +305,107 @@ func (n *Node) Fullname() string {
 	return n.Name
 }
 
+// NodeEqualForPropagation reports whether a and b are equal with respect to
+// every field that is propagated to the kvstore / CiliumNode on a Node
+// update. It is intended to let callers such as nodediscovery skip
+// redundant update propagation when a new Node observation carries no
+// change that any consumer cares about.
+//
+// BootID is deliberately excluded: it changes on every node reboot, so a
+// BootID-only change is not treated as significant. It is still written to
+// the CiliumNode (Spec.BootID) and the kvstore whenever some other compared
+// field changes, so a skipped BootID-only update leaves the old BootID there.
+func NodeEqualForPropagation(a, b Node) bool {
+	return a.Name == b.Name &&
+		a.Cluster == b.Cluster &&
+		slices.EqualFunc(a.IPAddresses, b.IPAddresses, func(x, y Address) bool {
+			return x.Type == y.Type && x.IP.Equal(y.IP)
+		}) &&
+		cidrEqual(a.IPv4AllocCIDR, b.IPv4AllocCIDR) &&
+		cidrsEqual(a.IPv4SecondaryAllocCIDRs, b.IPv4SecondaryAllocCIDRs) &&
+		cidrEqual(a.IPv6AllocCIDR, b.IPv6AllocCIDR) &&
+		cidrsEqual(a.IPv6SecondaryAllocCIDRs, b.IPv6SecondaryAllocCIDRs) &&
+		a.IPv4HealthIP.Equal(b.IPv4HealthIP) &&
+		a.IPv6HealthIP.Equal(b.IPv6HealthIP) &&
+		a.IPv4IngressIP.Equal(b.IPv4IngressIP) &&
+		a.IPv6IngressIP.Equal(b.IPv6IngressIP) &&
+		a.ClusterID == b.ClusterID &&
+		a.Source == b.Source &&
+		a.EncryptionKey == b.EncryptionKey &&
+		maps.Equal(a.Labels, b.Labels) &&
+		maps.Equal(a.Annotations, b.Annotations) &&
+		a.NodeIdentity == b.NodeIdentity &&
+		a.WireguardPubKey == b.WireguardPubKey &&
+		maps.Equal(a.CIDRPools, b.CIDRPools)
+}
+
+// EncryptionKeyChanged reports whether other carries a different
+// EncryptionKey than n, e.g. because an IPSec key rotation has taken place.
+func (n *Node) EncryptionKeyChanged(other Node) bool {
+	return n.EncryptionKey != other.EncryptionKey
+}
+
+// EncryptionKeys returns the old (n's) and new (other's) EncryptionKey, for
+// callers that already know the key changed (see EncryptionKeyChanged) and
+// want to react to the specific transition, e.g. to distinguish a rotation
+// from a reset to no encryption.
+func (n *Node) EncryptionKeys(other Node) (old, new uint8) {
+	return n.EncryptionKey, other.EncryptionKey
+}
+
+// MergeNodes merges incoming into existing and returns the result, applying
+// source.AllowOverwrite to decide which of the two provides the base for
+// the merge. The loser's addresses, health IPs, ingress IPs and encryption
+// key are preserved where the winner leaves them unset, so that merging a
+// node known from two different sources (e.g. kvstore and CustomResource)
+// does not drop information the winning source doesn't carry.
+//
+// This does not mutate existing or incoming.
+func MergeNodes(existing, incoming Node) Node {
+	winner, loser := existing, incoming
+	if source.AllowOverwrite(existing.Source, incoming.Source) {
+		winner, loser = incoming, existing
+	}
+
+	merged := winner
+
+	if len(merged.IPAddresses) == 0 {
+		merged.IPAddresses = loser.IPAddresses
+	}
+	if merged.IPv4HealthIP == nil {
+		merged.IPv4HealthIP = loser.IPv4HealthIP
+	}
+	if merged.IPv6HealthIP == nil {
+		merged.IPv6HealthIP = loser.IPv6HealthIP
+	}
+	if merged.IPv4IngressIP == nil {
+		merged.IPv4IngressIP = loser.IPv4IngressIP
+	}
+	if merged.IPv6IngressIP == nil {
+		merged.IPv6IngressIP = loser.IPv6IngressIP
+	}
+	if merged.EncryptionKey == 0 {
+		merged.EncryptionKey = loser.EncryptionKey
+	}
+
+	return merged
+}
+
+// cidrEqual reports whether a and b represent the same CIDR, treating nil
+// as a valid value distinct from any non-nil CIDR.
+func cidrEqual(a, b *cidr.CIDR) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.DeepEqual(b)
+}
+
+// cidrsEqual reports whether a and b contain the same CIDRs in the same
+// order.
+func cidrsEqual(a, b []*cidr.CIDR) bool {
+	return slices.EqualFunc(a, b, cidrEqual)
+}
+
 // Address is a node address which contains an IP and the address type.
 //
 // +k8s:deepcopy-gen=true
@@ -306,6 +433,19 @@ func (n *Node) GetNodeIP(ipv6 bool) net.IP {
 	return addressing.ExtractNodeIP[Address](n.IPAddresses, ipv6)
 }
 
+// HasIPFamily returns true if the node has any address, of any type
+// (NodeInternalIP, NodeExternalIP, CiliumInternalIP, ...), of the requested
+// address family.
+func (n *Node) HasIPFamily(ipv6 bool) bool {
+	for _, addr := range n.IPAddresses {
+		if (ipv6 && addr.IP.To4() == nil) || (!ipv6 && addr.IP.To4() != nil) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetExternalIP returns ExternalIP of k8s Node. If not present, then it
 // returns nil;
 func (n *Node) GetExternalIP(ipv6 bool) net.IP {
@@ -583,6 +723,36 @@ func (n *Node) IsLocal() bool {
 	return n != nil && n.Name == GetName() && n.Cluster == getCluster()
 }
 
+// IsLocalNode returns true if n is the node on which the agent itself is
+// running on, i.e. n's name and cluster match the local name and cluster
+// most recently set with SetName() (or its os.Hostname()/"localhost"
+// defaults, see GetName()) and the configured ClusterName. Unlike IsLocal,
+// this takes a Node by value, so it can be used without first obtaining a
+// pointer to one, e.g. when classifying a Node freshly decoded off the wire.
+func IsLocalNode(n Node) bool {
+	return n.Name == GetName() && n.Cluster == getCluster()
+}
+
+// HasLabel returns true if n has a label key with exactly the value value.
+// A nil or empty Labels map never matches.
+func (n *Node) HasLabel(key, value string) bool {
+	v, ok := n.Labels[key]
+	return ok && v == value
+}
+
+// MatchLabels returns true if n carries every key/value pair in selector. An
+// empty selector always matches. Matching is exact: a key present in
+// selector but missing from n.Labels, or present with a different value,
+// fails the match.
+func (n *Node) MatchLabels(selector map[string]string) bool {
+	for k, v := range selector {
+		if !n.HasLabel(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
 func (n *Node) GetIPv4AllocCIDRs() []*cidr.CIDR {
 	result := make([]*cidr.CIDR, 0, len(n.IPv4SecondaryAllocCIDRs)+1)
 	if n.IPv4AllocCIDR != nil {
@@ -605,6 +775,97 @@ func (n *Node) GetIPv6AllocCIDRs() []*cidr.CIDR {
 	return result
 }
 
+// GetAllAllocCIDRs returns all allocation CIDRs, IPv4 followed by IPv6,
+// primary followed by secondary within each family.
+func (n *Node) GetAllAllocCIDRs() []*cidr.CIDR {
+	v4 := n.GetIPv4AllocCIDRs()
+	v6 := n.GetIPv6AllocCIDRs()
+	result := make([]*cidr.CIDR, 0, len(v4)+len(v6))
+	result = append(result, v4...)
+	result = append(result, v6...)
+	return result
+}
+
+// PoolForCIDR returns the name of the IPAM pool that c was allocated from,
+// and whether one is known for it. It returns false for nil c, for CIDRs
+// that came from the flat Spec.IPAM.PodCIDRs list, and for CIDRs not
+// currently allocated to this node.
+func (n *Node) PoolForCIDR(c *cidr.CIDR) (pool string, ok bool) {
+	if c == nil {
+		return "", false
+	}
+	pool, ok = n.CIDRPools[c.String()]
+	return
+}
+
+// GetBGPRouterIDs parses the node's BGP virtual router configuration and
+// returns the configured router ID for each local ASN. Two annotation forms
+// are recognized and may be combined on the same node: the per-ASN
+// `cilium.io/bgp-virtual-router.{asn}` annotations, and the single
+// annotation.BGPVRoutersAnnoKey annotation carrying a JSON object of all
+// ASNs at once. Where both forms configure the same ASN, the per-ASN
+// annotation takes precedence, matching the order in which they're merged
+// in below. An error is returned for a malformed ASN, router-id, or JSON
+// blob; parsing continues and the returned map still contains any router
+// IDs that were successfully parsed.
+func (n *Node) GetBGPRouterIDs() (map[uint32]netip.Addr, error) {
+	routerIDs := make(map[uint32]netip.Addr)
+	var errs error
+
+	if blob, ok := n.Annotations[annotation.BGPVRoutersAnnoKey]; ok {
+		var routers map[string]string
+		if err := json.Unmarshal([]byte(blob), &routers); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("annotation %s is not valid JSON: %w", annotation.BGPVRoutersAnnoKey, err))
+		}
+
+		for asnStr, routerIDStr := range routers {
+			asn, err := strconv.ParseUint(asnStr, 10, 32)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("annotation %s has an invalid ASN %q: %w", annotation.BGPVRoutersAnnoKey, asnStr, err))
+				continue
+			}
+
+			routerID, err := netip.ParseAddr(routerIDStr)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("annotation %s has an invalid router-id %q for ASN %q: %w", annotation.BGPVRoutersAnnoKey, routerIDStr, asnStr, err))
+				continue
+			}
+
+			routerIDs[uint32(asn)] = routerID
+		}
+	}
+
+	for key, value := range n.Annotations {
+		if !strings.HasPrefix(key, annotation.BGPVRouterAnnoPrefix) {
+			continue
+		}
+
+		asnStr := strings.TrimPrefix(key, annotation.BGPVRouterAnnoPrefix)
+		asn, err := strconv.ParseUint(asnStr, 10, 32)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("annotation %s has an invalid ASN %q: %w", key, asnStr, err))
+			continue
+		}
+
+		for _, attr := range strings.Split(value, ",") {
+			attrKey, attrValue, ok := strings.Cut(attr, "=")
+			if !ok || attrKey != "router-id" {
+				continue
+			}
+
+			routerID, err := netip.ParseAddr(attrValue)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("annotation %s has an invalid router-id %q: %w", key, attrValue, err))
+				continue
+			}
+
+			routerIDs[uint32(asn)] = routerID
+		}
+	}
+
+	return routerIDs, errs
+}
+
 // GetKeyNodeName constructs the API name for the given cluster and node name.
 func GetKeyNodeName(cluster, node string) string {
 	// WARNING - STABLE API: Changing the structure of the key may break
@@ -668,5 +929,11 @@ func (n *Node) validate() error {
 		}
 	}
 
+	if n.WireguardPubKey != "" {
+		if _, err := wgtypes.ParseKey(n.WireguardPubKey); err != nil {
+			return fmt.Errorf("invalid wireguard public key: %w", err)
+		}
+	}
+
 	return nil
 }
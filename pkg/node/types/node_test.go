@@ -4,8 +4,10 @@
 package types
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net"
+	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,6 +19,7 @@ import (
 	ipamTypes "github.com/cilium/cilium/pkg/ipam/types"
 	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
 	"github.com/cilium/cilium/pkg/node/addressing"
+	"github.com/cilium/cilium/pkg/option"
 	"github.com/cilium/cilium/pkg/source"
 )
 
@@ -111,6 +114,49 @@ func TestGetIPByType(t *testing.T) {
 	require.Equal(t, ip, net.ParseIP("f00d::1"))
 }
 
+func TestHasIPFamily(t *testing.T) {
+	t.Run("empty node", func(t *testing.T) {
+		n := Node{Name: "node-1"}
+		assert.False(t, n.HasIPFamily(false))
+		assert.False(t, n.HasIPFamily(true))
+	})
+
+	t.Run("ipv4 only", func(t *testing.T) {
+		n := Node{
+			Name: "node-2",
+			IPAddresses: []Address{
+				{IP: net.ParseIP("192.0.2.3"), Type: addressing.NodeInternalIP},
+				{IP: net.ParseIP("192.0.2.4"), Type: addressing.NodeExternalIP},
+			},
+		}
+		assert.True(t, n.HasIPFamily(false))
+		assert.False(t, n.HasIPFamily(true))
+	})
+
+	t.Run("ipv6 only", func(t *testing.T) {
+		n := Node{
+			Name: "node-3",
+			IPAddresses: []Address{
+				{IP: net.ParseIP("2001:DB8::1"), Type: addressing.NodeCiliumInternalIP},
+			},
+		}
+		assert.False(t, n.HasIPFamily(false))
+		assert.True(t, n.HasIPFamily(true))
+	})
+
+	t.Run("dual stack", func(t *testing.T) {
+		n := Node{
+			Name: "node-4",
+			IPAddresses: []Address{
+				{IP: net.ParseIP("198.51.100.2"), Type: addressing.NodeInternalIP},
+				{IP: net.ParseIP("2001:DB8::2"), Type: addressing.NodeCiliumInternalIP},
+			},
+		}
+		assert.True(t, n.HasIPFamily(false))
+		assert.True(t, n.HasIPFamily(true))
+	})
+}
+
 func TestParseCiliumNode(t *testing.T) {
 	nodeResource := &ciliumv2.CiliumNode{
 		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
@@ -167,6 +213,81 @@ func TestParseCiliumNode(t *testing.T) {
 	}, n)
 }
 
+func TestParseCiliumNodeWithPools(t *testing.T) {
+	nodeResource := &ciliumv2.CiliumNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: ciliumv2.NodeSpec{
+			IPAM: ipamTypes.IPAMSpec{
+				PodCIDRs: []string{
+					"10.10.0.0/16",
+				},
+				Pools: ipamTypes.IPAMPoolSpec{
+					Allocated: []ipamTypes.IPAMPoolAllocation{
+						{
+							Pool: "pool-a",
+							CIDRs: []ipamTypes.IPAMPodCIDR{
+								"10.20.0.0/16",
+							},
+						},
+						{
+							Pool: "pool-b",
+							CIDRs: []ipamTypes.IPAMPodCIDR{
+								"10.30.0.0/16",
+								"c0fe::/96",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	n := ParseCiliumNode(nodeResource)
+
+	// The CIDR from the flat, poolless PodCIDRs list becomes the primary
+	// alloc CIDR and has no pool recorded for it.
+	require.Equal(t, cidr.MustParseCIDR("10.10.0.0/16"), n.IPv4AllocCIDR)
+	pool, ok := n.PoolForCIDR(n.IPv4AllocCIDR)
+	require.False(t, ok)
+	require.Empty(t, pool)
+
+	// The CIDRs sourced from named pools are appended as secondary alloc
+	// CIDRs in the order the pools were listed, and each one's pool is
+	// recoverable via PoolForCIDR.
+	require.Equal(t, []*cidr.CIDR{
+		cidr.MustParseCIDR("10.20.0.0/16"),
+		cidr.MustParseCIDR("10.30.0.0/16"),
+	}, n.IPv4SecondaryAllocCIDRs)
+
+	// No flat IPv6 podCIDR was given, so the first pool-sourced IPv6 CIDR
+	// became the primary IPv6 alloc CIDR instead of a secondary one; it
+	// still has its pool recorded.
+	require.Equal(t, cidr.MustParseCIDR("c0fe::/96"), n.IPv6AllocCIDR)
+	require.Empty(t, n.IPv6SecondaryAllocCIDRs)
+
+	pool, ok = n.PoolForCIDR(cidr.MustParseCIDR("10.20.0.0/16"))
+	require.True(t, ok)
+	require.Equal(t, "pool-a", pool)
+
+	pool, ok = n.PoolForCIDR(cidr.MustParseCIDR("10.30.0.0/16"))
+	require.True(t, ok)
+	require.Equal(t, "pool-b", pool)
+
+	pool, ok = n.PoolForCIDR(cidr.MustParseCIDR("c0fe::/96"))
+	require.True(t, ok)
+	require.Equal(t, "pool-b", pool)
+
+	// A CIDR not allocated to this node at all has no pool.
+	pool, ok = n.PoolForCIDR(cidr.MustParseCIDR("192.168.0.0/24"))
+	require.False(t, ok)
+	require.Empty(t, pool)
+
+	// A nil CIDR is handled gracefully.
+	pool, ok = n.PoolForCIDR(nil)
+	require.False(t, ok)
+	require.Empty(t, pool)
+}
+
 func TestNode_ToCiliumNode(t *testing.T) {
 	nodeResource := Node{
 		Name:   "foo",
@@ -264,6 +385,26 @@ func TestNodeValidate(t *testing.T) {
 			node:   Node{Cluster: "foo", Name: "bar", ClusterID: 260},
 			assert: assert.Error,
 		},
+		{
+			name:   "empty wireguard public key",
+			node:   Node{Cluster: "foo", Name: "bar"},
+			assert: assert.NoError,
+		},
+		{
+			name:   "valid wireguard public key",
+			node:   Node{Cluster: "foo", Name: "bar", WireguardPubKey: "IdMzpun9LV4IdSX0zCwcShq3tLGOXP6xZjRYi7mORkk="},
+			assert: assert.NoError,
+		},
+		{
+			name:   "malformed base64 wireguard public key",
+			node:   Node{Cluster: "foo", Name: "bar", WireguardPubKey: "not-valid-base64!!"},
+			assert: assert.Error,
+		},
+		{
+			name:   "wrong length wireguard public key",
+			node:   Node{Cluster: "foo", Name: "bar", WireguardPubKey: base64.StdEncoding.EncodeToString([]byte("too short"))},
+			assert: assert.Error,
+		},
 	}
 
 	for _, tt := range tests {
@@ -384,3 +525,377 @@ func TestGetIPv6AllocCIDRs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllAllocCIDRs(t *testing.T) {
+	var (
+		cidr1    = cidr.MustParseCIDR("1.0.0.0/24")
+		cidr2    = cidr.MustParseCIDR("2.0.0.0/24")
+		cidr2001 = cidr.MustParseCIDR("2001:db8::/32")
+		cidr2002 = cidr.MustParseCIDR("2002:db8::/32")
+	)
+
+	var tests = []struct {
+		name                    string
+		ipv4AllocCIDR           *cidr.CIDR
+		ipv4SecondaryAllocCIDRs []*cidr.CIDR
+		ipv6AllocCIDR           *cidr.CIDR
+		ipv6SecondaryAllocCIDRs []*cidr.CIDR
+		expectedCIDRs           []*cidr.CIDR
+	}{
+		{
+			name:          "nil cidrs",
+			expectedCIDRs: []*cidr.CIDR{},
+		},
+		{
+			name:          "only ipv4",
+			ipv4AllocCIDR: cidr1,
+			expectedCIDRs: []*cidr.CIDR{cidr1},
+		},
+		{
+			name:          "only ipv6",
+			ipv6AllocCIDR: cidr2001,
+			expectedCIDRs: []*cidr.CIDR{cidr2001},
+		},
+		{
+			name:                    "both families with secondaries, ipv4 before ipv6",
+			ipv4AllocCIDR:           cidr1,
+			ipv4SecondaryAllocCIDRs: []*cidr.CIDR{cidr2},
+			ipv6AllocCIDR:           cidr2001,
+			ipv6SecondaryAllocCIDRs: []*cidr.CIDR{cidr2002},
+			expectedCIDRs:           []*cidr.CIDR{cidr1, cidr2, cidr2001, cidr2002},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Node{
+				Name:                    fmt.Sprintf("node-%s", tt.name),
+				IPv4AllocCIDR:           tt.ipv4AllocCIDR,
+				IPv4SecondaryAllocCIDRs: tt.ipv4SecondaryAllocCIDRs,
+				IPv6AllocCIDR:           tt.ipv6AllocCIDR,
+				IPv6SecondaryAllocCIDRs: tt.ipv6SecondaryAllocCIDRs,
+			}
+
+			assert.Equal(t, tt.expectedCIDRs, n.GetAllAllocCIDRs())
+		})
+	}
+}
+
+func TestIsLocalNode(t *testing.T) {
+	oldName := GetName()
+	oldCluster := option.Config.ClusterName
+	defer func() {
+		SetName(oldName)
+		option.Config.ClusterName = oldCluster
+	}()
+
+	SetName("local-node")
+	option.Config.ClusterName = "cluster1"
+
+	assert.True(t, IsLocalNode(Node{Name: "local-node", Cluster: "cluster1"}))
+	assert.False(t, IsLocalNode(Node{Name: "remote-node", Cluster: "cluster1"}))
+	assert.False(t, IsLocalNode(Node{Name: "local-node", Cluster: "cluster2"}))
+}
+
+func TestNodeHasLabel(t *testing.T) {
+	n := &Node{Labels: map[string]string{"role": "control-plane"}}
+
+	assert.True(t, n.HasLabel("role", "control-plane"))
+	assert.False(t, n.HasLabel("role", "worker"))
+	assert.False(t, n.HasLabel("missing", "control-plane"))
+
+	empty := &Node{}
+	assert.False(t, empty.HasLabel("role", "control-plane"))
+}
+
+func TestNodeMatchLabels(t *testing.T) {
+	n := &Node{Labels: map[string]string{
+		"role": "control-plane",
+		"zone": "us-west",
+	}}
+
+	assert.True(t, n.MatchLabels(nil))
+	assert.True(t, n.MatchLabels(map[string]string{}))
+	assert.True(t, n.MatchLabels(map[string]string{"role": "control-plane"}))
+	assert.True(t, n.MatchLabels(map[string]string{"role": "control-plane", "zone": "us-west"}))
+	assert.False(t, n.MatchLabels(map[string]string{"role": "worker"}))
+	assert.False(t, n.MatchLabels(map[string]string{"role": "control-plane", "zone": "us-east"}))
+	assert.False(t, n.MatchLabels(map[string]string{"missing": "label"}))
+
+	empty := &Node{}
+	assert.True(t, empty.MatchLabels(nil))
+	assert.False(t, empty.MatchLabels(map[string]string{"role": "control-plane"}))
+}
+
+func TestGetBGPRouterIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    map[uint32]netip.Addr
+		assertErr   assert.ErrorAssertionFunc
+	}{
+		{
+			name: "valid router-id",
+			annotations: map[string]string{
+				annotation.BGPVRouterAnnoPrefix + "64512": "router-id=172.0.0.3",
+			},
+			expected: map[uint32]netip.Addr{
+				64512: netip.MustParseAddr("172.0.0.3"),
+			},
+			assertErr: assert.NoError,
+		},
+		{
+			name: "extra kv pairs are ignored",
+			annotations: map[string]string{
+				annotation.BGPVRouterAnnoPrefix + "64512": "local-port=179,router-id=172.0.0.3",
+			},
+			expected: map[uint32]netip.Addr{
+				64512: netip.MustParseAddr("172.0.0.3"),
+			},
+			assertErr: assert.NoError,
+		},
+		{
+			name: "unrelated annotations are ignored",
+			annotations: map[string]string{
+				annotation.BGPVRouterAnnoPrefix + "64512": "router-id=172.0.0.3",
+				"unrelated.annotation/foo":                "bar",
+			},
+			expected: map[uint32]netip.Addr{
+				64512: netip.MustParseAddr("172.0.0.3"),
+			},
+			assertErr: assert.NoError,
+		},
+		{
+			name: "malformed ASN",
+			annotations: map[string]string{
+				annotation.BGPVRouterAnnoPrefix + "not-an-asn": "router-id=172.0.0.3",
+			},
+			expected:  map[uint32]netip.Addr{},
+			assertErr: assert.Error,
+		},
+		{
+			name: "malformed router-id",
+			annotations: map[string]string{
+				annotation.BGPVRouterAnnoPrefix + "64512": "router-id=not-an-ip",
+			},
+			expected:  map[uint32]netip.Addr{},
+			assertErr: assert.Error,
+		},
+		{
+			name: "JSON blob annotation",
+			annotations: map[string]string{
+				annotation.BGPVRoutersAnnoKey: `{"64512":"172.0.0.3","64513":"172.0.0.4"}`,
+			},
+			expected: map[uint32]netip.Addr{
+				64512: netip.MustParseAddr("172.0.0.3"),
+				64513: netip.MustParseAddr("172.0.0.4"),
+			},
+			assertErr: assert.NoError,
+		},
+		{
+			name: "malformed JSON blob annotation",
+			annotations: map[string]string{
+				annotation.BGPVRoutersAnnoKey: `not-json`,
+			},
+			expected:  map[uint32]netip.Addr{},
+			assertErr: assert.Error,
+		},
+		{
+			name: "JSON blob with invalid ASN or router-id",
+			annotations: map[string]string{
+				annotation.BGPVRoutersAnnoKey: `{"not-an-asn":"172.0.0.3","64512":"not-an-ip"}`,
+			},
+			expected:  map[uint32]netip.Addr{},
+			assertErr: assert.Error,
+		},
+		{
+			name: "mixed node with both annotation forms",
+			annotations: map[string]string{
+				annotation.BGPVRoutersAnnoKey:             `{"64512":"172.0.0.3"}`,
+				annotation.BGPVRouterAnnoPrefix + "64513": "router-id=172.0.0.4",
+			},
+			expected: map[uint32]netip.Addr{
+				64512: netip.MustParseAddr("172.0.0.3"),
+				64513: netip.MustParseAddr("172.0.0.4"),
+			},
+			assertErr: assert.NoError,
+		},
+		{
+			name: "per-ASN annotation takes precedence over the JSON blob for the same ASN",
+			annotations: map[string]string{
+				annotation.BGPVRoutersAnnoKey:             `{"64512":"172.0.0.3"}`,
+				annotation.BGPVRouterAnnoPrefix + "64512": "router-id=172.0.0.9",
+			},
+			expected: map[uint32]netip.Addr{
+				64512: netip.MustParseAddr("172.0.0.9"),
+			},
+			assertErr: assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Node{Annotations: tt.annotations}
+			routerIDs, err := n.GetBGPRouterIDs()
+			tt.assertErr(t, err)
+			assert.Equal(t, tt.expected, routerIDs)
+		})
+	}
+}
+
+func TestNodeEqualForPropagation(t *testing.T) {
+	base := Node{
+		Name:          "node1",
+		Cluster:       "cluster1",
+		IPAddresses:   []Address{{Type: addressing.NodeInternalIP, IP: net.ParseIP("10.0.0.1")}},
+		IPv4AllocCIDR: cidr.MustParseCIDR("10.1.0.0/24"),
+		ClusterID:     1,
+		Source:        source.Kubernetes,
+		EncryptionKey: 1,
+		Labels:        map[string]string{"foo": "bar"},
+		NodeIdentity:  42,
+		BootID:        "boot-a",
+	}
+
+	t.Run("identical nodes are equal", func(t *testing.T) {
+		other := base
+		assert.True(t, NodeEqualForPropagation(base, other))
+	})
+
+	t.Run("BootID-only change is ignored", func(t *testing.T) {
+		other := base
+		other.BootID = "boot-b"
+		assert.True(t, NodeEqualForPropagation(base, other))
+	})
+
+	t.Run("EncryptionKey change is significant", func(t *testing.T) {
+		other := base
+		other.EncryptionKey = 2
+		assert.False(t, NodeEqualForPropagation(base, other))
+	})
+
+	t.Run("Labels change is significant", func(t *testing.T) {
+		other := base
+		other.Labels = map[string]string{"foo": "baz"}
+		assert.False(t, NodeEqualForPropagation(base, other))
+	})
+
+	t.Run("IPv4AllocCIDR change is significant", func(t *testing.T) {
+		other := base
+		other.IPv4AllocCIDR = cidr.MustParseCIDR("10.2.0.0/24")
+		assert.False(t, NodeEqualForPropagation(base, other))
+	})
+}
+
+func TestMergeNodes(t *testing.T) {
+	healthV4 := net.ParseIP("10.0.1.1")
+	ingressV4 := net.ParseIP("10.0.2.1")
+
+	t.Run("same-source update takes the incoming value", func(t *testing.T) {
+		existing := Node{
+			Name:          "node1",
+			Source:        source.Kubernetes,
+			Labels:        map[string]string{"foo": "bar"},
+			EncryptionKey: 1,
+		}
+		incoming := Node{
+			Name:          "node1",
+			Source:        source.Kubernetes,
+			Labels:        map[string]string{"foo": "baz"},
+			EncryptionKey: 2,
+		}
+
+		merged := MergeNodes(existing, incoming)
+		assert.Equal(t, map[string]string{"foo": "baz"}, merged.Labels)
+		assert.EqualValues(t, 2, merged.EncryptionKey)
+	})
+
+	t.Run("higher-priority incoming source wins over existing", func(t *testing.T) {
+		existing := Node{
+			Name:          "node1",
+			Source:        source.Kubernetes,
+			EncryptionKey: 1,
+			IPv4HealthIP:  healthV4,
+		}
+		incoming := Node{
+			Name:          "node1",
+			Source:        source.KVStore,
+			EncryptionKey: 2,
+		}
+
+		merged := MergeNodes(existing, incoming)
+		assert.Equal(t, source.KVStore, merged.Source)
+		assert.EqualValues(t, 2, merged.EncryptionKey, "kvstore source should win over kubernetes")
+		// incoming doesn't carry a health IP, so the one known from the
+		// losing source is preserved rather than dropped.
+		assert.True(t, healthV4.Equal(merged.IPv4HealthIP))
+	})
+
+	t.Run("lower-priority incoming source loses to existing", func(t *testing.T) {
+		existing := Node{
+			Name:          "node1",
+			Source:        source.KVStore,
+			EncryptionKey: 1,
+			IPv4IngressIP: ingressV4,
+		}
+		incoming := Node{
+			Name:          "node1",
+			Source:        source.Kubernetes,
+			EncryptionKey: 2,
+			IPv4IngressIP: net.ParseIP("10.0.2.2"),
+		}
+
+		merged := MergeNodes(existing, incoming)
+		assert.Equal(t, source.KVStore, merged.Source)
+		assert.EqualValues(t, 1, merged.EncryptionKey, "kubernetes source must not overwrite kvstore state")
+		assert.True(t, ingressV4.Equal(merged.IPv4IngressIP))
+	})
+
+	t.Run("winner's addresses are preferred over loser's when both are set", func(t *testing.T) {
+		existing := Node{
+			Name:        "node1",
+			Source:      source.Kubernetes,
+			IPAddresses: []Address{{Type: addressing.NodeInternalIP, IP: net.ParseIP("10.0.0.1")}},
+		}
+		incoming := Node{
+			Name:        "node1",
+			Source:      source.KVStore,
+			IPAddresses: []Address{{Type: addressing.NodeInternalIP, IP: net.ParseIP("10.0.0.2")}},
+		}
+
+		merged := MergeNodes(existing, incoming)
+		require.Len(t, merged.IPAddresses, 1)
+		assert.True(t, net.ParseIP("10.0.0.2").Equal(merged.IPAddresses[0].IP))
+	})
+}
+
+func TestNodeEncryptionKeyChanged(t *testing.T) {
+	t.Run("no change", func(t *testing.T) {
+		old := Node{Name: "node1", EncryptionKey: 1}
+		updated := Node{Name: "node1", EncryptionKey: 1}
+
+		assert.False(t, old.EncryptionKeyChanged(updated))
+		oldKey, newKey := old.EncryptionKeys(updated)
+		assert.EqualValues(t, 1, oldKey)
+		assert.EqualValues(t, 1, newKey)
+	})
+
+	t.Run("incremented on rotation", func(t *testing.T) {
+		old := Node{Name: "node1", EncryptionKey: 1}
+		updated := Node{Name: "node1", EncryptionKey: 2}
+
+		assert.True(t, old.EncryptionKeyChanged(updated))
+		oldKey, newKey := old.EncryptionKeys(updated)
+		assert.EqualValues(t, 1, oldKey)
+		assert.EqualValues(t, 2, newKey)
+	})
+
+	t.Run("reset to zero", func(t *testing.T) {
+		old := Node{Name: "node1", EncryptionKey: 2}
+		updated := Node{Name: "node1", EncryptionKey: 0}
+
+		assert.True(t, old.EncryptionKeyChanged(updated))
+		oldKey, newKey := old.EncryptionKeys(updated)
+		assert.EqualValues(t, 2, oldKey)
+		assert.EqualValues(t, 0, newKey)
+	})
+}
@@ -107,6 +107,13 @@ func (in *Node) DeepCopyInto(out *Node) {
 			(*out)[key] = val
 		}
 	}
+	if in.CIDRPools != nil {
+		in, out := &in.CIDRPools, &out.CIDRPools
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
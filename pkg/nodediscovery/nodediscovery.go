@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	stdnet "net"
 	"slices"
 	"strings"
 
@@ -71,6 +72,16 @@ type NodeDiscovery struct {
 	localNodeStore        *node.LocalNodeStore
 	clientset             client.Clientset
 	ctrlmgr               *controller.Manager
+
+	// KeyPrefix overrides the kvstore prefix under which the local node's
+	// kvstore state is kept, and is used to derive the controller group
+	// that propagates local node updates to the kvstore. Empty preserves
+	// the default (node/store.NodeStorePrefix and the unsuffixed
+	// "local-node-to-kv-store" group), which is what every single-tenant
+	// deployment wants. Multi-tenant KVStoreMesh setups that need to scope
+	// a cluster's node state into a dedicated namespace should set this
+	// before calling StartDiscovery.
+	KeyPrefix string
 }
 
 // NewNodeDiscovery returns a pointer to new node discovery object
@@ -151,6 +162,11 @@ func (n *NodeDiscovery) JoinCluster(nodeName string) error {
 // agent startup to configure the local node based on the configuration options
 // passed to the agent. nodeName is the name to be used in the local agent.
 func (n *NodeDiscovery) StartDiscovery() {
+	// Propagate the configured key prefix, if any, to the registrar before
+	// it joins the shared store, so that the local node's kvstore state
+	// lands under the right namespace from the very first RegisterNode().
+	n.Registrar.StorePrefix = n.KeyPrefix
+
 	// Start observing local node changes, so that we keep the corresponding CiliumNode
 	// and kvstore representations in sync. The first update is performed synchronously
 	// so that they are guaranteed to exist when StartDiscovery returns.
@@ -191,7 +207,16 @@ func (n *NodeDiscovery) StartDiscovery() {
 
 	go func() {
 		// Propagate all updates to the CiliumNode and kvstore representations.
+		prevNode := localNode.Node
 		for ln := range updates {
+			// Skip propagation entirely if no field compared by
+			// NodeEqualForPropagation changed, e.g. a debounced update that
+			// only touched BootID, which it deliberately ignores.
+			if nodeTypes.NodeEqualForPropagation(prevNode, ln.Node) {
+				continue
+			}
+			prevNode = ln.Node
+
 			// We want to propagate a local node update back into the Manager.
 			// This is particularly helpful when an IPSec key rotation occurs
 			// and the manager needs to evaluate the local node's EncryptionKey
@@ -209,12 +234,23 @@ func (n *NodeDiscovery) WaitForLocalNodeInit() {
 	<-n.localStateInitialized
 }
 
+// localNodeToKVStoreGroup returns the controller group used to propagate
+// local node updates to the kvstore, suffixed with KeyPrefix so that
+// multiple NodeDiscovery instances scoped to different KVStoreMesh
+// namespaces can be told apart in controller status/metrics.
+func (n *NodeDiscovery) localNodeToKVStoreGroup() controller.Group {
+	if n.KeyPrefix == "" {
+		return localNodeToKVStoreControllerGroup
+	}
+	return controller.NewGroup(localNodeToKVStoreControllerGroup.Name + "-" + n.KeyPrefix)
+}
+
 func (n *NodeDiscovery) updateLocalNode(ln *node.LocalNode) {
 	if option.Config.KVStore != "" && !option.Config.JoinCluster {
 		n.ctrlmgr.UpdateController(
 			"propagating local node change to kv-store",
 			controller.ControllerParams{
-				Group:                localNodeToKVStoreControllerGroup,
+				Group:                n.localNodeToKVStoreGroup(),
 				CancelDoFuncOnUpdate: true,
 				DoFunc: func(ctx context.Context) error {
 					select {
@@ -321,6 +357,41 @@ func (n *NodeDiscovery) updateCiliumNodeResource(ln *node.LocalNode) {
 	log.Fatalf("Could not create or update CiliumNode resource, despite %d retries", maxRetryCount)
 }
 
+// warnConflictingReservedLabels logs a warning for every Cilium-reserved
+// label (i.e. prefixed with defaults.CiliumK8sAnnotationPrefix) present in
+// oldLabels whose value is about to be changed by newLabels. This is meant
+// to catch misconfigurations where a user label accidentally collides with
+// a label namespace managed by Cilium.
+func warnConflictingReservedLabels(oldLabels, newLabels map[string]string) {
+	for key, oldValue := range oldLabels {
+		if !strings.HasPrefix(key, defaults.CiliumK8sAnnotationPrefix) {
+			continue
+		}
+		if newValue, ok := newLabels[key]; ok && newValue != oldValue {
+			log.WithFields(logrus.Fields{
+				logfields.ConflictingKey: key,
+				"oldValue":               oldValue,
+				"newValue":               newValue,
+			}).Warning("Cilium-reserved node label is being overwritten by a user label")
+		}
+	}
+}
+
+// mutateFamilyAddress sets *field to ip's string representation if ip is
+// non-nil, clears it if enabled is false, and otherwise leaves the existing
+// value untouched. This mirrors the CiliumInternalIP preservation logic in
+// mutateNodeResource: this function can be called before a family's health
+// or ingress IP has been restored or allocated yet, in which case clearing
+// the field would make it flap to empty and back once the IP becomes known.
+func mutateFamilyAddress(field *string, enabled bool, ip stdnet.IP) {
+	switch {
+	case !enabled:
+		*field = ""
+	case ip != nil:
+		*field = ip.String()
+	}
+}
+
 func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln *node.LocalNode) error {
 	nodeResource.ObjectMeta.OwnerReferences = []metav1.OwnerReference{{
 		APIVersion: "v1",
@@ -329,6 +400,8 @@ func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln
 		UID:        ln.UID,
 	}}
 
+	warnConflictingReservedLabels(nodeResource.ObjectMeta.Labels, ln.Labels)
+
 	nodeResource.ObjectMeta.Labels = ln.Labels
 	nodeResource.ObjectMeta.Annotations = ln.Annotations
 
@@ -384,25 +457,10 @@ func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln
 
 	nodeResource.Spec.Encryption.Key = int(ln.EncryptionKey)
 
-	nodeResource.Spec.HealthAddressing.IPv4 = ""
-	if ip := ln.IPv4HealthIP; ip != nil {
-		nodeResource.Spec.HealthAddressing.IPv4 = ip.String()
-	}
-
-	nodeResource.Spec.HealthAddressing.IPv6 = ""
-	if ip := ln.IPv6HealthIP; ip != nil {
-		nodeResource.Spec.HealthAddressing.IPv6 = ip.String()
-	}
-
-	nodeResource.Spec.IngressAddressing.IPV4 = ""
-	if ip := ln.IPv4IngressIP; ip != nil {
-		nodeResource.Spec.IngressAddressing.IPV4 = ip.String()
-	}
-
-	nodeResource.Spec.IngressAddressing.IPV6 = ""
-	if ip := ln.IPv6IngressIP; ip != nil {
-		nodeResource.Spec.IngressAddressing.IPV6 = ip.String()
-	}
+	mutateFamilyAddress(&nodeResource.Spec.HealthAddressing.IPv4, option.Config.EnableIPv4, ln.IPv4HealthIP)
+	mutateFamilyAddress(&nodeResource.Spec.HealthAddressing.IPv6, option.Config.EnableIPv6, ln.IPv6HealthIP)
+	mutateFamilyAddress(&nodeResource.Spec.IngressAddressing.IPV4, option.Config.EnableIPv4, ln.IPv4IngressIP)
+	mutateFamilyAddress(&nodeResource.Spec.IngressAddressing.IPV6, option.Config.EnableIPv6, ln.IPv6IngressIP)
 
 	nodeResource.Spec.BootID = ln.BootID
 
@@ -412,7 +470,7 @@ func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln
 		nodeResource.Spec.ENI = eniTypes.ENISpec{}
 		instanceID, instanceType, availabilityZone, vpcID, subnetID, err := metadata.GetInstanceMetadata()
 		if err != nil {
-			log.WithError(err).Fatal("Unable to retrieve InstanceID of own EC2 instance")
+			return fmt.Errorf("unable to retrieve InstanceID of own EC2 instance: %w", err)
 		}
 
 		if instanceID == "" {
@@ -486,10 +544,10 @@ func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln
 
 	case ipamOption.IPAMAzure:
 		if ln.ProviderID == "" {
-			log.Fatal("Spec.ProviderID in k8s node resource must be set for Azure IPAM")
+			return errors.New("Spec.ProviderID in k8s node resource must be set for Azure IPAM")
 		}
 		if !strings.HasPrefix(ln.ProviderID, azureTypes.ProviderPrefix) {
-			log.Fatalf("Spec.ProviderID in k8s node resource must have prefix %s", azureTypes.ProviderPrefix)
+			return fmt.Errorf("Spec.ProviderID in k8s node resource must have prefix %s", azureTypes.ProviderPrefix)
 		}
 		// The Azure controller in Kubernetes creates a mix of upper
 		// and lower case when filling in the ProviderID and is
@@ -515,7 +573,7 @@ func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln
 
 		instanceID, err := alibabaCloudMetadata.GetInstanceID(context.TODO())
 		if err != nil {
-			log.WithError(err).Fatal("Unable to retrieve InstanceID of own ECS instance")
+			return fmt.Errorf("unable to retrieve InstanceID of own ECS instance: %w", err)
 		}
 
 		if instanceID == "" {
@@ -524,19 +582,19 @@ func (n *NodeDiscovery) mutateNodeResource(nodeResource *ciliumv2.CiliumNode, ln
 
 		instanceType, err := alibabaCloudMetadata.GetInstanceType(context.TODO())
 		if err != nil {
-			log.WithError(err).Fatal("Unable to retrieve InstanceType of own ECS instance")
+			return fmt.Errorf("unable to retrieve InstanceType of own ECS instance: %w", err)
 		}
 		vpcID, err := alibabaCloudMetadata.GetVPCID(context.TODO())
 		if err != nil {
-			log.WithError(err).Fatal("Unable to retrieve VPC ID of own ECS instance")
+			return fmt.Errorf("unable to retrieve VPC ID of own ECS instance: %w", err)
 		}
 		vpcCidrBlock, err := alibabaCloudMetadata.GetVPCCIDRBlock(context.TODO())
 		if err != nil {
-			log.WithError(err).Fatal("Unable to retrieve VPC CIDR block of own ECS instance")
+			return fmt.Errorf("unable to retrieve VPC CIDR block of own ECS instance: %w", err)
 		}
 		zoneID, err := alibabaCloudMetadata.GetZoneID(context.TODO())
 		if err != nil {
-			log.WithError(err).Fatal("Unable to retrieve Zone ID of own ECS instance")
+			return fmt.Errorf("unable to retrieve Zone ID of own ECS instance: %w", err)
 		}
 		nodeResource.Spec.InstanceID = instanceID
 		nodeResource.Spec.AlibabaCloud.InstanceType = instanceType
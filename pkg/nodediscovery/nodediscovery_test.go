@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package nodediscovery
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fakecni "github.com/cilium/cilium/daemon/cmd/cni/fake"
+	"github.com/cilium/cilium/pkg/defaults"
+	ipamOption "github.com/cilium/cilium/pkg/ipam/option"
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+	"github.com/cilium/cilium/pkg/node"
+	"github.com/cilium/cilium/pkg/option"
+)
+
+func TestWarnConflictingReservedLabels(t *testing.T) {
+	hook := test.NewLocal(log.Logger)
+	defer hook.Reset()
+
+	tests := []struct {
+		name       string
+		oldLabels  map[string]string
+		newLabels  map[string]string
+		wantWarned bool
+	}{
+		{
+			name: "conflicting reserved label",
+			oldLabels: map[string]string{
+				defaults.CiliumK8sAnnotationPrefix + "no-schedule": "true",
+			},
+			newLabels: map[string]string{
+				defaults.CiliumK8sAnnotationPrefix + "no-schedule": "false",
+			},
+			wantWarned: true,
+		},
+		{
+			name: "only user labels",
+			oldLabels: map[string]string{
+				"team": "platform",
+			},
+			newLabels: map[string]string{
+				"team": "infra",
+			},
+			wantWarned: false,
+		},
+		{
+			name: "reserved label unchanged",
+			oldLabels: map[string]string{
+				defaults.CiliumK8sAnnotationPrefix + "no-schedule": "true",
+			},
+			newLabels: map[string]string{
+				defaults.CiliumK8sAnnotationPrefix + "no-schedule": "true",
+			},
+			wantWarned: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook.Reset()
+			warnConflictingReservedLabels(tt.oldLabels, tt.newLabels)
+			assert.Equal(t, tt.wantWarned, len(hook.Entries) > 0)
+		})
+	}
+}
+
+// TestLocalNodeToKVStoreGroup verifies that the controller group propagating
+// local node updates to the kvstore keeps its default name unless a
+// KeyPrefix is configured, in which case the group name is suffixed with it
+// so that multiple KVStoreMesh-scoped NodeDiscovery instances can be told
+// apart in controller status/metrics.
+func TestLocalNodeToKVStoreGroup(t *testing.T) {
+	n := &NodeDiscovery{}
+	assert.Equal(t, localNodeToKVStoreControllerGroup, n.localNodeToKVStoreGroup())
+
+	n.KeyPrefix = "cluster-a"
+	assert.Equal(t, "local-node-to-kv-store-cluster-a", n.localNodeToKVStoreGroup().Name)
+}
+
+// TestMutateNodeResourceMetadataRecovery verifies that a failure to retrieve
+// cloud provider metadata is returned as an error rather than a log.Fatal,
+// and that a subsequent, successful metadata fetch (as would happen on the
+// next iteration of updateCiliumNodeResource's retry loop) lets the node
+// resource be populated normally.
+func TestMutateNodeResourceMetadataRecovery(t *testing.T) {
+	oldIPAM := option.Config.IPAM
+	defer func() { option.Config.IPAM = oldIPAM }()
+	option.Config.IPAM = ipamOption.IPAMAzure
+
+	n := &NodeDiscovery{cniConfigManager: &fakecni.FakeCNIConfigManager{}}
+	ln := &node.LocalNode{}
+	nodeResource := &ciliumv2.CiliumNode{}
+
+	err := n.mutateNodeResource(nodeResource, ln)
+	require.Error(t, err)
+
+	ln.ProviderID = "azure://foo"
+	require.NoError(t, n.mutateNodeResource(nodeResource, ln))
+	assert.Equal(t, "foo", nodeResource.Spec.InstanceID)
+}
+
+// TestMutateNodeResourceFamilyAddresses verifies that mutateNodeResource only
+// writes a family's health/ingress address when that family is enabled and
+// the local node has an IP for it, and that it otherwise preserves whatever
+// value is already on the CiliumNode rather than clobbering it with an empty
+// string, which matters during dual-stack bootstrap when one family's
+// health/ingress IP is allocated before the other's.
+func TestMutateNodeResourceFamilyAddresses(t *testing.T) {
+	oldV4, oldV6 := option.Config.EnableIPv4, option.Config.EnableIPv6
+	defer func() {
+		option.Config.EnableIPv4 = oldV4
+		option.Config.EnableIPv6 = oldV6
+	}()
+
+	n := &NodeDiscovery{cniConfigManager: &fakecni.FakeCNIConfigManager{}}
+
+	t.Run("IPv4-only", func(t *testing.T) {
+		option.Config.EnableIPv4 = true
+		option.Config.EnableIPv6 = false
+
+		ln := &node.LocalNode{}
+		ln.IPv4HealthIP = net.ParseIP("10.0.0.1")
+		ln.IPv4IngressIP = net.ParseIP("10.0.0.2")
+		nodeResource := &ciliumv2.CiliumNode{}
+
+		require.NoError(t, n.mutateNodeResource(nodeResource, ln))
+		assert.Equal(t, "10.0.0.1", nodeResource.Spec.HealthAddressing.IPv4)
+		assert.Equal(t, "10.0.0.2", nodeResource.Spec.IngressAddressing.IPV4)
+		assert.Empty(t, nodeResource.Spec.HealthAddressing.IPv6)
+		assert.Empty(t, nodeResource.Spec.IngressAddressing.IPV6)
+	})
+
+	t.Run("IPv6-only", func(t *testing.T) {
+		option.Config.EnableIPv4 = false
+		option.Config.EnableIPv6 = true
+
+		ln := &node.LocalNode{}
+		ln.IPv6HealthIP = net.ParseIP("f00d::1")
+		ln.IPv6IngressIP = net.ParseIP("f00d::2")
+		nodeResource := &ciliumv2.CiliumNode{}
+
+		require.NoError(t, n.mutateNodeResource(nodeResource, ln))
+		assert.Equal(t, "f00d::1", nodeResource.Spec.HealthAddressing.IPv6)
+		assert.Equal(t, "f00d::2", nodeResource.Spec.IngressAddressing.IPV6)
+		assert.Empty(t, nodeResource.Spec.HealthAddressing.IPv4)
+		assert.Empty(t, nodeResource.Spec.IngressAddressing.IPV4)
+	})
+
+	t.Run("dual-stack bootstrap ordering", func(t *testing.T) {
+		option.Config.EnableIPv4 = true
+		option.Config.EnableIPv6 = true
+
+		ln := &node.LocalNode{}
+		ln.IPv4HealthIP = net.ParseIP("10.0.0.1")
+		ln.IPv4IngressIP = net.ParseIP("10.0.0.2")
+		// The IPv6 health/ingress IPs are not known yet, as would be the
+		// case early during a dual-stack bootstrap; any previously written
+		// IPv6 addresses must survive this call.
+		nodeResource := &ciliumv2.CiliumNode{}
+		nodeResource.Spec.HealthAddressing.IPv6 = "f00d::1"
+		nodeResource.Spec.IngressAddressing.IPV6 = "f00d::2"
+
+		require.NoError(t, n.mutateNodeResource(nodeResource, ln))
+		assert.Equal(t, "10.0.0.1", nodeResource.Spec.HealthAddressing.IPv4)
+		assert.Equal(t, "10.0.0.2", nodeResource.Spec.IngressAddressing.IPV4)
+		assert.Equal(t, "f00d::1", nodeResource.Spec.HealthAddressing.IPv6,
+			"IPv6 health IP not yet known should preserve the existing value")
+		assert.Equal(t, "f00d::2", nodeResource.Spec.IngressAddressing.IPV6,
+			"IPv6 ingress IP not yet known should preserve the existing value")
+
+		// Once the IPv6 health/ingress IPs become known, they are written
+		// as normal.
+		ln.IPv6HealthIP = net.ParseIP("f00d::3")
+		ln.IPv6IngressIP = net.ParseIP("f00d::4")
+		require.NoError(t, n.mutateNodeResource(nodeResource, ln))
+		assert.Equal(t, "f00d::3", nodeResource.Spec.HealthAddressing.IPv6)
+		assert.Equal(t, "f00d::4", nodeResource.Spec.IngressAddressing.IPV6)
+	})
+}
@@ -119,6 +119,16 @@ func (fpr *fakePodResource) Events(ctx context.Context, opts ...resource.EventsO
 func (fpr *fakePodResource) Store(context.Context) (resource.Store[*slimcorev1.Pod], error) {
 	return &fpr.store, nil
 }
+func (fpr *fakePodResource) Get(ctx context.Context, key resource.Key) (item *slimcorev1.Pod, exists bool, err error) {
+	return fpr.store.GetByKey(key)
+}
+func (fpr *fakePodResource) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (fpr *fakePodResource) HasSynced() bool {
+	return true
+}
 
 type fakePodStore struct {
 	OnList func() []*slimcorev1.Pod
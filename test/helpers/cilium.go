@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,8 +19,10 @@ import (
 
 	"github.com/cilium/cilium/api/v1/models"
 	"github.com/cilium/cilium/pkg/identity"
+	"github.com/cilium/cilium/pkg/labels"
 	"github.com/cilium/cilium/pkg/logging"
 	"github.com/cilium/cilium/pkg/logging/logfields"
+	"github.com/cilium/cilium/pkg/metrics"
 	"github.com/cilium/cilium/test/config"
 	ginkgoext "github.com/cilium/cilium/test/ginkgo-ext"
 	"github.com/cilium/cilium/test/helpers/logutils"
@@ -78,6 +82,170 @@ func (s *SSHMeta) BpfIPCacheList(localScopeOnly bool) (map[string]uint32, error)
 	return result, nil
 }
 
+// PolicyMapKey mirrors the Key field of policymap.PolicyEntryDump as emitted
+// by `cilium-dbg bpf policy get -o json`.
+type PolicyMapKey struct {
+	Prefixlen        uint32 `json:"Prefixlen"`
+	Identity         uint32 `json:"Identity"`
+	TrafficDirection uint8  `json:"TrafficDirection"`
+	Nexthdr          uint8  `json:"Nexthdr"`
+	DestPortNetwork  uint16 `json:"DestPortNetwork"`
+}
+
+// PolicyMapEntry mirrors policymap.PolicyEntryDump as emitted by
+// `cilium-dbg bpf policy get -o json`.
+type PolicyMapEntry struct {
+	Key              PolicyMapKey `json:"Key"`
+	ProxyPortNetwork uint16       `json:"ProxyPortNetwork"`
+	Flags            uint8        `json:"Flags"`
+	AuthType         uint8        `json:"AuthType"`
+	Packets          uint64       `json:"Packets"`
+	Bytes            uint64       `json:"Bytes"`
+}
+
+// BpfPolicyGet returns the output of `cilium-dbg bpf policy get <endpointID>
+// -o json` for the given endpoint, i.e. the full set of entries currently
+// programmed into that endpoint's BPF policy map.
+func (s *SSHMeta) BpfPolicyGet(endpointID string) ([]PolicyMapEntry, error) {
+	var dump []PolicyMapEntry
+
+	res := s.ExecCilium(fmt.Sprintf("bpf policy get %s -o json", endpointID))
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("cannot get bpf policy map for endpoint %s: %s", endpointID, res.CombineOutput())
+	}
+	if err := res.Unmarshal(&dump); err != nil {
+		return nil, err
+	}
+
+	return dump, nil
+}
+
+// BpfPolicyDiff compares the policy map entries returned by two calls to
+// BpfPolicyGet and returns the entries that were added and removed between
+// before and after, keyed on PolicyMapKey. It ignores entries whose key is
+// unchanged, even if their stats (Packets/Bytes) differ.
+func BpfPolicyDiff(before, after []PolicyMapEntry) (added, removed []PolicyMapEntry) {
+	beforeKeys := make(map[PolicyMapKey]struct{}, len(before))
+	for _, entry := range before {
+		beforeKeys[entry.Key] = struct{}{}
+	}
+	afterKeys := make(map[PolicyMapKey]struct{}, len(after))
+	for _, entry := range after {
+		afterKeys[entry.Key] = struct{}{}
+	}
+
+	for _, entry := range after {
+		if _, ok := beforeKeys[entry.Key]; !ok {
+			added = append(added, entry)
+		}
+	}
+	for _, entry := range before {
+		if _, ok := afterKeys[entry.Key]; !ok {
+			removed = append(removed, entry)
+		}
+	}
+
+	return added, removed
+}
+
+// LBBackend is a single backend of a load-balancing frontend, parsed from
+// the output of `cilium-dbg bpf lb list -o json`.
+type LBBackend struct {
+	IP    string
+	Port  uint16
+	State string
+}
+
+// Backend states reported by BpfLBList. These describe what the backend
+// entry text emitted by `cilium-dbg bpf lb list` actually tells us, which is
+// coarser than the full loadbalancer.BackendState machinery the agent uses
+// internally: the BPF dump has no way to distinguish e.g. terminating from
+// quarantined backends.
+const (
+	// LBBackendStateActive is a backend slot that resolved to a real
+	// backend found in the backend table.
+	LBBackendStateActive = "active"
+	// LBBackendStateEmpty is an unused backend slot, i.e. one the service
+	// reserved but has not filled with a backend.
+	LBBackendStateEmpty = "empty"
+	// LBBackendStateNotFound is a backend slot whose backend ID could not
+	// be resolved in the backend table, which should not normally happen
+	// outside of a race with a concurrent backend removal.
+	LBBackendStateNotFound = "not-found"
+)
+
+// bpfLBListBackendRE matches a single backend entry as formatted by
+// cilium-dbg's `dumpSVC`, e.g. "10.0.0.1:8080 (5) (1)" or,  for an IPv6
+// backend, "[fd00::1]:8080 (5) (1)". The revNAT ID and backend slot are
+// matched but not used here. An empty backend slot (backendSlot 0) carries
+// an additional "[flags] extra" suffix, e.g. "0.0.0.0:0 (2) (0) [ClusterIP] ",
+// which is matched but discarded.
+var bpfLBListBackendRE = regexp.MustCompile(`^(?:\[(?P<ip6>[^\]]+)\]|(?P<ip4>[^:\[\]]+)):(?P<port>\d+) \(\d+\) \(\d+\)(?: \[[^\]]*\].*)?$`)
+
+// parseBpfLBListBackend parses a single backend entry from the value side
+// of the `cilium-dbg bpf lb list -o json` output.
+func parseBpfLBListBackend(entry string) (LBBackend, error) {
+	if strings.HasSuffix(entry, "not found") {
+		return LBBackend{State: LBBackendStateNotFound}, nil
+	}
+
+	m := bpfLBListBackendRE.FindStringSubmatch(entry)
+	if m == nil {
+		return LBBackend{}, fmt.Errorf("cannot parse bpf lb list backend entry: %q", entry)
+	}
+
+	ip := m[bpfLBListBackendRE.SubexpIndex("ip4")]
+	if ip == "" {
+		ip = m[bpfLBListBackendRE.SubexpIndex("ip6")]
+	}
+	port, err := strconv.ParseUint(m[bpfLBListBackendRE.SubexpIndex("port")], 10, 16)
+	if err != nil {
+		return LBBackend{}, fmt.Errorf("cannot parse bpf lb list backend port from %q: %w", entry, err)
+	}
+
+	state := LBBackendStateActive
+	if ip == "0.0.0.0" || ip == "::" {
+		state = LBBackendStateEmpty
+	}
+
+	return LBBackend{IP: ip, Port: uint16(port), State: state}, nil
+}
+
+// parseBpfLBList converts the service->backends map emitted by
+// `cilium-dbg bpf lb list -o json` into typed backend entries, keyed by the
+// same frontend address strings.
+func parseBpfLBList(dump map[string][]string) (map[string][]LBBackend, error) {
+	result := make(map[string][]LBBackend, len(dump))
+	for frontend, entries := range dump {
+		backends := make([]LBBackend, 0, len(entries))
+		for _, entry := range entries {
+			backend, err := parseBpfLBListBackend(entry)
+			if err != nil {
+				return nil, fmt.Errorf("frontend %s: %w", frontend, err)
+			}
+			backends = append(backends, backend)
+		}
+		result[frontend] = backends
+	}
+	return result, nil
+}
+
+// BpfLBList returns the output of `cilium-dbg bpf lb list -o json`, mapping
+// each load-balancing frontend to its backends.
+func (s *SSHMeta) BpfLBList() (map[string][]LBBackend, error) {
+	var dump map[string][]string
+
+	res := s.ExecCilium("bpf lb list -o json")
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("cannot get bpf lb list: %s", res.CombineOutput())
+	}
+	if err := res.Unmarshal(&dump); err != nil {
+		return nil, err
+	}
+
+	return parseBpfLBList(dump)
+}
+
 // SelectedIdentities returns filtered identities from the output of `cilium-dbg policy selectors list
 // -o json` as a string
 func (s *SSHMeta) SelectedIdentities(match string) string {
@@ -86,12 +254,122 @@ func (s *SSHMeta) SelectedIdentities(match string) string {
 	return res.Stdout()
 }
 
+// IdentityList returns the identities known to the agent, as reported by
+// `cilium-dbg identity list -o json`.
+func (s *SSHMeta) IdentityList() ([]models.Identity, error) {
+	res := s.ExecCilium("identity list -o json")
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("cannot get identity list: %s", res.CombineOutput())
+	}
+
+	var identities []models.Identity
+	if err := res.Unmarshal(&identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// IdentityCountByType returns, for every label source found across the
+// agent's identities (e.g. "k8s", "reserved", "cidr"), the number of
+// identities that carry at least one label of that source.
+func (s *SSHMeta) IdentityCountByType() (map[string]int, error) {
+	identities, err := s.IdentityList()
+	if err != nil {
+		return nil, err
+	}
+	return identityCountByType(identities), nil
+}
+
+// identityCountByType does the counting for IdentityCountByType, factored
+// out so it can be unit tested against recorded identity JSON without a
+// live agent.
+func identityCountByType(identities []models.Identity) map[string]int {
+	counts := make(map[string]int)
+	for _, id := range identities {
+		sources := make(map[string]struct{})
+		for _, lbl := range id.Labels {
+			sources[labels.ParseLabel(lbl).Source] = struct{}{}
+		}
+		for source := range sources {
+			counts[source]++
+		}
+	}
+	return counts
+}
+
+// bpfMapPressureMetricName is the name of the cilium_bpf_map_pressure
+// metric, as reported by `cilium-dbg metrics list -o json` (the Namespace
+// and SubsystemBPF-prefixed "map_pressure" gauge created by
+// metrics.NewBPFMapPressureGauge).
+const bpfMapPressureMetricName = "cilium_bpf_map_pressure"
+
+// BpfMapPressure returns the fill percentage of every BPF map reporting the
+// cilium_bpf_map_pressure metric, keyed by map name, as reported by
+// `cilium-dbg metrics list -o json`. Tests can use this to assert that no
+// map is near capacity after running a workload.
+func (s *SSHMeta) BpfMapPressure() (map[string]float64, error) {
+	res := s.ExecCilium("metrics list -o json")
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("cannot get metrics list: %s", res.CombineOutput())
+	}
+
+	var metricsList []models.Metric
+	if err := res.Unmarshal(&metricsList); err != nil {
+		return nil, err
+	}
+	return bpfMapPressureByMapName(metricsList), nil
+}
+
+// bpfMapPressureByMapName does the filtering and keying for BpfMapPressure,
+// factored out so it can be unit tested against a recorded metrics listing
+// without a live agent.
+func bpfMapPressureByMapName(metricsList []models.Metric) map[string]float64 {
+	pressure := make(map[string]float64)
+	for _, m := range metricsList {
+		if m.Name != bpfMapPressureMetricName {
+			continue
+		}
+		pressure[m.Labels[metrics.LabelMapName]] = m.Value
+	}
+	return pressure
+}
+
 // ExecCilium runs a Cilium CLI command and returns the resultant cmdRes.
 func (s *SSHMeta) ExecCilium(cmd string) *CmdRes {
 	command := fmt.Sprintf("cilium-dbg %s", cmd)
 	return s.ExecWithSudo(command)
 }
 
+// ExecCiliumRetry runs a Cilium CLI command, retrying it up to attempts
+// times with backoff between attempts if it does not succeed. This is
+// useful for commands that can transiently fail while the agent is
+// restarting. It returns the CmdRes of the last attempt, successful or
+// not, and logs every retried attempt.
+func (s *SSHMeta) ExecCiliumRetry(cmd string, attempts int, backoff time.Duration) *CmdRes {
+	return execRetry(s.ExecCilium, cmd, attempts, backoff)
+}
+
+// execRetry calls exec(cmd) up to attempts times, retrying with backoff
+// between attempts as long as the result is not successful. It returns the
+// CmdRes of the last attempt. Factored out of ExecCiliumRetry so the retry
+// logic can be unit tested against a scripted exec func, without a live
+// SSH connection.
+func execRetry(exec func(cmd string) *CmdRes, cmd string, attempts int, backoff time.Duration) *CmdRes {
+	var res *CmdRes
+	for attempt := 1; attempt <= attempts; attempt++ {
+		res = exec(cmd)
+		if res.WasSuccessful() {
+			return res
+		}
+
+		log.Warningf("attempt %d/%d of %q failed: %s", attempt, attempts, cmd, res.GetErr(""))
+		if attempt < attempts {
+			time.Sleep(backoff)
+		}
+	}
+	return res
+}
+
 // EndpointGet returns the output of `cilium-dbg endpoint get` for the provided
 // endpoint ID.
 func (s *SSHMeta) EndpointGet(id string) *models.Endpoint {
@@ -453,6 +731,71 @@ func (s *SSHMeta) SetPolicyEnforcementAndWait(status string) bool {
 	return s.WaitEndpointsReady()
 }
 
+// configOptions returns the Cilium agent's currently realized runtime
+// configuration options, keyed the same way as the "cilium-dbg config
+// <key>=<value>" syntax expects.
+func (s *SSHMeta) configOptions() (map[string]string, error) {
+	res := s.ExecCilium("config -o json")
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("cannot get Cilium configuration: %s", res.GetErr(""))
+	}
+
+	var config models.DaemonConfiguration
+	if err := res.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+	if config.Status == nil || config.Status.Realized == nil {
+		return nil, nil
+	}
+
+	return config.Status.Realized.Options, nil
+}
+
+// ConfigDiff returns the subset of desired whose value differs from
+// current, skipping keys whose desired value is already in effect. This is
+// the same no-op check SetPolicyEnforcement performs for a single key,
+// generalized to an arbitrary set of configuration keys.
+func ConfigDiff(current, desired map[string]string) map[string]string {
+	changed := make(map[string]string, len(desired))
+	for k, v := range desired {
+		if current[k] != v {
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+// SetConfigAndWait sets each of the given Cilium agent runtime configuration
+// key/value pairs, skipping any key whose value already matches, and then
+// waits for all endpoints running in s to be ready. Returns whether applying
+// the configuration and waiting for endpoints were both successful.
+func (s *SSHMeta) SetConfigAndWait(kv map[string]string) bool {
+	current, err := s.configOptions()
+	if err != nil {
+		log.WithError(err).Error("cannot get current Cilium configuration")
+		return false
+	}
+
+	changed := ConfigDiff(current, kv)
+	if len(changed) == 0 {
+		return s.WaitEndpointsReady()
+	}
+
+	args := make([]string, 0, len(changed))
+	for k, v := range changed {
+		args = append(args, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(args)
+
+	log.Infof("setting %s", strings.Join(args, " "))
+	res := s.ExecCilium(fmt.Sprintf("config %s", strings.Join(args, " ")))
+	if !res.WasSuccessful() {
+		return false
+	}
+
+	return s.WaitEndpointsReady()
+}
+
 // PolicyDelAll deletes all policy rules currently imported into Cilium.
 func (s *SSHMeta) PolicyDelAll() *CmdRes {
 	log.Info("Deleting all policy in agent")
@@ -732,6 +1075,48 @@ func (s *SSHMeta) DumpCiliumCommandOutput() {
 
 }
 
+// bugtoolArchiveRegexp matches the "ARCHIVE at <path>" line that
+// cilium-bugtool prints to stderr once it has written the tar archive.
+var bugtoolArchiveRegexp = regexp.MustCompile(`ARCHIVE at (\S+)`)
+
+// bugtoolArchivePath extracts the archive path from cilium-bugtool's stderr
+// output.
+func bugtoolArchivePath(stderr string) (string, error) {
+	matches := bugtoolArchiveRegexp.FindStringSubmatch(stderr)
+	if matches == nil {
+		return "", fmt.Errorf("could not find archive path in %s output: %s", CiliumBugtool, stderr)
+	}
+	return matches[1], nil
+}
+
+// CollectBugtool runs cilium-bugtool and returns the path to the resulting
+// archive along with the list of files it contains, so that tests can assert
+// that expected diagnostics (e.g. bpf maps, cilium config) were collected.
+func (s *SSHMeta) CollectBugtool(ctx context.Context) (archivePath string, fileList []string, err error) {
+	res := s.ExecContext(ctx, fmt.Sprintf("sudo %s %s", CiliumBugtool, CiliumBugtoolArgs), ExecOptions{SkipLog: true})
+	if !res.WasSuccessful() {
+		return "", nil, fmt.Errorf("failed to run %s: %s", CiliumBugtool, res.CombineOutput())
+	}
+
+	archivePath, err = bugtoolArchivePath(res.Stderr())
+	if err != nil {
+		return "", nil, err
+	}
+
+	listRes := s.ExecContext(ctx, fmt.Sprintf("sudo tar -tf %q", archivePath), ExecOptions{SkipLog: true})
+	if !listRes.WasSuccessful() {
+		return archivePath, nil, fmt.Errorf("failed to list contents of %s: %s", archivePath, listRes.CombineOutput())
+	}
+
+	for _, line := range listRes.ByLines() {
+		if line != "" {
+			fileList = append(fileList, line)
+		}
+	}
+
+	return archivePath, fileList, nil
+}
+
 // GatherLogs dumps Cilium, Cilium Docker, key-value store logs, and gops output
 // to the directory testResultsPath
 func (s *SSHMeta) GatherLogs() {
@@ -816,6 +1201,22 @@ func (s *SSHMeta) SetUpCiliumWithHubble() error {
 	return s.SetUpCiliumWithOptions("--enable-hubble")
 }
 
+// CiliumStatus runs `cilium-dbg status -o json` and unmarshals its output
+// into a models.StatusResponse. The response is returned even when the
+// command exits with a nonzero return code, since a degraded or partial
+// status is still reported as valid JSON; callers that care about specific
+// subsystems (e.g. KVStore, Kubernetes, IPAM) should inspect those fields
+// directly rather than relying solely on the command's exit code.
+func (s *SSHMeta) CiliumStatus() (*models.StatusResponse, error) {
+	res := s.ExecCilium("status -o json")
+
+	var status models.StatusResponse
+	if err := res.Unmarshal(&status); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal cilium status: %s", err)
+	}
+	return &status, nil
+}
+
 // WaitUntilReady waits until the output of `cilium-dbg status` returns with code
 // zero. Returns an error if the output of `cilium-dbg status` returns a nonzero
 // return code after the specified timeout duration has elapsed.
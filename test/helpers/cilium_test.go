@@ -0,0 +1,387 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cilium/cilium/api/v1/models"
+)
+
+// recordedDegradedStatusJSON is a trimmed-down recording of the JSON emitted
+// by `cilium-dbg status -o json` on an agent whose KVStore connection has
+// not yet been established, while Kubernetes and IPAM are healthy.
+const recordedDegradedStatusJSON = `{
+	"cilium": {
+		"state": "Ok",
+		"msg": "OK"
+	},
+	"kvstore": {
+		"state": "Warning",
+		"msg": "etcd: still connecting to etcd server instances"
+	},
+	"kubernetes": {
+		"state": "Ok",
+		"msg": "Kubernetes is running"
+	},
+	"ipam": {
+		"status": "cilium-operator not running",
+		"ipv4": ["1.1.1.1"]
+	}
+}`
+
+func TestCiliumStatusUnmarshal(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedDegradedStatusJSON))
+	require.NoError(t, err)
+
+	var status models.StatusResponse
+	require.NoError(t, res.Unmarshal(&status))
+
+	require.NotNil(t, status.Cilium)
+	assert.Equal(t, models.StatusStateOk, status.Cilium.State)
+
+	require.NotNil(t, status.Kvstore)
+	assert.Equal(t, models.StatusStateWarning, status.Kvstore.State)
+	assert.Contains(t, status.Kvstore.Msg, "still connecting")
+
+	require.NotNil(t, status.Kubernetes)
+	assert.Equal(t, models.StatusStateOk, status.Kubernetes.State)
+
+	require.NotNil(t, status.Ipam)
+	assert.Equal(t, "cilium-operator not running", status.Ipam.Status)
+	assert.Contains(t, status.Ipam.IPV4, "1.1.1.1")
+}
+
+// recordedAllowAllPolicyJSON is a trimmed-down recording of the JSON emitted
+// by `cilium-dbg bpf policy get <id> -o json` for an endpoint whose policy
+// allows all ingress and egress traffic.
+const recordedAllowAllPolicyJSON = `[
+	{
+		"Key": {"Prefixlen": 8, "Identity": 0, "TrafficDirection": 0, "Nexthdr": 0, "DestPortNetwork": 0},
+		"ProxyPortNetwork": 0, "Flags": 6, "AuthType": 0, "Packets": 10, "Bytes": 1000
+	},
+	{
+		"Key": {"Prefixlen": 8, "Identity": 0, "TrafficDirection": 1, "Nexthdr": 0, "DestPortNetwork": 0},
+		"ProxyPortNetwork": 0, "Flags": 6, "AuthType": 0, "Packets": 5, "Bytes": 500
+	}
+]`
+
+// recordedRestrictedPolicyJSON is a trimmed-down recording of the same
+// endpoint's policy map after it was restricted to only allow ingress from
+// a single identity on port 80, dropping the allow-all egress entry and
+// adding a deny entry for a second identity.
+const recordedRestrictedPolicyJSON = `[
+	{
+		"Key": {"Prefixlen": 48, "Identity": 1234, "TrafficDirection": 0, "Nexthdr": 6, "DestPortNetwork": 20480},
+		"ProxyPortNetwork": 0, "Flags": 0, "AuthType": 0, "Packets": 3, "Bytes": 300
+	},
+	{
+		"Key": {"Prefixlen": 24, "Identity": 5678, "TrafficDirection": 0, "Nexthdr": 0, "DestPortNetwork": 0},
+		"ProxyPortNetwork": 0, "Flags": 3, "AuthType": 0, "Packets": 0, "Bytes": 0
+	}
+]`
+
+func TestBpfPolicyGetUnmarshal(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedAllowAllPolicyJSON))
+	require.NoError(t, err)
+
+	var entries []PolicyMapEntry
+	require.NoError(t, res.Unmarshal(&entries))
+
+	require.Len(t, entries, 2)
+	assert.EqualValues(t, 0, entries[0].Key.TrafficDirection)
+	// Bit 0 of Flags is the deny flag; an allow-all entry has it clear.
+	assert.True(t, entries[0].Flags&1 == 0)
+	assert.EqualValues(t, 10, entries[0].Packets)
+	assert.EqualValues(t, 1, entries[1].Key.TrafficDirection)
+}
+
+// recordedBpfLBListJSON is a trimmed-down recording of the JSON emitted by
+// `cilium-dbg bpf lb list -o json` for a ClusterIP service with two ready
+// backends, a second ClusterIP frontend whose only backend slot is still
+// empty, and a
+// frontend whose sole backend ID no longer resolves in the backend table.
+const recordedBpfLBListJSON = `{
+	"10.96.0.1:443": ["10.0.0.1:4244 (1) (1)", "10.0.0.2:4244 (1) (2)"],
+	"10.96.0.2:80": ["0.0.0.0:0 (2) (0) [ClusterIP] "],
+	"[fd00::1]:8080": ["backend 42 not found"]
+}`
+
+func TestBpfLBListUnmarshal(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedBpfLBListJSON))
+	require.NoError(t, err)
+
+	var dump map[string][]string
+	require.NoError(t, res.Unmarshal(&dump))
+
+	backends, err := parseBpfLBList(dump)
+	require.NoError(t, err)
+
+	require.Len(t, backends["10.96.0.1:443"], 2)
+	assert.ElementsMatch(t,
+		[]LBBackend{
+			{IP: "10.0.0.1", Port: 4244, State: LBBackendStateActive},
+			{IP: "10.0.0.2", Port: 4244, State: LBBackendStateActive},
+		},
+		backends["10.96.0.1:443"])
+
+	require.Len(t, backends["10.96.0.2:80"], 1)
+	assert.Equal(t, LBBackendStateEmpty, backends["10.96.0.2:80"][0].State)
+
+	require.Len(t, backends["[fd00::1]:8080"], 1)
+	assert.Equal(t, LBBackendStateNotFound, backends["[fd00::1]:8080"][0].State)
+}
+
+func TestBpfLBListBackendParseIPv6(t *testing.T) {
+	backend, err := parseBpfLBListBackend("[fd00::2]:8080 (3) (1)")
+	require.NoError(t, err)
+	assert.Equal(t, LBBackend{IP: "fd00::2", Port: 8080, State: LBBackendStateActive}, backend)
+}
+
+func TestBpfLBListBackendParseEmptySlot(t *testing.T) {
+	backend, err := parseBpfLBListBackend("0.0.0.0:0 (2) (0) [ClusterIP] ")
+	require.NoError(t, err)
+	assert.Equal(t, LBBackend{IP: "0.0.0.0", Port: 0, State: LBBackendStateEmpty}, backend)
+
+	backend, err = parseBpfLBListBackend("[::]:0 (2) (0) [ClusterIP, NodePort] (L7LB Proxy Port: 1234)")
+	require.NoError(t, err)
+	assert.Equal(t, LBBackend{IP: "::", Port: 0, State: LBBackendStateEmpty}, backend)
+}
+
+// recordedConfigJSON is a trimmed-down recording of the JSON emitted by
+// `cilium-dbg config -o json` on an agent with a couple of runtime options
+// set.
+const recordedConfigJSON = `{
+	"status": {
+		"realized": {
+			"policy-enforcement": "default",
+			"options": {
+				"ConntrackGCInterval": "30s",
+				"Debug": "false"
+			}
+		}
+	}
+}`
+
+func TestConfigOptionsUnmarshal(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedConfigJSON))
+	require.NoError(t, err)
+
+	var config models.DaemonConfiguration
+	require.NoError(t, res.Unmarshal(&config))
+
+	require.NotNil(t, config.Status)
+	require.NotNil(t, config.Status.Realized)
+	assert.Equal(t, "default", config.Status.Realized.PolicyEnforcement)
+	assert.Equal(t, map[string]string{"ConntrackGCInterval": "30s", "Debug": "false"}, map[string]string(config.Status.Realized.Options))
+}
+
+func TestConfigDiff(t *testing.T) {
+	current := map[string]string{"ConntrackGCInterval": "30s", "Debug": "false"}
+
+	// No-op: both keys already have their desired value.
+	assert.Empty(t, ConfigDiff(current, map[string]string{"ConntrackGCInterval": "30s", "Debug": "false"}))
+
+	// Only the changed key is reported, not the unchanged one.
+	changed := ConfigDiff(current, map[string]string{"ConntrackGCInterval": "30s", "Debug": "true"})
+	assert.Equal(t, map[string]string{"Debug": "true"}, changed)
+
+	// A key absent from current is treated as changed.
+	changed = ConfigDiff(current, map[string]string{"MTU": "1500"})
+	assert.Equal(t, map[string]string{"MTU": "1500"}, changed)
+}
+
+func TestExecRetry(t *testing.T) {
+	// Scripted exec that fails twice before succeeding on its third attempt.
+	var calls []string
+	exec := func(cmd string) *CmdRes {
+		calls = append(calls, cmd)
+		success := len(calls) == 3
+		return &CmdRes{
+			cmd:      cmd,
+			success:  success,
+			exitcode: map[bool]int{true: 0, false: 1}[success],
+			stdout:   &Buffer{},
+			stderr:   &Buffer{},
+		}
+	}
+
+	res := execRetry(exec, "endpoint list", 5, time.Millisecond)
+	assert.True(t, res.WasSuccessful())
+	assert.Equal(t, []string{"endpoint list", "endpoint list", "endpoint list"}, calls)
+
+	// Exhausting all attempts without success returns the last (failed) CmdRes.
+	calls = nil
+	alwaysFails := func(cmd string) *CmdRes {
+		calls = append(calls, cmd)
+		return &CmdRes{cmd: cmd, success: false, exitcode: 1, stdout: &Buffer{}, stderr: &Buffer{}}
+	}
+	res = execRetry(alwaysFails, "endpoint list", 3, time.Millisecond)
+	assert.False(t, res.WasSuccessful())
+	assert.Len(t, calls, 3)
+}
+
+func TestBpfPolicyDiff(t *testing.T) {
+	resBefore := &CmdRes{stdout: &Buffer{}}
+	_, err := resBefore.stdout.Write([]byte(recordedAllowAllPolicyJSON))
+	require.NoError(t, err)
+	var before []PolicyMapEntry
+	require.NoError(t, resBefore.Unmarshal(&before))
+
+	resAfter := &CmdRes{stdout: &Buffer{}}
+	_, err = resAfter.stdout.Write([]byte(recordedRestrictedPolicyJSON))
+	require.NoError(t, err)
+	var after []PolicyMapEntry
+	require.NoError(t, resAfter.Unmarshal(&after))
+
+	added, removed := BpfPolicyDiff(before, after)
+
+	require.Len(t, added, 2)
+	assert.ElementsMatch(t, []uint32{1234, 5678}, []uint32{added[0].Key.Identity, added[1].Key.Identity})
+
+	require.Len(t, removed, 2)
+	assert.ElementsMatch(t, []uint8{0, 1}, []uint8{removed[0].Key.TrafficDirection, removed[1].Key.TrafficDirection})
+
+	// Diffing against itself yields no changes.
+	added, removed = BpfPolicyDiff(before, before)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+// recordedBugtoolStderr is a trimmed-down recording of the stderr output of
+// `cilium-bugtool --exclude-object-files`, including the disclaimer and the
+// archive path line that CollectBugtool parses.
+const recordedBugtoolStderr = `DISCLAIMER
+This tool has tried to remove all sensitive information from gathered data.
+However, you should still review all files before sending them to anyone.
+
+ARCHIVE at /tmp/cilium-bugtool-20260809-103000.tar
+`
+
+// recordedBugtoolArchiveListing is a trimmed-down recording of the output of
+// `tar -tf` on a cilium-bugtool archive.
+const recordedBugtoolArchiveListing = `cilium-bugtool-20260809-103000/
+cilium-bugtool-20260809-103000/cmd/cilium-dbg_status.md
+cilium-bugtool-20260809-103000/cmd/cilium-dbg_bpf_lb_list.md
+cilium-bugtool-20260809-103000/conf/cilium-config.json
+cilium-bugtool-20260809-103000/cilium-bugtool.log
+`
+
+func TestBugtoolArchivePath(t *testing.T) {
+	path, err := bugtoolArchivePath(recordedBugtoolStderr)
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/cilium-bugtool-20260809-103000.tar", path)
+
+	_, err = bugtoolArchivePath("no archive line here")
+	require.Error(t, err)
+}
+
+// recordedIdentityListJSON is a trimmed-down recording of the JSON emitted
+// by `cilium-dbg identity list -o json`, with a reserved identity, a
+// kubernetes-only identity, and an identity carrying both k8s and CIDR
+// labels.
+const recordedIdentityListJSON = `[
+	{
+		"id": 1,
+		"labels": ["reserved:host"]
+	},
+	{
+		"id": 1234,
+		"labels": ["k8s:io.kubernetes.pod.namespace=default", "k8s:app=web"]
+	},
+	{
+		"id": 5678,
+		"labels": ["k8s:io.kubernetes.pod.namespace=default", "cidr:10.0.0.0/24"]
+	}
+]`
+
+func TestIdentityListUnmarshal(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedIdentityListJSON))
+	require.NoError(t, err)
+
+	var identities []models.Identity
+	require.NoError(t, res.Unmarshal(&identities))
+
+	require.Len(t, identities, 3)
+	assert.EqualValues(t, 1, identities[0].ID)
+	assert.Contains(t, identities[1].Labels, "k8s:app=web")
+}
+
+func TestIdentityCountByType(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedIdentityListJSON))
+	require.NoError(t, err)
+
+	var identities []models.Identity
+	require.NoError(t, res.Unmarshal(&identities))
+
+	counts := identityCountByType(identities)
+	assert.Equal(t, map[string]int{
+		"reserved": 1,
+		"k8s":      2,
+		"cidr":     1,
+	}, counts)
+}
+
+// recordedMetricsListJSON is a trimmed-down recording of the JSON emitted
+// by `cilium-dbg metrics list -o json`, with cilium_bpf_map_pressure gauges
+// for two maps alongside an unrelated metric that must be ignored.
+const recordedMetricsListJSON = `[
+	{
+		"name": "cilium_bpf_map_pressure",
+		"labels": {"map_name": "cilium_lb4_services_v2"},
+		"value": 0.42
+	},
+	{
+		"name": "cilium_bpf_map_pressure",
+		"labels": {"map_name": "cilium_ipcache"},
+		"value": 0.91
+	},
+	{
+		"name": "cilium_bpf_map_ops_total",
+		"labels": {"map_name": "cilium_ipcache", "operation": "update", "outcome": "success"},
+		"value": 1234
+	}
+]`
+
+func TestBpfMapPressureUnmarshal(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedMetricsListJSON))
+	require.NoError(t, err)
+
+	var metricsList []models.Metric
+	require.NoError(t, res.Unmarshal(&metricsList))
+
+	pressure := bpfMapPressureByMapName(metricsList)
+	assert.Equal(t, map[string]float64{
+		"cilium_lb4_services_v2": 0.42,
+		"cilium_ipcache":         0.91,
+	}, pressure)
+}
+
+func TestCollectBugtoolFileList(t *testing.T) {
+	res := &CmdRes{stdout: &Buffer{}}
+	_, err := res.stdout.Write([]byte(recordedBugtoolArchiveListing))
+	require.NoError(t, err)
+
+	var fileList []string
+	for _, line := range res.ByLines() {
+		if line != "" {
+			fileList = append(fileList, line)
+		}
+	}
+
+	assert.Contains(t, fileList, "cilium-bugtool-20260809-103000/cmd/cilium-dbg_bpf_lb_list.md")
+	assert.Contains(t, fileList, "cilium-bugtool-20260809-103000/conf/cilium-config.json")
+	assert.Len(t, fileList, 5)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConntrackFilter selects the subset of conntrack entries to count. Empty
+// fields are omitted from the conntrack command line, matching any value.
+type ConntrackFilter struct {
+	// SourceCIDR restricts the count to entries whose source address falls
+	// within this CIDR (conntrack -s).
+	SourceCIDR string
+	// DestCIDR restricts the count to entries whose destination address
+	// falls within this CIDR (conntrack -d).
+	DestCIDR string
+	// Protocol restricts the count to entries of this protocol, e.g. "tcp"
+	// or "udp" (conntrack -p).
+	Protocol string
+}
+
+// conntrackCountCommand builds the "conntrack -L ... | wc -l" command line
+// for the given filter.
+func (f ConntrackFilter) conntrackCountCommand() string {
+	cmd := "conntrack -L"
+	if f.SourceCIDR != "" {
+		cmd += fmt.Sprintf(" -s %s", f.SourceCIDR)
+	}
+	if f.DestCIDR != "" {
+		cmd += fmt.Sprintf(" -d %s", f.DestCIDR)
+	}
+	if f.Protocol != "" {
+		cmd += fmt.Sprintf(" -p %s", f.Protocol)
+	}
+	return cmd + " | wc -l"
+}
+
+// parseConntrackCount parses the output of "conntrack -L ... | wc -l", which
+// is the count on its own line, possibly surrounded by whitespace added by
+// the shell or the SSH/exec transport.
+func parseConntrackCount(output string) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected conntrack count output %q: %w", output, err)
+	}
+	return count, nil
+}
+
+// ConntrackCount returns the number of conntrack entries on the node matching
+// filter.
+func (s *SSHMeta) ConntrackCount(filter ConntrackFilter) (int, error) {
+	res := s.ExecWithSudo(filter.conntrackCountCommand())
+	if !res.WasSuccessful() {
+		return 0, fmt.Errorf("cannot list conntrack entries: %s", res.GetErr(""))
+	}
+	return parseConntrackCount(res.GetStdOut().String())
+}
+
+// ConntrackCountInHostNetNSByLabel returns the number of conntrack entries
+// matching filter on the node identified by label, as seen from a pod
+// running in that node's host network namespace.
+func (kub *Kubectl) ConntrackCountInHostNetNSByLabel(ctx context.Context, label string, filter ConntrackFilter) (int, error) {
+	out, err := kub.ExecInHostNetNSByLabel(ctx, label, filter.conntrackCountCommand())
+	if err != nil {
+		return 0, fmt.Errorf("cannot list conntrack entries: %w", err)
+	}
+	return parseConntrackCount(out)
+}
+
+// WaitConntrackCount retries countFn until it returns the expected count, or
+// the default timeout expires. It is meant to be used with ConntrackCount or
+// ConntrackCountInHostNetNSByLabel, whose results may lag behind a change in
+// traffic until the next conntrack garbage-collection run.
+func WaitConntrackCount(countFn func() (int, error), expected int) error {
+	var lastErr error
+	body := func() bool {
+		count, err := countFn()
+		if err != nil {
+			lastErr = err
+			return false
+		}
+		lastErr = nil
+		return count == expected
+	}
+
+	if err := RepeatUntilTrueDefaultTimeout(body); err != nil {
+		if lastErr != nil {
+			return fmt.Errorf("waiting for conntrack count to reach %d: %w", expected, lastErr)
+		}
+		return fmt.Errorf("waiting for conntrack count to reach %d: %w", expected, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConntrackFilterCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ConntrackFilter
+		want   string
+	}{
+		{
+			name:   "no filter",
+			filter: ConntrackFilter{},
+			want:   "conntrack -L | wc -l",
+		},
+		{
+			name:   "source CIDR only",
+			filter: ConntrackFilter{SourceCIDR: "10.0.0.0/24"},
+			want:   "conntrack -L -s 10.0.0.0/24 | wc -l",
+		},
+		{
+			name:   "source and dest CIDR",
+			filter: ConntrackFilter{SourceCIDR: "10.0.0.0/24", DestCIDR: "10.0.0.0/24"},
+			want:   "conntrack -L -s 10.0.0.0/24 -d 10.0.0.0/24 | wc -l",
+		},
+		{
+			name:   "full filter",
+			filter: ConntrackFilter{SourceCIDR: "10.0.0.0/24", DestCIDR: "172.16.0.0/16", Protocol: "tcp"},
+			want:   "conntrack -L -s 10.0.0.0/24 -d 172.16.0.0/16 -p tcp | wc -l",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.conntrackCountCommand())
+		})
+	}
+}
+
+func TestParseConntrackCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:   "recorded wc -l output",
+			output: "42\n",
+			want:   42,
+		},
+		{
+			name:   "zero entries",
+			output: "0\n",
+			want:   0,
+		},
+		{
+			name:   "extra whitespace from SSH transport",
+			output: "  7  \r\n",
+			want:   7,
+		},
+		{
+			name:    "non-numeric output",
+			output:  "conntrack v1.4.6 (conformance ipv4): \n",
+			wantErr: true,
+		},
+		{
+			name:    "empty output",
+			output:  "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConntrackCount(tt.output)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWaitConntrackCount(t *testing.T) {
+	calls := 0
+	err := WaitConntrackCount(func() (int, error) {
+		calls++
+		if calls < 2 {
+			return 1, nil
+		}
+		return 2, nil
+	}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
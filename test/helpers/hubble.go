@@ -5,6 +5,7 @@ package helpers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -14,6 +15,132 @@ const (
 	hubbleSock = "unix:///var/run/cilium/hubble.sock"
 )
 
+// HubbleFilter describes the subset of `hubble observe` flags that
+// HubbleObserveFlows knows how to apply. Zero-valued fields are omitted from
+// the command line rather than passed through as empty flag values.
+type HubbleFilter struct {
+	// Verdict filters on the flow verdict, e.g. "FORWARDED" or "DROPPED".
+	Verdict string
+	// From filters on the source pod, in "namespace/pod" form.
+	From string
+	// To filters on the destination pod, in "namespace/pod" form.
+	To string
+	// Protocol filters on the L4 protocol, e.g. "tcp" or "udp".
+	Protocol string
+}
+
+// toFlags renders f as the `hubble observe` flags it corresponds to.
+func (f HubbleFilter) toFlags() []string {
+	var flags []string
+	if f.Verdict != "" {
+		flags = append(flags, "--verdict", f.Verdict)
+	}
+	if f.From != "" {
+		flags = append(flags, "--from-pod", f.From)
+	}
+	if f.To != "" {
+		flags = append(flags, "--to-pod", f.To)
+	}
+	if f.Protocol != "" {
+		flags = append(flags, "--protocol", f.Protocol)
+	}
+	return flags
+}
+
+// FlowEndpoint describes one side (source or destination) of a Flow.
+type FlowEndpoint struct {
+	Namespace string
+	PodName   string
+	IP        string
+}
+
+// Flow is a trimmed-down, typed view of the fields of a Hubble flow that
+// tests most commonly assert on. It is populated from the JSON emitted by
+// `hubble observe -o json`, ignoring everything it doesn't recognize.
+type Flow struct {
+	Verdict         string
+	Source          FlowEndpoint
+	Destination     FlowEndpoint
+	Protocol        string
+	SourcePort      int
+	DestinationPort int
+}
+
+// rawHubbleFlow mirrors the subset of Hubble's flow JSON schema that Flow is
+// populated from. See Flow for the simplified, test-facing shape.
+type rawHubbleFlow struct {
+	Flow struct {
+		Verdict string `json:"verdict"`
+		IP      struct {
+			Source      string `json:"source"`
+			Destination string `json:"destination"`
+		} `json:"IP"`
+		Source struct {
+			Namespace string `json:"namespace"`
+			PodName   string `json:"pod_name"`
+		} `json:"source"`
+		Destination struct {
+			Namespace string `json:"namespace"`
+			PodName   string `json:"pod_name"`
+		} `json:"destination"`
+		L4 struct {
+			TCP *struct {
+				SourcePort      int `json:"source_port"`
+				DestinationPort int `json:"destination_port"`
+			} `json:"TCP"`
+			UDP *struct {
+				SourcePort      int `json:"source_port"`
+				DestinationPort int `json:"destination_port"`
+			} `json:"UDP"`
+		} `json:"l4"`
+	} `json:"flow"`
+}
+
+// parseHubbleObserveFlows parses the newline-delimited JSON produced by
+// `hubble observe -o json`, one object per flow, into a slice of Flow. Blank
+// lines are skipped.
+func parseHubbleObserveFlows(output string) ([]Flow, error) {
+	var flows []Flow
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var raw rawHubbleFlow
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, fmt.Errorf("unable to parse hubble flow %q: %w", line, err)
+		}
+
+		flow := Flow{
+			Verdict: raw.Flow.Verdict,
+			Source: FlowEndpoint{
+				Namespace: raw.Flow.Source.Namespace,
+				PodName:   raw.Flow.Source.PodName,
+				IP:        raw.Flow.IP.Source,
+			},
+			Destination: FlowEndpoint{
+				Namespace: raw.Flow.Destination.Namespace,
+				PodName:   raw.Flow.Destination.PodName,
+				IP:        raw.Flow.IP.Destination,
+			},
+		}
+		switch {
+		case raw.Flow.L4.TCP != nil:
+			flow.Protocol = "TCP"
+			flow.SourcePort = raw.Flow.L4.TCP.SourcePort
+			flow.DestinationPort = raw.Flow.L4.TCP.DestinationPort
+		case raw.Flow.L4.UDP != nil:
+			flow.Protocol = "UDP"
+			flow.SourcePort = raw.Flow.L4.UDP.SourcePort
+			flow.DestinationPort = raw.Flow.L4.UDP.DestinationPort
+		}
+
+		flows = append(flows, flow)
+	}
+	return flows, nil
+}
+
 // HubbleObserve runs `hubble observe --output=jsonpb <args>`. JSON output is
 // enabled such that CmdRes.FilterLines may be used to grep for specific events
 // in the output.
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedHubbleObserveJSON is a trimmed-down recording of two lines emitted
+// by `hubble observe -o json`: a forwarded TCP flow followed by a dropped
+// UDP flow.
+const recordedHubbleObserveJSON = `{"flow":{"verdict":"FORWARDED","IP":{"source":"10.0.0.1","destination":"10.0.0.2"},"source":{"namespace":"default","pod_name":"client"},"destination":{"namespace":"default","pod_name":"server"},"l4":{"TCP":{"source_port":54321,"destination_port":80}}}}
+{"flow":{"verdict":"DROPPED","IP":{"source":"10.0.0.3","destination":"10.0.0.4"},"source":{"namespace":"kube-system","pod_name":"dns-client"},"destination":{"namespace":"kube-system","pod_name":"kube-dns"},"l4":{"UDP":{"source_port":45000,"destination_port":53}}}}
+`
+
+func TestParseHubbleObserveFlows(t *testing.T) {
+	flows, err := parseHubbleObserveFlows(recordedHubbleObserveJSON)
+	require.NoError(t, err)
+	require.Len(t, flows, 2)
+
+	assert.Equal(t, Flow{
+		Verdict:         "FORWARDED",
+		Source:          FlowEndpoint{Namespace: "default", PodName: "client", IP: "10.0.0.1"},
+		Destination:     FlowEndpoint{Namespace: "default", PodName: "server", IP: "10.0.0.2"},
+		Protocol:        "TCP",
+		SourcePort:      54321,
+		DestinationPort: 80,
+	}, flows[0])
+
+	assert.Equal(t, Flow{
+		Verdict:         "DROPPED",
+		Source:          FlowEndpoint{Namespace: "kube-system", PodName: "dns-client", IP: "10.0.0.3"},
+		Destination:     FlowEndpoint{Namespace: "kube-system", PodName: "kube-dns", IP: "10.0.0.4"},
+		Protocol:        "UDP",
+		SourcePort:      45000,
+		DestinationPort: 53,
+	}, flows[1])
+}
+
+func TestParseHubbleObserveFlowsSkipsBlankLines(t *testing.T) {
+	flows, err := parseHubbleObserveFlows("\n" + recordedHubbleObserveJSON + "\n\n")
+	require.NoError(t, err)
+	require.Len(t, flows, 2)
+}
+
+func TestParseHubbleObserveFlowsInvalidJSON(t *testing.T) {
+	_, err := parseHubbleObserveFlows("not json")
+	require.Error(t, err)
+}
+
+func TestHubbleFilterToFlags(t *testing.T) {
+	assert.Empty(t, HubbleFilter{}.toFlags())
+
+	f := HubbleFilter{
+		Verdict:  "DROPPED",
+		From:     "default/client",
+		To:       "default/server",
+		Protocol: "tcp",
+	}
+	assert.Equal(t, []string{
+		"--verdict", "DROPPED",
+		"--from-pod", "default/client",
+		"--to-pod", "default/server",
+		"--protocol", "tcp",
+	}, f.toFlags())
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IptablesRule is a single "-A <chain> ..." rule as emitted by
+// "iptables -S <chain>", with RuleSpec holding the tokens that follow the
+// chain name, e.g. ["-s", "10.0.0.0/8", "-m", "comment", "--comment",
+// "cilium: NOTRACK for pod traffic", "-j", "CT", "--notrack"].
+type IptablesRule struct {
+	Chain    string
+	RuleSpec []string
+}
+
+// HasArg reports whether RuleSpec contains flag immediately followed by
+// value, e.g. HasArg("-j", "CT") on a rule ending in "-j CT --notrack".
+func (r IptablesRule) HasArg(flag, value string) bool {
+	for i, tok := range r.RuleSpec {
+		if tok == flag && i+1 < len(r.RuleSpec) && r.RuleSpec[i+1] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IptablesCheck reports whether ruleSpec is present in table/chain, using
+// "iptables -C" so the kernel's own rule-matching semantics decide equality
+// rather than a textual comparison. A missing rule is reported as (false,
+// nil); any other failure (e.g. an unknown chain) is returned as an error.
+func (s *SSHMeta) IptablesCheck(table, chain string, ruleSpec []string) (bool, error) {
+	quoted := make([]string, 0, len(ruleSpec))
+	for _, tok := range ruleSpec {
+		quoted = append(quoted, shellQuote(tok))
+	}
+	cmd := fmt.Sprintf("iptables -w 60 -t %s -C %s %s", table, chain, strings.Join(quoted, " "))
+	res := s.ExecWithSudo(cmd)
+	if res.WasSuccessful() {
+		return true, nil
+	}
+	if res.GetExitCode() == 1 {
+		// "iptables -C" exits 1 (and only 1) when the rule is well-formed but
+		// not present; any other non-zero exit code is a real failure, e.g. an
+		// unknown chain or a malformed ruleSpec.
+		return false, nil
+	}
+	return false, fmt.Errorf("cannot check iptables rule in table %q chain %q: %s", table, chain, res.GetErr(""))
+}
+
+// IptablesList returns the rules currently installed in table/chain, parsed
+// from "iptables -S <chain>".
+func (s *SSHMeta) IptablesList(table, chain string) ([]IptablesRule, error) {
+	cmd := fmt.Sprintf("iptables -w 60 -t %s -S %s", table, chain)
+	res := s.ExecWithSudo(cmd)
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("cannot list iptables rules in table %q chain %q: %s", table, chain, res.GetErr(""))
+	}
+	return parseIptablesRules(res.GetStdOut().String())
+}
+
+// parseIptablesRules parses the output of "iptables -S", keeping only the
+// "-A <chain> ..." rule lines and skipping the "-P" (policy) and "-N" (chain
+// declaration) lines that "-S" also emits.
+func parseIptablesRules(output string) ([]IptablesRule, error) {
+	var rules []IptablesRule
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "-A ") {
+			continue
+		}
+
+		tokens, err := splitIptablesLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse iptables rule %q: %w", line, err)
+		}
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("cannot parse iptables rule %q: expected \"-A <chain> ...\"", line)
+		}
+
+		rules = append(rules, IptablesRule{
+			Chain:    tokens[1],
+			RuleSpec: tokens[2:],
+		})
+	}
+	return rules, nil
+}
+
+// shellQuote single-quotes tok so it survives word-splitting by the shell
+// that ExecWithSudo runs the command through, matching the manual
+// single-quoting convention already used for --comment values elsewhere
+// (e.g. test/k8s/datapath_configuration.go). Embedded single quotes are
+// escaped in the standard shell idiom: close the quote, emit an escaped
+// quote, reopen it.
+func shellQuote(tok string) string {
+	return "'" + strings.ReplaceAll(tok, "'", `'\''`) + "'"
+}
+
+// splitIptablesLine tokenizes a single line of "iptables -S" output, which
+// is whitespace-separated except for arguments containing spaces (such as
+// --comment values), which iptables double-quotes.
+func splitIptablesLine(line string) ([]string, error) {
+	var tokens []string
+	var b strings.Builder
+	inQuotes := false
+	hasToken := false
+
+	flush := func() {
+		if hasToken {
+			tokens = append(tokens, b.String())
+			b.Reset()
+			hasToken = false
+		}
+	}
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case r == ' ' && !inQuotes:
+			flush()
+		default:
+			b.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	flush()
+	return tokens, nil
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedNotrackRulesDump is a recorded "iptables -w 60 -t raw -S
+// CILIUM_PRE_raw" output, including the policy/chain-declaration lines that
+// "-S" also emits for this chain.
+const recordedNotrackRulesDump = `-N CILIUM_PRE_raw
+-A CILIUM_PRE_raw -s 10.0.0.0/8 -m comment --comment "cilium: NOTRACK for pod traffic" -j CT --notrack
+-A CILIUM_PRE_raw -d 10.0.0.0/8 -m comment --comment "cilium: NOTRACK for pod traffic" -j CT --notrack
+`
+
+func TestParseIptablesRules(t *testing.T) {
+	rules, err := parseIptablesRules(recordedNotrackRulesDump)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	assert.Equal(t, "CILIUM_PRE_raw", rules[0].Chain)
+	assert.Equal(t, []string{"-s", "10.0.0.0/8", "-m", "comment", "--comment", "cilium: NOTRACK for pod traffic", "-j", "CT", "--notrack"}, rules[0].RuleSpec)
+	assert.True(t, rules[0].HasArg("-s", "10.0.0.0/8"))
+	assert.True(t, rules[0].HasArg("-j", "CT"))
+	assert.False(t, rules[0].HasArg("-d", "10.0.0.0/8"))
+
+	assert.Equal(t, "CILIUM_PRE_raw", rules[1].Chain)
+	assert.True(t, rules[1].HasArg("-d", "10.0.0.0/8"))
+}
+
+func TestParseIptablesRulesEmpty(t *testing.T) {
+	rules, err := parseIptablesRules("-P INPUT ACCEPT\n-N CILIUM_PRE_raw\n")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestParseIptablesRulesUnterminatedQuote(t *testing.T) {
+	_, err := parseIptablesRules(`-A CHAIN -m comment --comment "unterminated`)
+	require.Error(t, err)
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		tok  string
+		want string
+	}{
+		{
+			name: "plain token",
+			tok:  "-j",
+			want: `'-j'`,
+		},
+		{
+			name: "token with spaces",
+			tok:  "cilium: NOTRACK for pod traffic",
+			want: `'cilium: NOTRACK for pod traffic'`,
+		},
+		{
+			name: "embedded single quote",
+			tok:  "it's",
+			want: `'it'\''s'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shellQuote(tt.tok))
+		})
+	}
+}
+
+func TestSplitIptablesLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{
+			name: "no quoted arguments",
+			line: "-A PREROUTING -j CILIUM_PRE_raw",
+			want: []string{"-A", "PREROUTING", "-j", "CILIUM_PRE_raw"},
+		},
+		{
+			name: "quoted comment with spaces",
+			line: `-A CILIUM_PRE_raw -m comment --comment "cilium: NOTRACK for pod traffic" -j CT --notrack`,
+			want: []string{"-A", "CILIUM_PRE_raw", "-m", "comment", "--comment", "cilium: NOTRACK for pod traffic", "-j", "CT", "--notrack"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitIptablesLine(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
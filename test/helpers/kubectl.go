@@ -694,6 +694,28 @@ func (kub *Kubectl) GetCiliumEndpoint(namespace string, pod string) (*cnpv2.Endp
 	return data, nil
 }
 
+// CiliumEndpointList returns the typed CiliumEndpoint objects in the
+// specified namespace. An empty namespace lists CiliumEndpoints across all
+// namespaces.
+func (kub *Kubectl) CiliumEndpointList(namespace string) ([]cnpv2.CiliumEndpoint, error) {
+	var res *CmdRes
+	if namespace == "" {
+		res = kub.GetFromAllNS("ciliumendpoints")
+	} else {
+		res = kub.Get(namespace, "ciliumendpoints")
+	}
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("unable to get CiliumEndpoints in namespace %q: %s", namespace, res.OutputPrettyPrint())
+	}
+
+	var list cnpv2.CiliumEndpointList
+	if err := res.Unmarshal(&list); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal CiliumEndpointList: %w", err)
+	}
+
+	return list.Items, nil
+}
+
 // GetCiliumHostEndpointID returns the ID of the host endpoint on a given node.
 func (kub *Kubectl) GetCiliumHostEndpointID(ciliumPod string) (int64, error) {
 	cmd := fmt.Sprintf("cilium-dbg endpoint list -o jsonpath='{[?(@.status.identity.id==%d)].id}'",
@@ -900,6 +922,37 @@ func (kub *Kubectl) GetCNP(namespace string, cnp string) *cnpv2.CiliumNetworkPol
 	return &result
 }
 
+// WaitForCNPStatus polls the CiliumNetworkPolicy cnp in namespace until
+// predicate returns true for its current state, or timeout elapses. On
+// timeout the returned error includes the last CNP status observed, or
+// notes that the CNP could never be retrieved if that never succeeded.
+func (kub *Kubectl) WaitForCNPStatus(namespace, cnp string, predicate func(cnpv2.CiliumNetworkPolicy) bool, timeout time.Duration) error {
+	var lastStatus cnpv2.CiliumNetworkPolicyStatus
+	seen := false
+
+	body := func() bool {
+		result := kub.GetCNP(namespace, cnp)
+		if result == nil {
+			return false
+		}
+		seen = true
+		lastStatus = result.Status
+		return predicate(*result)
+	}
+
+	err := WithTimeout(
+		body,
+		fmt.Sprintf("timed out waiting for CNP %s/%s to reach the desired status", namespace, cnp),
+		&TimeoutConfig{Timeout: timeout})
+	if err != nil {
+		if !seen {
+			return fmt.Errorf("%w: CNP was never successfully retrieved", err)
+		}
+		return fmt.Errorf("%w: last observed status: %+v", err, lastStatus)
+	}
+	return nil
+}
+
 func (kub *Kubectl) WaitForCRDCount(filter string, count int, timeout time.Duration) error {
 	// Set regexp flag m for multi-line matching, then add the
 	// matches for beginning and end of a line, so that we count
@@ -3141,6 +3194,42 @@ func (kub *Kubectl) CiliumPolicyRevision(pod string) (int, error) {
 	return revi, nil
 }
 
+// WaitForPolicyRevisionAll polls the policy revision of every Cilium agent
+// pod in namespace and blocks until each one has reached a revision of at
+// least minRevision, logging any pods that are still lagging behind on
+// each poll. Returns an error if timeout elapses first.
+func (kub *Kubectl) WaitForPolicyRevisionAll(namespace string, minRevision int, timeout time.Duration) error {
+	body := func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), ShortCommandTimeout)
+		defer cancel()
+		pods, err := kub.GetCiliumPodsContext(ctx, namespace)
+		if err != nil {
+			kub.Logger().WithError(err).Error("cannot retrieve cilium pods")
+			return false
+		}
+
+		ready := true
+		for _, pod := range pods {
+			revision, err := kub.CiliumPolicyRevision(pod)
+			if err != nil {
+				kub.Logger().WithError(err).Errorf("cannot retrieve policy revision for pod %q", pod)
+				ready = false
+				continue
+			}
+			if revision < minRevision {
+				kub.Logger().Infof("pod %q is at policy revision %d, waiting for %d", pod, revision, minRevision)
+				ready = false
+			}
+		}
+		return ready
+	}
+
+	return WithTimeout(
+		body,
+		fmt.Sprintf("timed out waiting for all Cilium pods in namespace %q to reach policy revision %d", namespace, minRevision),
+		&TimeoutConfig{Timeout: timeout})
+}
+
 // ResourceLifeCycleAction represents an action performed upon objects in
 // Kubernetes.
 type ResourceLifeCycleAction string
@@ -4297,6 +4386,23 @@ func (kub *Kubectl) HubbleObserve(pod string, args string) *CmdRes {
 	return kub.ExecPodCmdContext(ctx, CiliumNamespace, pod, fmt.Sprintf("hubble observe --output=jsonpb %s", args))
 }
 
+// HubbleObserveFlows runs `hubble observe -o json` on the Cilium pod
+// ciliumPod, applying filter and limiting the result to the last count
+// flows, and returns the parsed flows.
+func (kub *Kubectl) HubbleObserveFlows(ciliumPod string, filter HubbleFilter, count int) ([]Flow, error) {
+	args := append(filter.toFlags(), "-o", "json", "--last", strconv.Itoa(count))
+	cmd := fmt.Sprintf("hubble observe %s", strings.Join(args, " "))
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShortCommandTimeout)
+	defer cancel()
+	res := kub.CiliumExecContext(ctx, ciliumPod, cmd)
+	if !res.WasSuccessful() {
+		return nil, fmt.Errorf("unable to run command '%s' on %s: %s", cmd, ciliumPod, res.OutputPrettyPrint())
+	}
+
+	return parseHubbleObserveFlows(res.Stdout())
+}
+
 // HubbleObserveFollow runs `hubble observe --follow --output=jsonpb <args>` on
 // the Cilium pod 'ns/pod' in the background. The process is stopped when ctx is cancelled.
 func (kub *Kubectl) HubbleObserveFollow(ctx context.Context, pod string, args string) (*CmdRes, error) {
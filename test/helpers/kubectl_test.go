@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	cnpv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+)
+
+// fakeKubectlExecutor is a minimal Executor that answers the pod-discovery
+// and `cilium-dbg policy get` commands issued by WaitForPolicyRevisionAll,
+// so that it can be unit tested without a real cluster.
+type fakeKubectlExecutor struct {
+	pods         []string
+	podRevisions map[string]int
+}
+
+func (f *fakeKubectlExecutor) IsLocal() bool   { return true }
+func (f *fakeKubectlExecutor) CloseSSHClient() {}
+
+func (f *fakeKubectlExecutor) Exec(cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(context.Background(), cmd, options...)
+}
+
+func (f *fakeKubectlExecutor) ExecContext(ctx context.Context, cmd string, options ...ExecOptions) *CmdRes {
+	for pod, revision := range f.podRevisions {
+		if strings.Contains(cmd, fmt.Sprintf("exec -n %s %s -- cilium-dbg policy get -o json", CiliumNamespace, pod)) {
+			res := &CmdRes{cmd: cmd, stdout: &Buffer{}, stderr: &Buffer{}, success: true}
+			fmt.Fprintf(res.stdout, `{"revision": %d}`, revision)
+			return res
+		}
+	}
+	return &CmdRes{cmd: cmd, stdout: &Buffer{}, stderr: &Buffer{}, success: false, err: fmt.Errorf("unexpected command: %q", cmd)}
+}
+
+func (f *fakeKubectlExecutor) ExecContextShort(ctx context.Context, cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(ctx, cmd, options...)
+}
+
+func (f *fakeKubectlExecutor) ExecInBackground(ctx context.Context, cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(ctx, cmd, options...)
+}
+
+func (f *fakeKubectlExecutor) ExecMiddle(cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(context.Background(), cmd, options...)
+}
+
+func (f *fakeKubectlExecutor) ExecShort(cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(context.Background(), cmd, options...)
+}
+
+func (f *fakeKubectlExecutor) ExecWithSudo(cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(context.Background(), cmd, options...)
+}
+
+func (f *fakeKubectlExecutor) ExecuteContext(ctx context.Context, cmd string, stdout io.Writer, stderr io.Writer) error {
+	if strings.Contains(cmd, "get pods -l k8s-app=cilium") {
+		if stdout != nil {
+			fmt.Fprint(stdout, strings.Join(f.pods, " "))
+		}
+		return nil
+	}
+	return fmt.Errorf("unexpected command: %q", cmd)
+}
+
+func (f *fakeKubectlExecutor) String() string   { return "fakeKubectlExecutor" }
+func (f *fakeKubectlExecutor) BasePath() string { return "" }
+func (f *fakeKubectlExecutor) RenderTemplateToFile(filename string, tmplt string, perm os.FileMode) error {
+	return nil
+}
+func (f *fakeKubectlExecutor) setBasePath() {}
+
+func (f *fakeKubectlExecutor) Logger() *logrus.Entry {
+	return logrus.NewEntry(logrus.New())
+}
+
+var _ Executor = &fakeKubectlExecutor{}
+
+func TestWaitForPolicyRevisionAll(t *testing.T) {
+	tests := []struct {
+		name         string
+		podRevisions map[string]int
+		minRevision  int
+		wantErr      bool
+	}{
+		{
+			name:         "all pods reached minRevision",
+			podRevisions: map[string]int{"cilium-1": 3, "cilium-2": 3},
+			minRevision:  3,
+		},
+		{
+			name:         "all pods past minRevision",
+			podRevisions: map[string]int{"cilium-1": 5, "cilium-2": 4},
+			minRevision:  3,
+		},
+		{
+			name:         "a lagging pod times out",
+			podRevisions: map[string]int{"cilium-1": 3, "cilium-2": 2},
+			minRevision:  3,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := make([]string, 0, len(tt.podRevisions))
+			for pod := range tt.podRevisions {
+				pods = append(pods, pod)
+			}
+
+			kub := &Kubectl{
+				Executor: &fakeKubectlExecutor{pods: pods, podRevisions: tt.podRevisions},
+			}
+
+			err := kub.WaitForPolicyRevisionAll(CiliumNamespace, tt.minRevision, 5*time.Second)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// recordedCiliumEndpointListJSON is a trimmed-down recording of the JSON
+// emitted by `kubectl get ciliumendpoints -o json` for a namespace with two
+// CiliumEndpoints.
+const recordedCiliumEndpointListJSON = `{
+	"apiVersion": "cilium.io/v2",
+	"kind": "CiliumEndpointList",
+	"items": [
+		{
+			"metadata": {"name": "pod-1", "namespace": "default"},
+			"status": {"id": 1, "state": "ready"}
+		},
+		{
+			"metadata": {"name": "pod-2", "namespace": "default"},
+			"status": {"id": 2, "state": "ready"}
+		}
+	]
+}`
+
+// fakeGetExecutor is a minimal Executor that answers `kubectl get` commands
+// with a canned response, recording the last command it was asked to run.
+type fakeGetExecutor struct {
+	fakeKubectlExecutor
+	lastCmd string
+	output  string
+}
+
+func (f *fakeGetExecutor) ExecContext(ctx context.Context, cmd string, options ...ExecOptions) *CmdRes {
+	f.lastCmd = cmd
+	res := &CmdRes{cmd: cmd, stdout: &Buffer{}, stderr: &Buffer{}, success: true}
+	fmt.Fprint(res.stdout, f.output)
+	return res
+}
+
+func (f *fakeGetExecutor) ExecShort(cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(context.Background(), cmd, options...)
+}
+
+// recordedCNPStatusJSON renders a CiliumNetworkPolicy with a
+// DerivativePolicies entry named "node" whose OK field is enforcing,
+// mirroring the shape that kubectl get cnp -o json returns.
+func recordedCNPStatusJSON(enforcing bool) string {
+	return fmt.Sprintf(`{
+		"apiVersion": "cilium.io/v2",
+		"kind": "CiliumNetworkPolicy",
+		"metadata": {"name": "rule1", "namespace": "default"},
+		"status": {
+			"derivativePolicies": {
+				"node": {"ok": %t}
+			}
+		}
+	}`, enforcing)
+}
+
+// fakeEvolvingCNPExecutor answers `kubectl get cnp` with a sequence of
+// canned responses, advancing to the next one on every call until the last
+// is reached, to simulate a CNP status converging over successive polls.
+type fakeEvolvingCNPExecutor struct {
+	fakeKubectlExecutor
+	responses []string
+	calls     int
+}
+
+func (f *fakeEvolvingCNPExecutor) ExecContext(ctx context.Context, cmd string, options ...ExecOptions) *CmdRes {
+	i := f.calls
+	if i >= len(f.responses) {
+		i = len(f.responses) - 1
+	}
+	f.calls++
+	res := &CmdRes{cmd: cmd, stdout: &Buffer{}, stderr: &Buffer{}, success: true}
+	fmt.Fprint(res.stdout, f.responses[i])
+	return res
+}
+
+func (f *fakeEvolvingCNPExecutor) ExecShort(cmd string, options ...ExecOptions) *CmdRes {
+	return f.ExecContext(context.Background(), cmd, options...)
+}
+
+func TestWaitForCNPStatus(t *testing.T) {
+	allEnforcing := func(cnp cnpv2.CiliumNetworkPolicy) bool {
+		status, ok := cnp.Status.DerivativePolicies["node"]
+		return ok && status.OK
+	}
+
+	t.Run("becomes enforcing before timeout", func(t *testing.T) {
+		executor := &fakeEvolvingCNPExecutor{
+			responses: []string{
+				recordedCNPStatusJSON(false),
+				recordedCNPStatusJSON(false),
+				recordedCNPStatusJSON(true),
+			},
+		}
+		kub := &Kubectl{Executor: executor}
+
+		err := kub.WaitForCNPStatus("default", "rule1", allEnforcing, 5*time.Second)
+		require.NoError(t, err)
+	})
+
+	t.Run("never enforcing times out with last observed status", func(t *testing.T) {
+		executor := &fakeEvolvingCNPExecutor{
+			responses: []string{recordedCNPStatusJSON(false)},
+		}
+		kub := &Kubectl{Executor: executor}
+
+		err := kub.WaitForCNPStatus("default", "rule1", allEnforcing, 5*time.Second)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "last observed status")
+		require.Contains(t, err.Error(), "node")
+	})
+
+	t.Run("CNP never retrievable times out without a last status", func(t *testing.T) {
+		kub := &Kubectl{Executor: &fakeKubectlExecutor{}}
+
+		err := kub.WaitForCNPStatus("default", "rule1", allEnforcing, 5*time.Second)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "never successfully retrieved")
+	})
+}
+
+func TestCiliumEndpointList(t *testing.T) {
+	executor := &fakeGetExecutor{output: recordedCiliumEndpointListJSON}
+	kub := &Kubectl{Executor: executor}
+
+	ceps, err := kub.CiliumEndpointList("default")
+	require.NoError(t, err)
+	require.Contains(t, executor.lastCmd, "-n default get ciliumendpoints")
+
+	require.Len(t, ceps, 2)
+	require.Equal(t, "pod-1", ceps[0].Name)
+	require.EqualValues(t, 1, ceps[0].Status.ID)
+	require.Equal(t, "pod-2", ceps[1].Name)
+
+	_, err = kub.CiliumEndpointList("")
+	require.NoError(t, err)
+	require.Contains(t, executor.lastCmd, "get ciliumendpoints --all-namespaces")
+}
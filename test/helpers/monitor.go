@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validMonitorFlags is the set of TCP flags NormalizeMonitorFlags accepts for
+// the bpf.monitorFlags Helm value: the six individual flags syn/ack/fin/psh/
+// rst/urg out of pkg/option.TCPFlags. Its "none", "all", "ece" and "cwr"
+// entries are not accepted here.
+var validMonitorFlags = map[string]struct{}{
+	"syn": {},
+	"ack": {},
+	"fin": {},
+	"psh": {},
+	"rst": {},
+	"urg": {},
+}
+
+// NormalizeMonitorFlags validates a comma-separated list of monitor
+// aggregation TCP flags (e.g. "syn,ack") against the known set
+// (syn/ack/fin/psh/rst/urg) and returns it normalized to lower-case,
+// whitespace-trimmed and sorted. An unknown flag, such as a typo, returns
+// an error instead of being silently dropped, which would otherwise disable
+// filtering on that flag without any indication something is wrong.
+func NormalizeMonitorFlags(in string) (string, error) {
+	parts := strings.Split(in, ",")
+	normalized := make([]string, 0, len(parts))
+	for _, part := range parts {
+		flag := strings.ToLower(strings.TrimSpace(part))
+		if flag == "" {
+			continue
+		}
+		if _, ok := validMonitorFlags[flag]; !ok {
+			return "", fmt.Errorf("unknown monitor flag %q", flag)
+		}
+		normalized = append(normalized, flag)
+	}
+	sort.Strings(normalized)
+	return strings.Join(normalized, ","), nil
+}
+
+// MonitorEvent is a single JSON-decoded event, as emitted by
+// `cilium-dbg monitor -o json`. Callers that care about particular fields
+// should unmarshal it into the type they expect.
+type MonitorEvent = json.RawMessage
+
+// WaitForMonitorEvent starts `cilium-dbg monitor -o json` on ciliumPod and
+// blocks until match returns true for a decoded event, or until timeout
+// elapses. The monitor process is always stopped before WaitForMonitorEvent
+// returns, whether it matched, timed out, or exited on its own.
+func (kub *Kubectl) WaitForMonitorEvent(ciliumPod string, match func(MonitorEvent) bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	events := make(chan json.RawMessage, 128)
+	jsonLines := newJSONLinesWriter(ctx, events)
+
+	cmd := fmt.Sprintf("%s exec -n %s %s -- cilium-dbg monitor -o json", KubectlCmd, CiliumNamespace, ciliumPod)
+	done := make(chan error, 1)
+	go func() {
+		defer jsonLines.Close()
+		done <- kub.ExecuteContext(ctx, cmd, jsonLines, io.Discard)
+	}()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				cancel()
+				<-done
+				return fmt.Errorf("monitor on %q exited before a matching event was observed", ciliumPod)
+			}
+			if match(ev) {
+				cancel()
+				<-done
+				return nil
+			}
+		case <-ctx.Done():
+			<-done
+			return fmt.Errorf("timed out waiting for a matching monitor event on %q", ciliumPod)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMonitorFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "single flag",
+			in:   "syn",
+			want: "syn",
+		},
+		{
+			name: "valid combo",
+			in:   "ack,syn",
+			want: "ack,syn",
+		},
+		{
+			name: "combo reordered",
+			in:   "urg,fin,rst",
+			want: "fin,rst,urg",
+		},
+		{
+			name: "case and whitespace normalization",
+			in:   " SYN , Ack ",
+			want: "ack,syn",
+		},
+		{
+			name:    "unknown flag",
+			in:      "syn,xyz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeMonitorFlags(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// recordedMonitorStream is a canned `cilium-dbg monitor -o json` stream: a
+// couple of uninteresting events followed by a TCP FIN notification.
+const recordedMonitorStream = `{"type":"drop","flow":{"IP":{"srcIP":"10.0.0.1"}}}
+{"type":"trace","flow":{"IP":{"srcIP":"10.0.0.2"}}}
+{"type":"trace","flow":{"IP":{"srcIP":"10.0.0.3"},"TCP":{"flags":"FIN"}}}
+`
+
+// fakeMonitorExecutor is a minimal Executor whose ExecuteContext streams a
+// canned monitor output to stdout, line by line, stopping early if ctx is
+// cancelled before the whole stream has been written.
+type fakeMonitorExecutor struct {
+	fakeKubectlExecutor
+	output string
+}
+
+func (f *fakeMonitorExecutor) ExecuteContext(ctx context.Context, cmd string, stdout io.Writer, stderr io.Writer) error {
+	for _, line := range strings.Split(strings.TrimRight(f.output, "\n"), "\n") {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return nil
+}
+
+func TestWaitForMonitorEvent(t *testing.T) {
+	hasFIN := func(ev MonitorEvent) bool {
+		return strings.Contains(string(ev), `"flags":"FIN"`)
+	}
+
+	t.Run("predicate matches an event in the stream", func(t *testing.T) {
+		kub := &Kubectl{Executor: &fakeMonitorExecutor{output: recordedMonitorStream}}
+		err := kub.WaitForMonitorEvent("cilium-1", hasFIN, time.Second)
+		require.NoError(t, err)
+	})
+
+	t.Run("predicate never matches before the stream ends", func(t *testing.T) {
+		kub := &Kubectl{Executor: &fakeMonitorExecutor{output: recordedMonitorStream}}
+		err := kub.WaitForMonitorEvent("cilium-1", func(MonitorEvent) bool { return false }, time.Second)
+		require.Error(t, err)
+	})
+
+	t.Run("predicate never matches and the monitor blocks past the timeout", func(t *testing.T) {
+		kub := &Kubectl{Executor: &blockingMonitorExecutor{}}
+		err := kub.WaitForMonitorEvent("cilium-1", func(MonitorEvent) bool { return false }, 50*time.Millisecond)
+		require.Error(t, err)
+	})
+}
+
+// blockingMonitorExecutor is a minimal Executor whose ExecuteContext writes
+// nothing and blocks until ctx is cancelled, simulating a monitor process
+// that is still running when the caller's timeout elapses.
+type blockingMonitorExecutor struct {
+	fakeKubectlExecutor
+}
+
+func (f *blockingMonitorExecutor) ExecuteContext(ctx context.Context, cmd string, stdout io.Writer, stderr io.Writer) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
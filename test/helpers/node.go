@@ -4,7 +4,9 @@
 package helpers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -17,6 +19,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 
+	"github.com/cilium/cilium/pkg/lock"
 	ginkgoext "github.com/cilium/cilium/test/ginkgo-ext"
 )
 
@@ -315,6 +318,140 @@ func (s *SSHMeta) ExecInBackground(ctx context.Context, cmd string, options ...E
 	return res
 }
 
+// ExecInBackgroundJSONLines behaves like ExecInBackground, but treats cmd's
+// stdout as a stream of newline-delimited JSON objects (as emitted by e.g.
+// `cilium monitor -o json` or `hubble observe -o jsonpb`) rather than opaque
+// output. Each decoded object is sent to out as it arrives; out is closed
+// once cmd's stdout is closed, which happens when the command exits or ctx
+// is cancelled. Lines that fail to decode as JSON are skipped and counted
+// rather than treated as a fatal error, since a truncated line can occur at
+// the tail of the stream when the command is interrupted.
+func (s *SSHMeta) ExecInBackgroundJSONLines(ctx context.Context, cmd string, out chan<- json.RawMessage) *CmdRes {
+	if ctx == nil {
+		panic("no context provided")
+	}
+
+	jsonLines := newJSONLinesWriter(ctx, out)
+
+	var ops ExecOptions
+	fmt.Fprintln(SSHMetaLogs, cmd)
+	stdout := new(Buffer)
+	stderr := new(Buffer)
+
+	command := &SSHCommand{
+		Path:   cmd,
+		Stdin:  os.Stdin,
+		Stdout: io.MultiWriter(stdout, jsonLines),
+		Stderr: stderr,
+	}
+	var wg sync.WaitGroup
+	res := &CmdRes{
+		cmd:     cmd,
+		stdout:  stdout,
+		stderr:  stderr,
+		success: false,
+		wg:      &wg,
+	}
+
+	res.wg.Add(1)
+	go func(res *CmdRes) {
+		defer res.wg.Done()
+		defer jsonLines.Close()
+		start := time.Now()
+		err := s.sshClient.RunCommandInBackground(ctx, command)
+		if err != nil {
+			exiterr := &ssh.ExitError{}
+			if errors.As(err, &exiterr) {
+				res.exitcode = exiterr.Waitmsg.ExitStatus()
+				// Set success as true if SIGINT signal was sent to command
+				if res.exitcode == 130 {
+					res.success = true
+				}
+			}
+			if !res.success {
+				res.err = err
+			}
+		} else {
+			res.success = true
+			res.exitcode = 0
+		}
+		res.duration = time.Since(start)
+		res.SendToLog(ops.SkipLog)
+		if skipped := jsonLines.Skipped(); skipped > 0 && s.logger != nil {
+			s.logger.Warningf("ExecInBackgroundJSONLines: skipped %d malformed JSON line(s) from %q", skipped, cmd)
+		}
+	}(res)
+
+	return res
+}
+
+// jsonLinesWriter is an io.WriteCloser that splits the bytes written to it on
+// newlines, decodes each complete line as JSON, and forwards the decoded
+// message to out. It is safe to write to concurrently.
+type jsonLinesWriter struct {
+	ctx     context.Context
+	out     chan<- json.RawMessage
+	mutex   lock.Mutex
+	partial []byte
+	skipped int
+}
+
+func newJSONLinesWriter(ctx context.Context, out chan<- json.RawMessage) *jsonLinesWriter {
+	return &jsonLinesWriter{ctx: ctx, out: out}
+}
+
+// Write implements io.Writer.
+func (w *jsonLinesWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		line := bytes.TrimSpace(w.partial[:idx])
+		w.partial = w.partial[idx+1:]
+		w.decodeAndSend(line)
+	}
+	return len(p), nil
+}
+
+// Close flushes any remaining partial line and then closes the out channel,
+// so that a reader ranging over out stops once the stream has ended.
+func (w *jsonLinesWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.decodeAndSend(bytes.TrimSpace(w.partial))
+	w.partial = nil
+	close(w.out)
+	return nil
+}
+
+// Skipped returns the number of lines that failed to decode as JSON.
+func (w *jsonLinesWriter) Skipped() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.skipped
+}
+
+func (w *jsonLinesWriter) decodeAndSend(line []byte) {
+	if len(line) == 0 {
+		return
+	}
+	var raw json.RawMessage
+	if err := json.Unmarshal(line, &raw); err != nil {
+		w.skipped++
+		return
+	}
+	select {
+	case w.out <- raw:
+	case <-w.ctx.Done():
+	}
+}
+
 // RenderTemplateToFile renders a text/template string into a target filename
 // with specific persmisions. Returns an error if the template cannot be
 // validated or the file cannot be created.
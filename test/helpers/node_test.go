@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLinesWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan json.RawMessage, 16)
+	w := newJSONLinesWriter(ctx, out)
+
+	// Feed the stream in arbitrary chunks to exercise the partial-line
+	// buffering, including a malformed line and a trailing line with no
+	// terminating newline that is only flushed on Close.
+	chunks := []string{
+		`{"type":"drop","re`,
+		`ason":"POLICY_DENIED"}` + "\n",
+		"not json\n",
+		`{"type":"trace"}` + "\n",
+		`{"type":"trailing"}`,
+	}
+	for _, c := range chunks {
+		n, err := w.Write([]byte(c))
+		require.NoError(t, err)
+		require.Len(t, c, n)
+	}
+	require.NoError(t, w.Close())
+
+	var got []string
+	for msg := range out {
+		got = append(got, string(msg))
+	}
+
+	require.Equal(t, []string{
+		`{"type":"drop","reason":"POLICY_DENIED"}`,
+		`{"type":"trace"}`,
+		`{"type":"trailing"}`,
+	}, got)
+	require.Equal(t, 1, w.Skipped())
+}
+
+func TestJSONLinesWriterCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// An unbuffered channel with no reader would block forever on send;
+	// a cancelled context must let Write/Close return promptly instead.
+	out := make(chan json.RawMessage)
+	w := newJSONLinesWriter(ctx, out)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte(`{"type":"trace"}` + "\n"))
+		_ = w.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write/Close did not return after context cancellation")
+	}
+}
@@ -59,10 +59,13 @@ var _ = Describe("K8sDatapathConfig", func() {
 
 	Context("MonitorAggregation", func() {
 		It("Checks that monitor aggregation restricts notifications", func() {
+			monitorFlags, err := helpers.NormalizeMonitorFlags("syn")
+			Expect(err).Should(BeNil(), "Failed to normalize monitor flags")
+
 			deploymentManager.DeployCilium(map[string]string{
 				"bpf.monitorAggregation": "medium",
 				"bpf.monitorInterval":    "60s",
-				"bpf.monitorFlags":       "syn",
+				"bpf.monitorFlags":       monitorFlags,
 			}, DeployCiliumOptionsAndDNS)
 
 			monitorRes, monitorCancel, targetIP := monitorConnectivityAcrossNodes(kubectl)
@@ -112,10 +115,13 @@ var _ = Describe("K8sDatapathConfig", func() {
 		})
 
 		It("Checks that monitor aggregation flags send notifications", func() {
+			monitorFlags, err := helpers.NormalizeMonitorFlags("psh")
+			Expect(err).Should(BeNil(), "Failed to normalize monitor flags")
+
 			deploymentManager.DeployCilium(map[string]string{
 				"bpf.monitorAggregation": "medium",
 				"bpf.monitorInterval":    "60s",
-				"bpf.monitorFlags":       "psh",
+				"bpf.monitorFlags":       monitorFlags,
 			}, DeployCiliumOptionsAndDNS)
 			monitorRes, monitorCancel, _ := monitorConnectivityAcrossNodes(kubectl)
 			defer monitorCancel()
@@ -736,12 +742,12 @@ var _ = Describe("K8sDatapathConfig", func() {
 			res = kubectl.ExecPodCmd(helpers.CiliumNamespace, ciliumPod, cmd)
 			res.ExpectSuccess("Missing '-j CT --notrack' iptables rule")
 
-			cmd = fmt.Sprintf("conntrack -L -s %s -d %s | wc -l", helpers.IPv4NativeRoutingCIDR, helpers.IPv4NativeRoutingCIDR)
-			resStr, err := kubectl.ExecInHostNetNSByLabel(context.TODO(), helpers.K8s1, cmd)
-			if err != nil {
-				ExpectWithOffset(1, err).Should(BeNil(), "Cannot list conntrack entries")
-			}
-			Expect(strings.TrimSpace(resStr)).To(Equal("0"), "Unexpected conntrack entries")
+			count, err := kubectl.ConntrackCountInHostNetNSByLabel(context.TODO(), helpers.K8s1, helpers.ConntrackFilter{
+				SourceCIDR: helpers.IPv4NativeRoutingCIDR,
+				DestCIDR:   helpers.IPv4NativeRoutingCIDR,
+			})
+			ExpectWithOffset(1, err).Should(BeNil(), "Cannot list conntrack entries")
+			Expect(count).To(Equal(0), "Unexpected conntrack entries")
 		})
 	})
 })